@@ -19,7 +19,9 @@ import (
 	"drones/internal/adapters/logger"
 	"drones/internal/adapters/postgres"
 	"drones/internal/adapters/redis"
+	"drones/internal/adapters/storage"
 	"drones/internal/core/services"
+	"drones/internal/ports"
 )
 
 func main() {
@@ -69,11 +71,22 @@ func main() {
 		}
 	}()
 
+	// Blob storage for delivery photos
+	blobStorage, err := storage.NewLocalBlobStorage(cfg.Storage, appLogger)
+	if err != nil {
+		log.Fatalf("Failed to initialize blob storage: %v", err)
+	}
+
 	// Initialize repositories
 	usersRepo := postgres.NewUserRepository(db, appLogger)
 	// loginRepo := postgres.NewLoginsRepository(db, appLogger)
 	dronesRepo := postgres.NewDronesRepository(db, appLogger)
 	ordersRepo := postgres.NewOrdersRepository(db, appLogger)
+	pricingRepo := postgres.NewPricingRepository(db, appLogger)
+	droneCommandsRepo := postgres.NewDroneCommandsRepository(db, appLogger)
+	droneTelemetryRepo := postgres.NewDroneTelemetryRepository(db, appLogger)
+	geofencesRepo := postgres.NewGeofencesRepository(db, appLogger)
+	serviceAreasRepo := postgres.NewServiceAreasRepository(db, appLogger)
 	// activityLogsRepo := postgres.NewActivityLogsRepository(db, appLogger)
 	// auditLogsRepo := postgres.NewAuditLogsRepository(db, appLogger)
 
@@ -84,19 +97,51 @@ func main() {
 
 	// Initialize services
 	usersService := services.NewUserRepository(usersRepo, natsEventPublisher, cacheService, appLogger)
-	dronesService := services.NewDronesService(dronesRepo, cacheService, natsEventPublisher, appLogger)
-
-	ordersService := services.NewOrdersService(ordersRepo, dronesService, cacheService, natsEventPublisher, appLogger)
+	etaService := services.NewEtaService(ordersRepo, dronesRepo, cacheService, natsEventPublisher, cfg.Eta, appLogger)
+	geofenceService := services.NewGeofenceService(geofencesRepo, cacheService, natsEventPublisher, cfg.Geofence, appLogger)
+	serviceAreaService := services.NewServiceAreaService(serviceAreasRepo, cacheService, appLogger)
+	dronesService := services.NewDronesService(dronesRepo, ordersRepo, droneCommandsRepo, droneTelemetryRepo, geofenceService, serviceAreaService, etaService, cacheService, natsEventPublisher, cfg.Heartbeat, cfg.Delivery, appLogger)
+
+	pricingService := services.NewPricingService(pricingRepo, cacheService, cfg.Pricing, appLogger)
+	idempotencyService := services.NewIdempotencyService(cacheService, cfg.Idempotency, appLogger)
+	failurePolicy := services.NewDeliveryFailurePolicy(cfg.Delivery)
+	ordersService := services.NewOrdersService(ordersRepo, droneTelemetryRepo, dronesService, etaService, pricingService, geofenceService, serviceAreaService, cacheService, blobStorage, natsEventPublisher, failurePolicy, cfg.Schedule, cfg.Delivery, appLogger)
 	tokenService := services.NewJWTService(&cfg.Jwt)
 	authService := services.NewAuthService(usersService, tokenService, cfg.Jwt, appLogger)
 	// activityLogsService := services.NewActivityLogsService(activityLogsRepo, cacheService, natsEventPublisher, appLogger)
 	// auditLogsService := services.NewAuditLogsService(auditLogsRepo, cacheService, natsEventPublisher, appLogger)
 
-	natsEventHandlers := natsadapter.NewEventHandlers(dronesService, appLogger)
+	// Automatic dispatch of pending orders
+	var dispatchService ports.DispatchService
+	var dispatchWorker ports.Worker
+	if cfg.Dispatch.Enabled {
+		dispatchPolicy := services.NewDispatchPolicy(cfg.Dispatch.Policy)
+		dispatchService = services.NewDispatchService(ordersRepo, dronesService, dispatchPolicy, etaService, cacheService, natsEventPublisher, cfg.Dispatch, appLogger)
+		dispatchWorker = services.NewPeriodicWorker("dispatch_sweep", cfg.Dispatch.SweepInterval, dispatchService.Sweep, appLogger)
+		appLogger.Info("Automatic dispatch enabled", "policy", string(dispatchPolicy.Name()))
+	}
+
+	// Release of scheduled orders
+	scheduleService := services.NewScheduleService(ordersRepo, cacheService, natsEventPublisher, cfg.Schedule, appLogger)
+	scheduleWorker := services.NewPeriodicWorker("schedule_release", cfg.Schedule.ReleaseInterval, scheduleService.ReleaseDueOrders, appLogger)
+
+	// Expiry of reservations whose pickup was never confirmed
+	reservationService := services.NewReservationService(ordersRepo, cacheService, natsEventPublisher, cfg.Reservation, appLogger)
+	reservationWorker := services.NewPeriodicWorker("reservation_expiry", cfg.Reservation.ExpiryInterval, reservationService.ExpireStaleReservations, appLogger)
+
+	// Grounding of flying drones that stopped sending heartbeats
+	heartbeatWatchdogService := services.NewHeartbeatWatchdogService(dronesRepo, cacheService, natsEventPublisher, cfg.Heartbeat, appLogger)
+	heartbeatWatchdogWorker := services.NewPeriodicWorker("heartbeat_watchdog", cfg.Heartbeat.WatchdogInterval, heartbeatWatchdogService.DetectLostDrones, appLogger)
+
+	// Pruning of drone telemetry past its retention period
+	telemetryRetentionService := services.NewTelemetryRetentionService(droneTelemetryRepo, cfg.Telemetry, appLogger)
+	telemetryRetentionWorker := services.NewPeriodicWorker("telemetry_retention", cfg.Telemetry.PruneInterval, telemetryRetentionService.PruneTelemetry, appLogger)
+
+	natsEventHandlers := natsadapter.NewEventHandlers(dronesService, dispatchService, appLogger)
 	natsEventHandlers.RegisterHandlers(natsEventConsumer)
 
 	// Initialize HTTP handler
-	httpHandlerInstance := httpHandler.NewHTTPHandler(authService, ordersService, dronesService, natsEventPublisher, appLogger, cfg.Server.ApiPrefix)
+	httpHandlerInstance := httpHandler.NewHTTPHandler(authService, ordersService, dronesService, pricingService, geofenceService, serviceAreaService, idempotencyService, natsEventPublisher, appLogger, cfg.Server.ApiPrefix)
 
 	// Setup routes
 	r := mux.NewRouter()
@@ -126,6 +171,25 @@ func main() {
 		}
 	}()
 
+	// Start background workers
+	if dispatchWorker != nil {
+		if err := dispatchWorker.Start(ctx); err != nil {
+			appLogger.Error("Failed to start dispatch worker", "error", err)
+		}
+	}
+	if err := scheduleWorker.Start(ctx); err != nil {
+		appLogger.Error("Failed to start schedule worker", "error", err)
+	}
+	if err := reservationWorker.Start(ctx); err != nil {
+		appLogger.Error("Failed to start reservation worker", "error", err)
+	}
+	if err := heartbeatWatchdogWorker.Start(ctx); err != nil {
+		appLogger.Error("Failed to start heartbeat watchdog worker", "error", err)
+	}
+	if err := telemetryRetentionWorker.Start(ctx); err != nil {
+		appLogger.Error("Failed to start telemetry retention worker", "error", err)
+	}
+
 	// Start server in a goroutine
 	go func() {
 		appLogger.Info("Server starting", "address", addr)
@@ -145,6 +209,25 @@ func main() {
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// Stop background workers
+	if dispatchWorker != nil {
+		if err := dispatchWorker.Stop(); err != nil {
+			appLogger.Error("Error stopping dispatch worker", "error", err)
+		}
+	}
+	if err := scheduleWorker.Stop(); err != nil {
+		appLogger.Error("Error stopping schedule worker", "error", err)
+	}
+	if err := reservationWorker.Stop(); err != nil {
+		appLogger.Error("Error stopping reservation worker", "error", err)
+	}
+	if err := heartbeatWatchdogWorker.Stop(); err != nil {
+		appLogger.Error("Error stopping heartbeat watchdog worker", "error", err)
+	}
+	if err := telemetryRetentionWorker.Stop(); err != nil {
+		appLogger.Error("Error stopping telemetry retention worker", "error", err)
+	}
+
 	// Stop event consumers and publishers
 	if err := natsEventConsumer.Stop(); err != nil {
 		appLogger.Error("Error stopping NATS event consumer", "error", err)
@@ -4,15 +4,132 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 )
 
 // Config holds the application configuration
 type Config struct {
-	Server   ServerConfig   `json:"server"`
-	Database DatabaseConfig `json:"database"`
-	Redis    RedisConfig    `json:"redis"`
-	NATS     NATSConfig     `json:"nats"`
-	Jwt      JwtConfig      `json:"auth"`
+	Server      ServerConfig      `json:"server"`
+	Database    DatabaseConfig    `json:"database"`
+	Redis       RedisConfig       `json:"redis"`
+	NATS        NATSConfig        `json:"nats"`
+	Jwt         JwtConfig         `json:"auth"`
+	Dispatch    DispatchConfig    `json:"dispatch"`
+	Eta         EtaConfig         `json:"eta"`
+	Schedule    ScheduleConfig    `json:"schedule"`
+	Reservation ReservationConfig `json:"reservation"`
+	Delivery    DeliveryConfig    `json:"delivery"`
+	Storage     StorageConfig     `json:"storage"`
+	Pricing     PricingConfig     `json:"pricing"`
+	Idempotency IdempotencyConfig `json:"idempotency"`
+	Heartbeat   HeartbeatConfig   `json:"heartbeat"`
+	Telemetry   TelemetryConfig   `json:"telemetry"`
+	Geofence    GeofenceConfig    `json:"geofence"`
+}
+
+// DispatchConfig holds automatic order dispatch configuration
+type DispatchConfig struct {
+	Enabled           bool          `json:"enabled"`
+	Policy            string        `json:"policy"`
+	SearchRadiusKm    float64       `json:"search_radius_km"`
+	MinBatteryPercent float64       `json:"min_battery_percent"`
+	SweepInterval     time.Duration `json:"sweep_interval"`
+	SweepBatchSize    int           `json:"sweep_batch_size"`
+}
+
+// EtaConfig holds estimated arrival time configuration
+type EtaConfig struct {
+	// ETA changes larger than this are published as order updates
+	ShiftThreshold time.Duration `json:"shift_threshold"`
+	// Share of the max speed assumed when no recent speed was observed
+	CruiseSpeedFactor float64 `json:"cruise_speed_factor"`
+	// Observed speeds below this (hovering, loading) are ignored
+	MinObservedSpeedKmh float64 `json:"min_observed_speed_kmh"`
+	// How long an observed speed is considered recent
+	SpeedWindow time.Duration `json:"speed_window"`
+}
+
+// ScheduleConfig holds scheduled order release configuration
+type ScheduleConfig struct {
+	// Scheduled orders become reservable this long before scheduled_at
+	LeadTime         time.Duration `json:"lead_time"`
+	ReleaseInterval  time.Duration `json:"release_interval"`
+	ReleaseBatchSize int           `json:"release_batch_size"`
+}
+
+// HeartbeatConfig holds the checks run on every drone heartbeat
+type HeartbeatConfig struct {
+	// Battery levels at or below these raise a warning in the heartbeat response
+	LowBatteryPercent      int `json:"low_battery_percent"`
+	CriticalBatteryPercent int `json:"critical_battery_percent"`
+	// Flying drones silent for longer than this are marked lost and their orders handed off
+	Timeout           time.Duration `json:"timeout"`
+	WatchdogInterval  time.Duration `json:"watchdog_interval"`
+	WatchdogBatchSize int           `json:"watchdog_batch_size"`
+}
+
+// TelemetryConfig holds drone telemetry retention configuration
+type TelemetryConfig struct {
+	// Heartbeats older than this are deleted from the telemetry history
+	Retention      time.Duration `json:"retention"`
+	PruneInterval  time.Duration `json:"prune_interval"`
+	PruneBatchSize int           `json:"prune_batch_size"`
+}
+
+// GeofenceConfig holds no-fly zone configuration
+type GeofenceConfig struct {
+	// Time zone of the daily windows of the zones
+	Timezone string `json:"timezone"`
+}
+
+// ReservationConfig holds reservation expiry configuration
+type ReservationConfig struct {
+	// Reserved orders not picked up within this time go back to pending
+	TTL             time.Duration `json:"ttl"`
+	ExpiryInterval  time.Duration `json:"expiry_interval"`
+	ExpiryBatchSize int           `json:"expiry_batch_size"`
+}
+
+// DeliveryConfig holds proof of delivery configuration
+type DeliveryConfig struct {
+	// Digits of the one-time code sent to the receiver
+	CodeLength int `json:"code_length"`
+	// Wrong codes accepted before the order needs support
+	MaxCodeAttempts int `json:"max_code_attempts"`
+	// Max distance between the drone fix and the destination
+	MaxDistanceMeters float64 `json:"max_distance_meters"`
+	MaxPhotoBytes     int64   `json:"max_photo_bytes"`
+	// Failed attempts before an order is no longer re-queued
+	MaxAttempts int `json:"max_attempts"`
+	// What happens once the attempts are used up: return_to_sender or hold_at_depot
+	ExhaustedAction string `json:"exhausted_action"`
+	// Where held packages are dropped off, holding is disabled without a position
+	DepotAddress string  `json:"depot_address"`
+	DepotLat     float64 `json:"depot_lat"`
+	DepotLon     float64 `json:"depot_lon"`
+}
+
+// PricingConfig holds delivery pricing configuration
+type PricingConfig struct {
+	// Secret used to sign quotes
+	QuoteSecret string        `json:"-"`
+	QuoteTTL    time.Duration `json:"quote_ttl"`
+	Currency    string        `json:"currency"`
+	// Time zone of the time-of-day surcharge windows
+	Timezone string `json:"timezone"`
+}
+
+// IdempotencyConfig holds Idempotency-Key configuration
+type IdempotencyConfig struct {
+	// How long responses are kept for replay
+	Window time.Duration `json:"window"`
+	// How long a key stays locked while its first request is processed
+	LockTTL time.Duration `json:"lock_ttl"`
+}
+
+// StorageConfig holds blob storage configuration
+type StorageConfig struct {
+	LocalPath string `json:"local_path"`
 }
 
 // JwtConfig holds JWT configuration
@@ -56,10 +173,11 @@ type NATSConfig struct {
 
 // NATSSubjects defines all NATS subjects
 type NATSSubjects struct {
-	OrdersEvents      string `json:"orders_events"`
-	DronesEvents      string `json:"drones_events"`
-	UsersEvents       string `json:"users_events"`
-	LogActivityEvents string `json:"log_activity_events"`
+	OrdersEvents       string `json:"orders_events"`
+	DronesEvents       string `json:"drones_events"`
+	UsersEvents        string `json:"users_events"`
+	LogActivityEvents  string `json:"log_activity_events"`
+	NotificationEvents string `json:"notification_events"`
 }
 
 // Load loads configuration from environment variables
@@ -89,16 +207,80 @@ func Load() (*Config, error) {
 			Servers:    []string{getEnv("NATS_SERVERS", "nats://localhost:4222")},
 			QueueGroup: getEnv("NATS_QUEUE_GROUP", "drones.service"),
 			Subjects: NATSSubjects{
-				OrdersEvents:      getEnv("NATS_SUBJECT_ORDERS_EVENTS", "orders.events"),
-				DronesEvents:      getEnv("NATS_SUBJECT_DRONES_EVENTS", "drones.events"),
-				UsersEvents:       getEnv("NATS_SUBJECT_USERS_EVENTS", "users.events"),
-				LogActivityEvents: getEnv("NATS_SUBJECT_LOG_ACTIVITY_EVENTS", "log_activity.events"),
+				OrdersEvents:       getEnv("NATS_SUBJECT_ORDERS_EVENTS", "orders.events"),
+				DronesEvents:       getEnv("NATS_SUBJECT_DRONES_EVENTS", "drones.events"),
+				UsersEvents:        getEnv("NATS_SUBJECT_USERS_EVENTS", "users.events"),
+				LogActivityEvents:  getEnv("NATS_SUBJECT_LOG_ACTIVITY_EVENTS", "log_activity.events"),
+				NotificationEvents: getEnv("NATS_SUBJECT_NOTIFICATION_EVENTS", "notification.events"),
 			},
 		},
 		Jwt: JwtConfig{
 			Secret:    getEnv("AUTH_JWT_SECRET", "secret"),
 			ExpiresIn: getEnv("AUTH_JWT_EXPIRES_IN", "24h"),
 		},
+		Dispatch: DispatchConfig{
+			Enabled:           getEnvAsBool("DISPATCH_ENABLED", true),
+			Policy:            getEnv("DISPATCH_POLICY", "nearest_first"),
+			SearchRadiusKm:    getEnvAsFloat("DISPATCH_SEARCH_RADIUS_KM", 10),
+			MinBatteryPercent: getEnvAsFloat("DISPATCH_MIN_BATTERY_PERCENT", 30),
+			SweepInterval:     getEnvAsDuration("DISPATCH_SWEEP_INTERVAL", 30*time.Second),
+			SweepBatchSize:    getEnvAsInt("DISPATCH_SWEEP_BATCH_SIZE", 50),
+		},
+		Eta: EtaConfig{
+			ShiftThreshold:      getEnvAsDuration("ETA_SHIFT_THRESHOLD", 2*time.Minute),
+			CruiseSpeedFactor:   getEnvAsFloat("ETA_CRUISE_SPEED_FACTOR", 0.8),
+			MinObservedSpeedKmh: getEnvAsFloat("ETA_MIN_OBSERVED_SPEED_KMH", 5),
+			SpeedWindow:         getEnvAsDuration("ETA_SPEED_WINDOW", 2*time.Minute),
+		},
+		Schedule: ScheduleConfig{
+			LeadTime:         getEnvAsDuration("SCHEDULE_LEAD_TIME", 30*time.Minute),
+			ReleaseInterval:  getEnvAsDuration("SCHEDULE_RELEASE_INTERVAL", time.Minute),
+			ReleaseBatchSize: getEnvAsInt("SCHEDULE_RELEASE_BATCH_SIZE", 50),
+		},
+		Reservation: ReservationConfig{
+			TTL:             getEnvAsDuration("RESERVATION_TTL", 15*time.Minute),
+			ExpiryInterval:  getEnvAsDuration("RESERVATION_EXPIRY_INTERVAL", time.Minute),
+			ExpiryBatchSize: getEnvAsInt("RESERVATION_EXPIRY_BATCH_SIZE", 50),
+		},
+		Delivery: DeliveryConfig{
+			CodeLength:        getEnvAsInt("DELIVERY_CODE_LENGTH", 6),
+			MaxCodeAttempts:   getEnvAsInt("DELIVERY_MAX_CODE_ATTEMPTS", 5),
+			MaxDistanceMeters: getEnvAsFloat("DELIVERY_MAX_DISTANCE_METERS", 50),
+			MaxPhotoBytes:     int64(getEnvAsInt("DELIVERY_MAX_PHOTO_BYTES", 5<<20)),
+			MaxAttempts:       getEnvAsInt("DELIVERY_MAX_ATTEMPTS", 3),
+			ExhaustedAction:   getEnv("DELIVERY_EXHAUSTED_ACTION", "return_to_sender"),
+			DepotAddress:      getEnv("DELIVERY_DEPOT_ADDRESS", ""),
+			DepotLat:          getEnvAsFloat("DELIVERY_DEPOT_LAT", 0),
+			DepotLon:          getEnvAsFloat("DELIVERY_DEPOT_LON", 0),
+		},
+		Pricing: PricingConfig{
+			QuoteSecret: getEnv("PRICING_QUOTE_SECRET", "secret"),
+			QuoteTTL:    getEnvAsDuration("PRICING_QUOTE_TTL", 15*time.Minute),
+			Currency:    getEnv("PRICING_CURRENCY", "SAR"),
+			Timezone:    getEnv("PRICING_TIMEZONE", "Asia/Riyadh"),
+		},
+		Storage: StorageConfig{
+			LocalPath: getEnv("STORAGE_LOCAL_PATH", "./data/blobs"),
+		},
+		Idempotency: IdempotencyConfig{
+			Window:  getEnvAsDuration("IDEMPOTENCY_WINDOW", 24*time.Hour),
+			LockTTL: getEnvAsDuration("IDEMPOTENCY_LOCK_TTL", time.Minute),
+		},
+		Heartbeat: HeartbeatConfig{
+			LowBatteryPercent:      getEnvAsInt("HEARTBEAT_LOW_BATTERY_PERCENT", 25),
+			CriticalBatteryPercent: getEnvAsInt("HEARTBEAT_CRITICAL_BATTERY_PERCENT", 10),
+			Timeout:                getEnvAsDuration("HEARTBEAT_TIMEOUT", 2*time.Minute),
+			WatchdogInterval:       getEnvAsDuration("HEARTBEAT_WATCHDOG_INTERVAL", 30*time.Second),
+			WatchdogBatchSize:      getEnvAsInt("HEARTBEAT_WATCHDOG_BATCH_SIZE", 50),
+		},
+		Telemetry: TelemetryConfig{
+			Retention:      getEnvAsDuration("TELEMETRY_RETENTION", 90*24*time.Hour),
+			PruneInterval:  getEnvAsDuration("TELEMETRY_PRUNE_INTERVAL", time.Hour),
+			PruneBatchSize: getEnvAsInt("TELEMETRY_PRUNE_BATCH_SIZE", 10000),
+		},
+		Geofence: GeofenceConfig{
+			Timezone: getEnv("GEOFENCE_TIMEZONE", "Asia/Riyadh"),
+		},
 	}
 
 	return config, nil
@@ -134,3 +316,30 @@ func getEnvAsInt(key string, fallback int) int {
 	}
 	return fallback
 }
+
+func getEnvAsFloat(key string, fallback float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return fallback
+}
+
+func getEnvAsBool(key string, fallback bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return fallback
+}
+
+func getEnvAsDuration(key string, fallback time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if durationValue, err := time.ParseDuration(value); err == nil {
+			return durationValue
+		}
+	}
+	return fallback
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
@@ -36,6 +37,13 @@ func (h *DronesHandler) RegisterRoutes(r *mux.Router) {
 	r.Handle("/{id}", AdminGuard(http.HandlerFunc(h.HandleUpdateDrone))).Methods("PUT")
 	r.Handle("/{id}/status", AdminGuard(http.HandlerFunc(h.HandleStatusUpdated))).Methods("POST")
 
+	// Commands delivered with the heartbeat response
+	r.Handle("/{id}/commands", AdminGuard(http.HandlerFunc(h.HandleQueueCommand))).Methods("POST")
+	r.Handle("/{id}/commands", AdminGuard(http.HandlerFunc(h.HandleListCommands))).Methods("GET")
+
+	// Recorded heartbeats
+	r.Handle("/{id}/telemetry", AdminGuard(http.HandlerFunc(h.HandleGetTelemetry))).Methods("GET")
+
 	// Drone submit Location
 	r.Handle("/{id}/heartbeat", DroneGuard(http.HandlerFunc(h.HandleHeartbeat))).Methods("POST")
 }
@@ -61,6 +69,7 @@ func (h *DronesHandler) HandleGetDrone(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	SetETag(w, drone.Version)
 	ResponseWithJSON(w, http.StatusOK, drone.ToDTO())
 }
 
@@ -99,6 +108,13 @@ func (h *DronesHandler) HandleUpdateDrone(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// Only update the version the admin last read
+	expectedVersion, err := GetIfMatchVersion(r)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
 	drone, err := h.service.UpdateDrone(r.Context(), id, &domain.UpdateDroneRequest{
 		Model:               request.Model,
 		SerialNumber:        request.SerialNumber,
@@ -117,13 +133,20 @@ func (h *DronesHandler) HandleUpdateDrone(w http.ResponseWriter, r *http.Request
 		LastMaintenanceAt:   request.LastMaintenanceAt,
 		NextMaintenanceAt:   request.NextMaintenanceAt,
 		Status:              request.Status,
+		Capabilities:        request.Capabilities,
 		UpdatedByID:         &user.ID,
+		ExpectedVersion:     expectedVersion,
 	})
 	if err != nil {
+		if err == domain.ErrDroneVersionConflict {
+			ResponseWithCustomError(w, http.StatusPreconditionFailed, *domain.ErrDroneVersionConflict)
+			return
+		}
 		ResponseWithError(w, err)
 		return
 	}
 
+	SetETag(w, drone.Version)
 	ResponseWithJSON(w, http.StatusOK, drone.ToDTO())
 }
 
@@ -241,11 +264,150 @@ func (h *DronesHandler) HandleHeartbeat(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	updatedDrone, err := h.service.ProcessHeartbeat(r.Context(), *user.DroneId, user.ID, request)
+	heartbeat, err := h.service.ProcessHeartbeat(r.Context(), *user.DroneId, user.ID, request)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	ResponseWithJSON(w, http.StatusOK, heartbeat.ToResponse())
+}
+
+// HandleQueueCommand queues a command for a drone, sent with its heartbeat responses until acknowledged
+func (h *DronesHandler) HandleQueueCommand(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		ResponseWithResouseNotFound(w, "Drones ID")
+		return
+	}
+
+	if !utils.ValidateUUID(id) {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid drone ID format", nil))
+		return
+	}
+	user, ok := UserFromContext(r.Context())
+	if !ok || user == nil {
+		ResponseWithCustomError(w, http.StatusUnauthorized, domain.DomainError{
+			Code:    domain.UserNotFoundError,
+			Message: "User not found in context",
+		})
+		return
+	}
+
+	var request domain.QueueDroneCommandRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid request body", err))
+		return
+	}
+
+	if err := h.validator.Struct(request); err != nil {
+		ResponseWithValidationError(w, http.StatusBadRequest, domain.GetValidationErrors(err.(validator.ValidationErrors)))
+		return
+	}
+
+	command, err := h.service.QueueCommand(r.Context(), id, user.ID, &request)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	ResponseWithJSON(w, http.StatusCreated, command)
+}
+
+// HandleListCommands lists the commands queued for a drone, optionally by status
+func (h *DronesHandler) HandleListCommands(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		ResponseWithResouseNotFound(w, "Drones ID")
+		return
+	}
+
+	if !utils.ValidateUUID(id) {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid drone ID format", nil))
+		return
+	}
+
+	limit, _, err := GetPaginationParams(r)
+	if err != nil {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid limit", err))
+		return
+	}
+	filter := domain.DroneCommandFilter{Limit: limit}
+	if status := r.URL.Query().Get("status"); status != "" {
+		commandStatus := domain.DroneCommandStatus(status)
+		if commandStatus != domain.DroneCommandStatusPending && commandStatus != domain.DroneCommandStatusAcknowledged {
+			ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid status, expected pending or acknowledged", nil))
+			return
+		}
+		filter.Status = &commandStatus
+	}
+
+	commands, err := h.service.ListCommands(r.Context(), id, filter)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+	if commands == nil {
+		commands = []*domain.DroneCommand{}
+	}
+
+	ResponseWithJSON(w, http.StatusOK, commands)
+}
+
+// HandleGetTelemetry returns the recorded heartbeats of a drone between from and to, one point per
+// interval seconds. The range defaults to the last DefaultTelemetryWindow.
+func (h *DronesHandler) HandleGetTelemetry(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		ResponseWithResouseNotFound(w, "Drones ID")
+		return
+	}
+
+	if !utils.ValidateUUID(id) {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid drone ID format", nil))
+		return
+	}
+
+	now := time.Now()
+	to, err := parseTimeParam(r, "to", now)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+	from, err := parseTimeParam(r, "from", to.Add(-domain.DefaultTelemetryWindow))
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+	if !from.Before(to) {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "from must be before to", nil))
+		return
+	}
+
+	var interval time.Duration
+	if value := r.URL.Query().Get("interval"); value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds <= 0 {
+			ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid interval, expected a number of seconds", err))
+			return
+		}
+		interval = time.Duration(seconds) * time.Second
+	}
+
+	query := domain.DroneTelemetryQuery{From: from, To: to}
+	query.Downsample(interval)
+
+	history, err := h.service.GetTelemetry(r.Context(), id, query)
 	if err != nil {
 		ResponseWithError(w, err)
 		return
 	}
 
-	ResponseWithJSON(w, http.StatusOK, updatedDrone.ToDTO())
+	ResponseWithJSON(w, http.StatusOK, history)
 }
@@ -0,0 +1,177 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+
+	"drones/internal/core/domain"
+	"drones/internal/ports"
+	"drones/pkg/utils"
+)
+
+type GeofencesHandler struct {
+	service   ports.GeofenceService
+	validator *validator.Validate
+	logger    ports.Logger
+}
+
+func NewGeofencesHandler(service ports.GeofenceService, logger ports.Logger) *GeofencesHandler {
+	return &GeofencesHandler{
+		service:   service,
+		validator: domain.NewValidator(),
+		logger:    logger,
+	}
+}
+
+// RegisterRoutes registers the geofence routes, no-fly zones are managed by admins
+func (h *GeofencesHandler) RegisterRoutes(r *mux.Router) {
+	r.Handle("", AdminGuard(http.HandlerFunc(h.HandleListGeofences))).Methods("GET")
+	r.Handle("", AdminGuard(http.HandlerFunc(h.HandleCreateGeofence))).Methods("POST")
+	r.Handle("/violations", AdminGuard(http.HandlerFunc(h.HandleListViolations))).Methods("GET")
+	r.Handle("/{id}", AdminGuard(http.HandlerFunc(h.HandleUpdateGeofence))).Methods("PUT")
+	r.Handle("/{id}", AdminGuard(http.HandlerFunc(h.HandleDeleteGeofence))).Methods("DELETE")
+}
+
+// HandleListGeofences returns the geofences, whatever their time window
+func (h *GeofencesHandler) HandleListGeofences(w http.ResponseWriter, r *http.Request) {
+	zones, err := h.service.ListGeofences(r.Context())
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	ResponseWithJSON(w, http.StatusOK, zones)
+}
+
+// HandleCreateGeofence adds a polygon or circle no-fly zone
+func (h *GeofencesHandler) HandleCreateGeofence(w http.ResponseWriter, r *http.Request) {
+	var request domain.GeofenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid request body", err))
+		return
+	}
+
+	if err := h.validator.Struct(request); err != nil {
+		ResponseWithValidationError(w, http.StatusBadRequest, domain.GetValidationErrors(err.(validator.ValidationErrors)))
+		return
+	}
+	user, ok := UserFromContext(r.Context())
+	if !ok || user == nil {
+		ResponseWithCustomError(w, http.StatusUnauthorized, domain.DomainError{
+			Code:    domain.UserNotFoundError,
+			Message: "User not found in context",
+		})
+		return
+	}
+
+	zone, err := h.service.CreateGeofence(r.Context(), user.ID, &request)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	ResponseWithJSON(w, http.StatusCreated, zone)
+}
+
+// HandleUpdateGeofence replaces a geofence
+func (h *GeofencesHandler) HandleUpdateGeofence(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if !utils.ValidateUUID(id) {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid geofence ID format", nil))
+		return
+	}
+
+	var request domain.GeofenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid request body", err))
+		return
+	}
+
+	if err := h.validator.Struct(request); err != nil {
+		ResponseWithValidationError(w, http.StatusBadRequest, domain.GetValidationErrors(err.(validator.ValidationErrors)))
+		return
+	}
+	user, ok := UserFromContext(r.Context())
+	if !ok || user == nil {
+		ResponseWithCustomError(w, http.StatusUnauthorized, domain.DomainError{
+			Code:    domain.UserNotFoundError,
+			Message: "User not found in context",
+		})
+		return
+	}
+
+	zone, err := h.service.UpdateGeofence(r.Context(), id, user.ID, &request)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	ResponseWithJSON(w, http.StatusOK, zone)
+}
+
+// HandleDeleteGeofence deactivates a geofence
+func (h *GeofencesHandler) HandleDeleteGeofence(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if !utils.ValidateUUID(id) {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid geofence ID format", nil))
+		return
+	}
+	user, ok := UserFromContext(r.Context())
+	if !ok || user == nil {
+		ResponseWithCustomError(w, http.StatusUnauthorized, domain.DomainError{
+			Code:    domain.UserNotFoundError,
+			Message: "User not found in context",
+		})
+		return
+	}
+
+	if err := h.service.DeleteGeofence(r.Context(), id, user.ID); err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleListViolations lists drones that entered no-fly zones, optionally by geofence or drone
+func (h *GeofencesHandler) HandleListViolations(w http.ResponseWriter, r *http.Request) {
+	limit, _, err := GetPaginationParams(r)
+	if err != nil {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid limit", err))
+		return
+	}
+
+	filter := domain.GeofenceViolationFilter{Limit: limit}
+	if geofenceID := r.URL.Query().Get("geofence_id"); geofenceID != "" {
+		if !utils.ValidateUUID(geofenceID) {
+			ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid geofence ID format", nil))
+			return
+		}
+		filter.GeofenceID = &geofenceID
+	}
+	if droneID := r.URL.Query().Get("drone_id"); droneID != "" {
+		if !utils.ValidateUUID(droneID) {
+			ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid drone ID format", nil))
+			return
+		}
+		filter.DroneID = &droneID
+	}
+
+	violations, err := h.service.ListViolations(r.Context(), filter)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+	if violations == nil {
+		violations = []*domain.GeofenceViolation{}
+	}
+
+	ResponseWithJSON(w, http.StatusOK, violations)
+}
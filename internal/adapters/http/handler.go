@@ -19,6 +19,10 @@ type HTTPHandler struct {
 	authService    ports.AuthService
 	ordersService  ports.OrdersService
 	dronesService  ports.DronesService
+	pricingService ports.PricingService
+	geofences      ports.GeofenceService
+	serviceAreas   ports.ServiceAreaService
+	idempotency    ports.IdempotencyService
 	eventPublisher ports.EventPublisher
 	logger         ports.Logger
 	Validator      *validator.Validate
@@ -30,6 +34,10 @@ func NewHTTPHandler(
 	authService ports.AuthService,
 	ordersService ports.OrdersService,
 	dronesService ports.DronesService,
+	pricingService ports.PricingService,
+	geofences ports.GeofenceService,
+	serviceAreas ports.ServiceAreaService,
+	idempotency ports.IdempotencyService,
 	eventPublisher ports.EventPublisher,
 	logger ports.Logger,
 	apiPrefix string,
@@ -38,6 +46,10 @@ func NewHTTPHandler(
 		authService:    authService,
 		ordersService:  ordersService,
 		dronesService:  dronesService,
+		pricingService: pricingService,
+		geofences:      geofences,
+		serviceAreas:   serviceAreas,
+		idempotency:    idempotency,
 		eventPublisher: eventPublisher,
 		logger:         logger,
 		Validator:      domain.NewValidator(),
@@ -58,11 +70,15 @@ func (h *HTTPHandler) SetupRoutes(r *mux.Router) {
 	authHandler.RegisterRoutes(authRouter)
 
 	// Orders routes
-	ordersHandler := NewOrdersHandler(h.ordersService, h.eventPublisher, h.logger)
+	ordersHandler := NewOrdersHandler(h.ordersService, h.pricingService, h.eventPublisher, h.logger)
 	ordersRouter := r.PathPrefix(fmt.Sprintf("%s/orders", h.apiPrefix)).Subrouter()
 	ordersRouter.Use(func(next http.Handler) http.Handler {
 		return AuthenticateMiddleware(next, "*", h.authService)
 	})
+	// Retried mutations with the same Idempotency-Key are replayed
+	ordersRouter.Use(func(next http.Handler) http.Handler {
+		return IdempotencyMiddleware(next, h.idempotency)
+	})
 	ordersHandler.RegisterRoutes(ordersRouter)
 
 	dronesHandler := NewDronesHandler(h.dronesService, h.eventPublisher, h.logger)
@@ -72,6 +88,30 @@ func (h *HTTPHandler) SetupRoutes(r *mux.Router) {
 	})
 	dronesHandler.RegisterRoutes(dronesRouter)
 
+	// Pricing rules routes
+	pricingHandler := NewPricingHandler(h.pricingService, h.logger)
+	pricingRouter := r.PathPrefix(fmt.Sprintf("%s/pricing", h.apiPrefix)).Subrouter()
+	pricingRouter.Use(func(next http.Handler) http.Handler {
+		return AuthenticateMiddleware(next, "*", h.authService)
+	})
+	pricingHandler.RegisterRoutes(pricingRouter)
+
+	// No-fly zone routes
+	geofencesHandler := NewGeofencesHandler(h.geofences, h.logger)
+	geofencesRouter := r.PathPrefix(fmt.Sprintf("%s/geofences", h.apiPrefix)).Subrouter()
+	geofencesRouter.Use(func(next http.Handler) http.Handler {
+		return AuthenticateMiddleware(next, "*", h.authService)
+	})
+	geofencesHandler.RegisterRoutes(geofencesRouter)
+
+	// Service area routes
+	serviceAreasHandler := NewServiceAreasHandler(h.serviceAreas, h.logger)
+	serviceAreasRouter := r.PathPrefix(fmt.Sprintf("%s/service-areas", h.apiPrefix)).Subrouter()
+	serviceAreasRouter.Use(func(next http.Handler) http.Handler {
+		return AuthenticateMiddleware(next, "*", h.authService)
+	})
+	serviceAreasHandler.RegisterRoutes(serviceAreasRouter)
+
 	// TODO: Implement audit and activity logs handlers
 	// auditLogsHandler := NewAuditLogsHandler(h.logger)
 	// auditLogsRouter := r.PathPrefix(h.apiPrefix + "/audit-logs").Subrouter()
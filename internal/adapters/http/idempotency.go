@@ -0,0 +1,156 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"drones/internal/core/domain"
+	"drones/internal/ports"
+)
+
+// maxIdempotentBodyBytes limits the size of a request buffered for hashing
+const maxIdempotentBodyBytes = 10 << 20
+
+// IdempotencyMiddleware replays the stored response of a mutating request retried with
+// the same Idempotency-Key. Requests without the header are processed as usual.
+// A key reused with a different request, or while the first request is still being
+// processed, is rejected with a conflict. Only successful responses are stored, a failed
+// request releases its key so the client can retry it.
+func IdempotencyMiddleware(next http.Handler, idempotencyService ports.IdempotencyService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(domain.IdempotencyKeyHeader)
+		if key == "" || r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > domain.MaxIdempotencyKeyLength {
+			ResponseWithError(w, domain.ErrInvalidIdempotencyKey)
+			return
+		}
+
+		user, ok := UserFromContext(r.Context())
+		if !ok || user == nil {
+			ResponseWithCustomError(w, http.StatusUnauthorized, domain.DomainError{
+				Code:    domain.UnauthenticatedError,
+				Message: "User not authenticated",
+			})
+			return
+		}
+
+		// The body is buffered to be hashed, bounded by the largest body an orders endpoint accepts
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBodyBytes))
+		if err != nil {
+			ResponseWithError(w, err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		requestHash := idempotencyRequestHash(r, body)
+		record, err := idempotencyService.Begin(r.Context(), user.ID, key, requestHash)
+		if err != nil {
+			if domainErr, ok := err.(*domain.DomainError); ok && domainErr.Code == domain.ConflictError {
+				ResponseWithCustomError(w, http.StatusConflict, *domainErr)
+				return
+			}
+			ResponseWithError(w, err)
+			return
+		}
+
+		// Replay the stored response
+		if record != nil {
+			if record.ContentType != "" {
+				w.Header().Set("Content-Type", record.ContentType)
+			}
+			w.Header().Set(domain.IdempotentReplayedHeader, "true")
+			w.WriteHeader(record.StatusCode)
+			w.Write(record.Body)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		defer func() {
+			if p := recover(); p != nil {
+				idempotencyService.Release(r.Context(), user.ID, key)
+				panic(p)
+			}
+		}()
+
+		next.ServeHTTP(recorder, r)
+
+		if recorder.statusCode < http.StatusOK || recorder.statusCode >= http.StatusMultipleChoices {
+			idempotencyService.Release(r.Context(), user.ID, key)
+			return
+		}
+		idempotencyService.Complete(r.Context(), user.ID, key, &domain.IdempotencyRecord{
+			RequestHash: requestHash,
+			StatusCode:  recorder.statusCode,
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		})
+	})
+}
+
+// idempotencyRequestHash identifies a request by method, path and body. Multipart bodies
+// are hashed part by part so a retry with a new boundary is still the same request.
+func idempotencyRequestHash(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery+"\n")
+
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err == nil && strings.HasPrefix(mediaType, "multipart/") {
+		if partsHash, err := multipartHash(body, params["boundary"]); err == nil {
+			hash.Write(partsHash)
+			return hex.EncodeToString(hash.Sum(nil))
+		}
+	}
+
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func multipartHash(body []byte, boundary string) ([]byte, error) {
+	hash := sha256.New()
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return hash.Sum(nil), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		io.WriteString(hash, part.FormName()+"\x00"+part.FileName()+"\x00")
+		if _, err := io.Copy(hash, part); err != nil {
+			return nil, err
+		}
+		io.WriteString(hash, "\x00")
+	}
+}
+
+// responseRecorder passes the response through while keeping a copy for replay
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	if !r.wroteHeader {
+		r.statusCode = statusCode
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
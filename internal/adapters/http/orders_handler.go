@@ -1,9 +1,15 @@
 package http
 
 import (
+	"encoding/csv"
 	"encoding/json"
+	"io"
+	"mime"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
@@ -16,14 +22,16 @@ import (
 
 type OrdersHandler struct {
 	service        ports.OrdersService
+	pricingService ports.PricingService
 	validator      *validator.Validate
 	logger         ports.Logger
 	eventPublisher ports.EventPublisher
 }
 
-func NewOrdersHandler(service ports.OrdersService, eventPublisher ports.EventPublisher, logger ports.Logger) *OrdersHandler {
+func NewOrdersHandler(service ports.OrdersService, pricingService ports.PricingService, eventPublisher ports.EventPublisher, logger ports.Logger) *OrdersHandler {
 	return &OrdersHandler{
 		service:        service,
+		pricingService: pricingService,
 		validator:      domain.NewValidator(),
 		logger:         logger,
 		eventPublisher: eventPublisher,
@@ -35,15 +43,30 @@ func (h *OrdersHandler) RegisterRoutes(r *mux.Router) {
 	// Get current order
 	r.Handle("/current", DroneGuard(http.HandlerFunc(h.HandleGetCurrentOrder))).Methods("GET")
 
+	// Reservable jobs near the drone
+	r.Handle("/available", DroneGuard(http.HandlerFunc(h.HandleListAvailableJobs))).Methods("GET")
+	r.Handle("/claim", DroneGuard(http.HandlerFunc(h.HandleClaimNextOrder))).Methods("POST")
+
+	// Price a delivery before creating the order
+	r.HandleFunc("/quote", h.HandleQuoteOrder).Methods("POST")
+
+	// Upcoming scheduled orders
+	r.Handle("/scheduled", AdminGuard(http.HandlerFunc(h.HandleListScheduledOrders))).Methods("GET")
+
 	r.HandleFunc("", h.HandleCreateOrder).Methods("POST")
+	r.HandleFunc("/bulk", h.HandleBulkCreateOrders).Methods("POST")
 	r.HandleFunc("", h.HandleListOrders).Methods("GET")
 	r.HandleFunc("/{id}", h.HandleGetOrder).Methods("GET")
+	r.HandleFunc("/{id}/timeline", h.HandleGetOrderTimeline).Methods("GET")
+	r.HandleFunc("/{id}/track", h.HandleGetOrderTrack).Methods("GET")
 	r.Handle("/{id}", AdminGuard(http.HandlerFunc(h.HandleUpdateOrder))).Methods("PUT")
 
 	r.HandleFunc("/{id}", h.HandleUpdateOrder).Methods("PUT")
 
 	// r.HandleFunc("/{id}", h.HandleDeleteOrder).Methods("DELETE")
 	r.Handle("/{id}/withdraw", EndUserGuard(http.HandlerFunc(h.HandleOrderWithdrawn))).Methods("POST")
+	r.Handle("/{id}/cancel", AdminGuard(http.HandlerFunc(h.HandleCancelOrder))).Methods("POST")
+	r.Handle("/{id}/route", AdminGuard(http.HandlerFunc(h.HandleChangeRoute))).Methods("PUT")
 
 	// Drone actions
 	r.Handle("/{id}/reserve", DroneGuard(http.HandlerFunc(h.HandleReserveOrder))).Methods("POST")
@@ -52,10 +75,16 @@ func (h *OrdersHandler) RegisterRoutes(r *mux.Router) {
 	r.Handle("/{id}/confirm-arrival", DroneGuard(http.HandlerFunc(h.HandleConfirmArrival))).Methods("POST")
 	r.Handle("/{id}/confirm-delivery", DroneGuard(http.HandlerFunc(h.HandleConfirmDelivery))).Methods("POST")
 	r.Handle("/{id}/delivery-failed", DroneGuard(http.HandlerFunc(h.HandleDeliveryFailed))).Methods("POST")
+	r.Handle("/{id}/confirm-return", DroneGuard(http.HandlerFunc(h.HandleConfirmReturn))).Methods("POST")
+	r.Handle("/{id}/requeue", AdminGuard(http.HandlerFunc(h.HandleRequeueOrder))).Methods("POST")
+
+	// Proof of delivery, for the order owner and admins
+	r.HandleFunc("/{id}/proof", h.HandleGetDeliveryProof).Methods("GET")
+	r.HandleFunc("/{id}/proof/photo", h.HandleGetDeliveryPhoto).Methods("GET")
 
 	// Handoff endpoint
-	// r.HandleFunc("/{id}/handoff", h.HandleOrderHandoff).Methods("POST")
-	// r.HandleFunc("/{id}/reassign", h.HandleReassign).Methods("POST")
+	r.Handle("/{id}/handoff", DroneGuard(http.HandlerFunc(h.HandleOrderHandoff))).Methods("POST")
+	r.Handle("/{id}/reassign", DroneGuard(http.HandlerFunc(h.HandleReassign))).Methods("POST")
 
 	// Location
 	// r.HandleFunc("/{id}/heartbeat", h.HandleHeartbeat).Methods("POST")
@@ -95,6 +124,118 @@ func (h *OrdersHandler) HandleCreateOrder(w http.ResponseWriter, r *http.Request
 	ResponseWithJSON(w, http.StatusCreated, order.ToDTO())
 }
 
+// HandleQuoteOrder prices a delivery, the returned quote ID locks the price in when passed to create
+func (h *OrdersHandler) HandleQuoteOrder(w http.ResponseWriter, r *http.Request) {
+	var request domain.QuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid request body", err))
+		return
+	}
+
+	if err := h.validator.Struct(request); err != nil {
+		ResponseWithValidationError(w, http.StatusBadRequest, domain.GetValidationErrors(err.(validator.ValidationErrors)))
+		return
+	}
+	user, ok := UserFromContext(r.Context())
+	if !ok || user == nil {
+		ResponseWithCustomError(w, http.StatusUnauthorized, domain.DomainError{
+			Code:    domain.UserNotFoundError,
+			Message: "User not found in context",
+		})
+		return
+	}
+
+	quote, err := h.pricingService.Quote(r.Context(), user.ID, &request)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	ResponseWithJSON(w, http.StatusOK, quote)
+}
+
+// HandleBulkCreateOrders creates orders from a JSON array or a CSV upload and reports a result per row
+func (h *OrdersHandler) HandleBulkCreateOrders(w http.ResponseWriter, r *http.Request) {
+	meta := domain.ExtractRequestInfo(r)
+	h.logger.Info("Creating bulk orders", zap.Any("data", meta))
+
+	user, ok := UserFromContext(r.Context())
+	if !ok || user == nil {
+		ResponseWithCustomError(w, http.StatusUnauthorized, domain.DomainError{
+			Code:    domain.UserNotFoundError,
+			Message: "User not found in context",
+		})
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBulkUploadBytes)
+
+	requests, rowErrors, err := parseBulkOrders(r)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+	if len(requests) == 0 {
+		ResponseWithError(w, domain.ErrBulkOrdersEmpty)
+		return
+	}
+	if len(requests) > domain.MaxBulkOrders {
+		ResponseWithError(w, domain.ErrBulkOrdersTooLarge)
+		return
+	}
+
+	// Validate row by row, only valid rows reach the service
+	results := make([]domain.BulkOrderResult, 0, len(requests))
+	rows := make([]domain.BulkOrderRow, 0, len(requests))
+	for i, request := range requests {
+		row := i + 1
+		if errs, ok := rowErrors[row]; ok {
+			results = append(results, domain.BulkOrderResult{Row: row, Errors: errs})
+			continue
+		}
+		if err := h.validator.Struct(request); err != nil {
+			if validationErrs, ok := err.(validator.ValidationErrors); ok {
+				results = append(results, domain.BulkOrderResult{Row: row, Errors: domain.GetValidationErrors(validationErrs)})
+				continue
+			}
+			ResponseWithError(w, err)
+			return
+		}
+		rows = append(rows, domain.BulkOrderRow{Row: row, Order: request})
+	}
+
+	if len(rows) > 0 {
+		created, err := h.service.BulkCreateOrders(r.Context(), user.ID, rows)
+		if err != nil {
+			ResponseWithError(w, err)
+			return
+		}
+		results = append(results, created...)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Row < results[j].Row
+	})
+
+	response := domain.BulkCreateOrdersResponse{
+		Total:   len(requests),
+		Results: results,
+	}
+	for _, result := range results {
+		if result.OrderID != nil {
+			response.Created++
+		} else {
+			response.Failed++
+		}
+	}
+
+	status := http.StatusCreated
+	if response.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	ResponseWithJSON(w, status, response)
+}
+
 // HandleGetOrder retrieves a order by ID
 func (h *OrdersHandler) HandleGetOrder(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -136,9 +277,79 @@ func (h *OrdersHandler) HandleGetOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	SetETag(w, order.Version)
 	ResponseWithJSON(w, http.StatusOK, order.ToDTO())
 }
 
+// HandleGetOrderTimeline returns the lifecycle steps of an order
+func (h *OrdersHandler) HandleGetOrderTimeline(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		ResponseWithResouseNotFound(w, "Orders ID")
+		return
+	}
+
+	if !utils.ValidateUUID(id) {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid order ID format", nil))
+		return
+	}
+	user, ok := UserFromContext(r.Context())
+	if !ok || user == nil {
+		ResponseWithCustomError(w, http.StatusUnauthorized, domain.DomainError{
+			Code:    domain.UserNotFoundError,
+			Message: "User not found in context",
+		})
+		return
+	}
+
+	// Same ownership rules as HandleGetOrder
+	filter := domain.OrderFilter{}
+	switch user.Type {
+	case domain.UserTypeEnduser:
+		filter.UserID = &user.ID
+	case domain.UserTypeDrone:
+		filter.DroneID = user.DroneId
+		filter.DeliveredByDroneID = user.DroneId
+	case domain.UserTypeAdmin:
+		// Admin can see all orders
+	}
+
+	timeline, err := h.service.GetOrderTimeline(r.Context(), id, filter)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	ResponseWithJSON(w, http.StatusOK, timeline)
+}
+
+// HandleGetOrderTrack returns the flight path of an order as a GeoJSON FeatureCollection,
+// for the order owner and admins
+func (h *OrdersHandler) HandleGetOrderTrack(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if !utils.ValidateUUID(id) {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid order ID format", nil))
+		return
+	}
+
+	filter, ok := ownerOrAdminFilter(w, r)
+	if !ok {
+		return
+	}
+
+	track, err := h.service.GetOrderTrack(r.Context(), id, *filter)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	ResponseWithGeoJSON(w, http.StatusOK, track)
+}
+
 // HandleUpdateOrder updates an existing order
 func (h *OrdersHandler) HandleUpdateOrder(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -182,7 +393,123 @@ func (h *OrdersHandler) HandleUpdateOrder(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// Record the admin as the actor of any status change
+	request.UpdatedByID = &user.ID
+
+	// Only update the version the admin last read
+	expectedVersion, err := GetIfMatchVersion(r)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+	request.ExpectedVersion = expectedVersion
+
 	order, err := h.service.UpdateOrder(r.Context(), id, &request, domain.OrderFilter{})
+	if err != nil {
+		if err == domain.ErrOrderVersionConflict {
+			ResponseWithCustomError(w, http.StatusPreconditionFailed, *domain.ErrOrderVersionConflict)
+			return
+		}
+		ResponseWithError(w, err)
+		return
+	}
+
+	SetETag(w, order.Version)
+	ResponseWithJSON(w, http.StatusOK, order.ToDTO())
+}
+
+// HandleChangeRoute moves the origin or destination of an order, repriced and pushed to its drone
+func (h *OrdersHandler) HandleChangeRoute(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		ResponseWithResouseNotFound(w, "Orders ID")
+		return
+	}
+
+	if !utils.ValidateUUID(id) {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid order ID format", nil))
+		return
+	}
+	user, ok := UserFromContext(r.Context())
+	if !ok || user == nil {
+		ResponseWithCustomError(w, http.StatusUnauthorized, domain.DomainError{
+			Code:    domain.UnauthorizedError,
+			Message: "User not found in context",
+		})
+		return
+	}
+
+	var request domain.ChangeRouteRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid request body", err))
+		return
+	}
+
+	if err := h.validator.Struct(request); err != nil {
+		ResponseWithValidationError(w, http.StatusBadRequest, domain.GetValidationErrors(err.(validator.ValidationErrors)))
+		return
+	}
+
+	// Only change the route of the version the admin last read
+	expectedVersion, err := GetIfMatchVersion(r)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+	request.ExpectedVersion = expectedVersion
+
+	order, err := h.service.ChangeRoute(r.Context(), id, user.ID, &request)
+	if err != nil {
+		if err == domain.ErrOrderVersionConflict {
+			ResponseWithCustomError(w, http.StatusPreconditionFailed, *domain.ErrOrderVersionConflict)
+			return
+		}
+		ResponseWithError(w, err)
+		return
+	}
+
+	SetETag(w, order.Version)
+	ResponseWithJSON(w, http.StatusOK, order.ToDTO())
+}
+
+// HandleCancelOrder cancels an order from any open status with a reason code
+func (h *OrdersHandler) HandleCancelOrder(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		ResponseWithResouseNotFound(w, "Orders ID")
+		return
+	}
+
+	if !utils.ValidateUUID(id) {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid order ID format", nil))
+		return
+	}
+
+	user, ok := UserFromContext(r.Context())
+	if !ok || user == nil {
+		ResponseWithCustomError(w, http.StatusUnauthorized, domain.DomainError{
+			Code:    domain.UnauthorizedError,
+			Message: "User not found in context",
+		})
+		return
+	}
+
+	var request domain.CancelOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	if err := h.validator.Struct(request); err != nil {
+		ResponseWithValidationError(w, http.StatusBadRequest, domain.GetValidationErrors(err.(validator.ValidationErrors)))
+		return
+	}
+
+	order, err := h.service.CancelOrder(r.Context(), id, user.ID, &request)
 	if err != nil {
 		ResponseWithError(w, err)
 		return
@@ -253,6 +580,11 @@ func (h *OrdersHandler) HandleListOrders(w http.ResponseWriter, r *http.Request)
 		filter.Status = &orderStatus
 	}
 
+	if priority := r.URL.Query().Get("priority"); priority != "" {
+		orderPriority := domain.OrderPriority(priority)
+		filter.Priority = &orderPriority
+	}
+
 	if active := r.URL.Query().Get("active"); active != "" {
 		if activeBool, err := strconv.ParseBool(active); err == nil {
 			filter.Active = &activeBool
@@ -273,6 +605,46 @@ func (h *OrdersHandler) HandleListOrders(w http.ResponseWriter, r *http.Request)
 	ResponseWithJSON(w, http.StatusOK, result)
 }
 
+// HandleListScheduledOrders lists scheduled orders not yet released, soonest first.
+// The window defaults to the next DefaultScheduledOrdersWindow.
+func (h *OrdersHandler) HandleListScheduledOrders(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := GetPaginationParams(r)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	now := time.Now()
+	from, err := getTimeParam(r, "from", now)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+	to, err := getTimeParam(r, "to", now.Add(domain.DefaultScheduledOrdersWindow))
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	status := domain.OrderStatusScheduled
+	filter := domain.OrderFilter{
+		Status:          &status,
+		ScheduledAtFrom: &from,
+		ScheduledAtTo:   &to,
+	}
+
+	result, err := h.service.ListOrders(r.Context(), domain.PaginationOption[domain.OrderFilter]{
+		Filter: &filter,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+	ResponseWithJSON(w, http.StatusOK, result)
+}
+
 func (s *OrdersHandler) HandleOrderWithdrawn(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	orderID := vars["id"]
@@ -486,17 +858,115 @@ func (h *OrdersHandler) HandleConfirmDelivery(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	order, err := h.service.ConfirmDelivery(r.Context(), orderID, user.ID, domain.OrderFilter{
-		DroneID: user.DroneId,
-	})
+	r.Body = http.MaxBytesReader(w, r.Body, maxDeliveryProofBytes)
+
+	request, errs, err := parseConfirmDelivery(r)
 	if err != nil {
-		ResponseWithError(w, err)
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid request body", err))
+		return
+	}
+	if len(errs) > 0 {
+		ResponseWithValidationError(w, http.StatusBadRequest, errs)
+		return
+	}
+	if err := h.validator.Struct(request); err != nil {
+		ResponseWithValidationError(w, http.StatusBadRequest, domain.GetValidationErrors(err.(validator.ValidationErrors)))
+		return
+	}
+
+	order, err := h.service.ConfirmDelivery(r.Context(), orderID, user.ID, request, domain.OrderFilter{
+		DroneID: user.DroneId,
+	})
+	if err != nil {
+		ResponseWithError(w, err)
 		return
 	}
 
 	ResponseWithJSON(w, http.StatusOK, order.ToDTO())
 }
 
+// ownerOrAdminFilter restricts an order, its proof and its track to the order owner and admins
+func ownerOrAdminFilter(w http.ResponseWriter, r *http.Request) (*domain.OrderFilter, bool) {
+	user, ok := UserFromContext(r.Context())
+	if !ok || user == nil {
+		ResponseWithCustomError(w, http.StatusUnauthorized, domain.DomainError{
+			Code:    domain.UserNotFoundError,
+			Message: "User not found in context",
+		})
+		return nil, false
+	}
+
+	filter := domain.OrderFilter{}
+	switch user.Type {
+	case domain.UserTypeEnduser:
+		filter.UserID = &user.ID
+	case domain.UserTypeAdmin:
+		// Admin can see all orders
+	default:
+		ResponseWithCustomError(w, http.StatusForbidden, domain.DomainError{
+			Code:    domain.AccessDeniedError,
+			Message: "Access denied for this user type",
+		})
+		return nil, false
+	}
+
+	return &filter, true
+}
+
+// HandleGetDeliveryProof returns the proof recorded when the order was delivered
+func (h *OrdersHandler) HandleGetDeliveryProof(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	orderID := vars["id"]
+
+	if !utils.ValidateUUID(orderID) {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid order ID format", nil))
+		return
+	}
+
+	filter, ok := ownerOrAdminFilter(w, r)
+	if !ok {
+		return
+	}
+
+	proof, err := h.service.GetDeliveryProof(r.Context(), orderID, *filter)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	ResponseWithJSON(w, http.StatusOK, proof.ToDTO())
+}
+
+// HandleGetDeliveryPhoto streams the photo attached to the delivery proof
+func (h *OrdersHandler) HandleGetDeliveryPhoto(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	orderID := vars["id"]
+
+	if !utils.ValidateUUID(orderID) {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid order ID format", nil))
+		return
+	}
+
+	filter, ok := ownerOrAdminFilter(w, r)
+	if !ok {
+		return
+	}
+
+	photo, err := h.service.GetDeliveryPhoto(r.Context(), orderID, *filter)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", photo.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(photo.Data)))
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(photo.Data); err != nil {
+		h.logger.Error("Failed to write delivery photo", "orderID", orderID, "error", err)
+	}
+}
+
 func (h *OrdersHandler) HandleDeliveryFailed(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	orderID := vars["id"]
@@ -526,7 +996,19 @@ func (h *OrdersHandler) HandleDeliveryFailed(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	order, err := h.service.DeliveryFailed(r.Context(), orderID, user.ID, domain.OrderFilter{
+	// The failure report is optional
+	var request domain.DeliveryFailedRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && err != io.EOF {
+		ResponseWithError(w, err)
+		return
+	}
+
+	if err := h.validator.Struct(request); err != nil {
+		ResponseWithValidationError(w, http.StatusBadRequest, domain.GetValidationErrors(err.(validator.ValidationErrors)))
+		return
+	}
+
+	order, err := h.service.DeliveryFailed(r.Context(), orderID, user.ID, &request, domain.OrderFilter{
 		DroneID: user.DroneId,
 	})
 	if err != nil {
@@ -537,6 +1019,122 @@ func (h *OrdersHandler) HandleDeliveryFailed(w http.ResponseWriter, r *http.Requ
 	ResponseWithJSON(w, http.StatusOK, order.ToDTO())
 }
 
+// HandleConfirmReturn closes an order the drone brought back to the sender, re-queues one
+// it brought back to the origin, or holds one it dropped off at the depot
+func (h *OrdersHandler) HandleConfirmReturn(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	orderID := vars["id"]
+
+	if orderID == "" {
+		ResponseWithResouseNotFound(w, "Order ID")
+		return
+	}
+
+	if !utils.ValidateUUID(orderID) {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid order ID format", nil))
+		return
+	}
+	user, ok := UserFromContext(r.Context())
+	if !ok || user == nil {
+		ResponseWithCustomError(w, http.StatusUnauthorized, domain.DomainError{
+			Code:    domain.UserNotFoundError,
+			Message: "User not found in context",
+		})
+		return
+	}
+	if user.DroneId == nil {
+		ResponseWithCustomError(w, http.StatusUnauthorized, domain.DomainError{
+			Code:    domain.UserNotFoundError,
+			Message: "Drone ID not found for user",
+		})
+		return
+	}
+
+	order, err := h.service.ConfirmReturned(r.Context(), orderID, user.ID, domain.OrderFilter{
+		DroneID: user.DroneId,
+	})
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	ResponseWithJSON(w, http.StatusOK, order.ToDTO())
+}
+
+// HandleRequeueOrder puts an order held at the depot up for another drone
+func (h *OrdersHandler) HandleRequeueOrder(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	orderID := vars["id"]
+
+	if orderID == "" {
+		ResponseWithResouseNotFound(w, "Order ID")
+		return
+	}
+
+	if !utils.ValidateUUID(orderID) {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid order ID format", nil))
+		return
+	}
+	user, ok := UserFromContext(r.Context())
+	if !ok || user == nil {
+		ResponseWithCustomError(w, http.StatusUnauthorized, domain.DomainError{
+			Code:    domain.UnauthorizedError,
+			Message: "User not found in context",
+		})
+		return
+	}
+
+	order, err := h.service.RequeueHeldOrder(r.Context(), orderID, user.ID)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	ResponseWithJSON(w, http.StatusOK, order.ToDTO())
+}
+
+// HandleOrderHandoff puts a failed order up for a rescue drone at the current drone position
+func (h *OrdersHandler) HandleOrderHandoff(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	orderID := vars["id"]
+
+	if orderID == "" {
+		ResponseWithResouseNotFound(w, "Order ID")
+		return
+	}
+
+	if !utils.ValidateUUID(orderID) {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid order ID format", nil))
+		return
+	}
+	user, ok := UserFromContext(r.Context())
+	if !ok || user == nil {
+		ResponseWithCustomError(w, http.StatusUnauthorized, domain.DomainError{
+			Code:    domain.UserNotFoundError,
+			Message: "User not found in context",
+		})
+		return
+	}
+	if user.DroneId == nil {
+		ResponseWithCustomError(w, http.StatusUnauthorized, domain.DomainError{
+			Code:    domain.UserNotFoundError,
+			Message: "Drone ID not found for user",
+		})
+		return
+	}
+
+	order, err := h.service.Handoff(r.Context(), orderID, user.ID, domain.OrderFilter{
+		DroneID: user.DroneId,
+	})
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	ResponseWithJSON(w, http.StatusOK, order.ToDTO())
+}
+
+// HandleReassign lets a rescue drone take over a handoff order
 func (h *OrdersHandler) HandleReassign(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	orderID := vars["id"]
@@ -628,3 +1226,344 @@ func (h *OrdersHandler) HandleGetCurrentOrder(w http.ResponseWriter, r *http.Req
 
 	ResponseWithJSON(w, http.StatusOK, order.ToDTO())
 }
+
+// HandleListAvailableJobs lists pending and handoff orders the calling drone can take
+func (h *OrdersHandler) HandleListAvailableJobs(w http.ResponseWriter, r *http.Request) {
+	user, ok := UserFromContext(r.Context())
+	if !ok || user == nil {
+		ResponseWithCustomError(w, http.StatusUnauthorized, domain.DomainError{
+			Code:    domain.UserNotFoundError,
+			Message: "User not found in context",
+		})
+		return
+	}
+
+	limit, _, err := GetPaginationParams(r)
+	if err != nil {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid limit", err))
+		return
+	}
+
+	radiusKm, err := getRadiusParam(r)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	jobs, err := h.service.ListAvailableJobs(r.Context(), user.ID, radiusKm, limit)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	ResponseWithJSON(w, http.StatusOK, map[string]interface{}{
+		"data":      jobs,
+		"radius_km": radiusKm,
+	})
+}
+
+// HandleClaimNextOrder reserves the best pending order near the calling drone
+func (h *OrdersHandler) HandleClaimNextOrder(w http.ResponseWriter, r *http.Request) {
+	user, ok := UserFromContext(r.Context())
+	if !ok || user == nil {
+		ResponseWithCustomError(w, http.StatusUnauthorized, domain.DomainError{
+			Code:    domain.UserNotFoundError,
+			Message: "User not found in context",
+		})
+		return
+	}
+
+	radiusKm, err := getRadiusParam(r)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	order, err := h.service.ClaimNextOrder(r.Context(), user.ID, radiusKm)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	ResponseWithJSON(w, http.StatusOK, order.ToDTO())
+}
+
+// getRadiusParam reads the optional radius_km query param, capped to MaxJobsRadiusKm
+func getRadiusParam(r *http.Request) (float64, error) {
+	radius := r.URL.Query().Get("radius_km")
+	if radius == "" {
+		return domain.DefaultJobsRadiusKm, nil
+	}
+
+	radiusKm, err := strconv.ParseFloat(radius, 64)
+	if err != nil || radiusKm <= 0 {
+		return 0, domain.NewDomainError(domain.InvalidInputError, "Invalid radius_km", err)
+	}
+	if radiusKm > domain.MaxJobsRadiusKm {
+		radiusKm = domain.MaxJobsRadiusKm
+	}
+	return radiusKm, nil
+}
+
+// getTimeParam parses an RFC3339 query parameter and returns it in UTC, like scheduled_at is stored
+func getTimeParam(r *http.Request, name string, fallback time.Time) (string, error) {
+	parsed, err := parseTimeParam(r, name, fallback)
+	if err != nil {
+		return "", err
+	}
+	return parsed.Format(time.RFC3339), nil
+}
+
+// parseTimeParam parses an RFC3339 query parameter in UTC, fallback when it is missing
+func parseTimeParam(r *http.Request, name string, fallback time.Time) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback.UTC(), nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, domain.NewDomainError(domain.InvalidInputError, "Invalid "+name+", expected RFC3339", err)
+	}
+	return parsed.UTC(), nil
+}
+
+// maxDeliveryProofBytes limits the size of a delivery confirmation, photo included
+const maxDeliveryProofBytes = 10 << 20
+
+// parseConfirmDelivery reads a delivery confirmation, either a JSON body or a multipart form with
+// the delivery_code, lat and lon fields and an optional photo file
+func parseConfirmDelivery(r *http.Request) (*domain.ConfirmDeliveryRequest, domain.ValidationErrors, error) {
+	request := &domain.ConfirmDeliveryRequest{}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+			return nil, nil, err
+		}
+		return request, nil, nil
+	}
+
+	if err := r.ParseMultipartForm(maxDeliveryProofBytes); err != nil {
+		return nil, nil, err
+	}
+
+	errs := make(domain.ValidationErrors)
+	parseFloat := func(field string) float64 {
+		value := strings.TrimSpace(r.FormValue(field))
+		if value == "" {
+			return 0
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			errs[field] = append(errs[field], domain.ValidationError{
+				Code:    "numeric",
+				Message: "This field must contain only numbers",
+				Value:   value,
+			})
+		}
+		return f
+	}
+
+	request.DeliveryCode = strings.TrimSpace(r.FormValue("delivery_code"))
+	request.Lat = parseFloat("lat")
+	request.Lon = parseFloat("lon")
+
+	file, _, err := r.FormFile("photo")
+	switch err {
+	case nil:
+		defer file.Close()
+		photo, err := io.ReadAll(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		request.Photo = photo
+		request.PhotoContentType = http.DetectContentType(photo)
+	case http.ErrMissingFile:
+		// The photo is optional
+	default:
+		return nil, nil, err
+	}
+
+	return request, errs, nil
+}
+
+// maxBulkUploadBytes limits the size of a bulk orders body or upload
+const maxBulkUploadBytes = 10 << 20
+
+// parseBulkOrders reads the orders of a bulk request, either a JSON array, a CSV body or a multipart
+// CSV upload in the "file" field. Rows that cannot be decoded are reported by their 1-based row number.
+func parseBulkOrders(r *http.Request) ([]*domain.CreateOrderRequest, map[int]domain.ValidationErrors, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		mediaType = "application/json"
+	}
+
+	switch mediaType {
+	case "text/csv":
+		return parseBulkOrdersCSV(r.Body)
+	case "multipart/form-data":
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			return nil, nil, domain.NewDomainError(domain.MissingParameterError, "CSV file is required in the file field", err)
+		}
+		defer file.Close()
+		return parseBulkOrdersCSV(file)
+	default:
+		return parseBulkOrdersJSON(r.Body)
+	}
+}
+
+func parseBulkOrdersJSON(body io.Reader) ([]*domain.CreateOrderRequest, map[int]domain.ValidationErrors, error) {
+	var raw []json.RawMessage
+	if err := json.NewDecoder(body).Decode(&raw); err != nil {
+		if err == io.EOF {
+			return nil, nil, err
+		}
+		return nil, nil, domain.NewDomainError(domain.InvalidInputError, "Request body must be a JSON array of orders", err)
+	}
+
+	requests := make([]*domain.CreateOrderRequest, len(raw))
+	rowErrors := make(map[int]domain.ValidationErrors)
+	for i, item := range raw {
+		requests[i] = &domain.CreateOrderRequest{}
+		if err := json.Unmarshal(item, requests[i]); err != nil {
+			field := "order"
+			if typeErr, ok := err.(*json.UnmarshalTypeError); ok && typeErr.Field != "" {
+				field = typeErr.Field
+			}
+			rowErrors[i+1] = domain.ValidationErrors{field: {{
+				Code:    "json",
+				Message: "This field has an invalid type",
+			}}}
+		}
+	}
+
+	return requests, rowErrors, nil
+}
+
+func parseBulkOrdersCSV(body io.Reader) ([]*domain.CreateOrderRequest, map[int]domain.ValidationErrors, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, nil, domain.ErrBulkOrdersEmpty
+		}
+		return nil, nil, domain.NewDomainError(domain.InvalidInputError, "Invalid CSV header", err)
+	}
+	for i, column := range header {
+		column = utils.ToSnakeCase(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		if !isBulkOrderColumn(column) {
+			return nil, nil, domain.NewDomainError(domain.InvalidInputError, "Unknown CSV column "+column, nil)
+		}
+		header[i] = column
+	}
+
+	var requests []*domain.CreateOrderRequest
+	rowErrors := make(map[int]domain.ValidationErrors)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, domain.NewDomainError(domain.InvalidInputError, "Invalid CSV content", err)
+		}
+		if len(requests) == domain.MaxBulkOrders {
+			return nil, nil, domain.ErrBulkOrdersTooLarge
+		}
+
+		request, errs := csvOrderRow(header, record)
+		requests = append(requests, request)
+		if len(errs) > 0 {
+			rowErrors[len(requests)] = errs
+		}
+	}
+
+	return requests, rowErrors, nil
+}
+
+var bulkOrderColumns = []string{
+	"receiver_name", "receiver_phone", "delivery_note", "package_weight_kg",
+	"origin_address", "origin_lat", "origin_lon",
+	"destination_address", "destination_lat", "destination_lon", "scheduled_at",
+	"quote_id", "priority", "required_capabilities",
+}
+
+func isBulkOrderColumn(column string) bool {
+	for _, c := range bulkOrderColumns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// csvOrderRow maps a CSV record to a create request, empty cells are left unset
+func csvOrderRow(header, record []string) (*domain.CreateOrderRequest, domain.ValidationErrors) {
+	request := &domain.CreateOrderRequest{}
+	errs := make(domain.ValidationErrors)
+
+	parseFloat := func(column, value string) float64 {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			errs[column] = append(errs[column], domain.ValidationError{
+				Code:    "numeric",
+				Message: "This field must contain only numbers",
+				Value:   value,
+			})
+		}
+		return f
+	}
+
+	for i, column := range header {
+		if i >= len(record) {
+			break
+		}
+		value := strings.TrimSpace(record[i])
+		if value == "" {
+			continue
+		}
+
+		switch column {
+		case "receiver_name":
+			request.ReceiverName = &value
+		case "receiver_phone":
+			request.ReceiverPhone = &value
+		case "delivery_note":
+			request.DeliveryNote = &value
+		case "package_weight_kg":
+			weight := parseFloat(column, value)
+			request.PackageWeightKg = &weight
+		case "origin_address":
+			request.OriginAddress = value
+		case "origin_lat":
+			request.OriginLat = parseFloat(column, value)
+		case "origin_lon":
+			request.OriginLon = parseFloat(column, value)
+		case "destination_address":
+			request.DestinationAddress = value
+		case "destination_lat":
+			request.DestinationLat = parseFloat(column, value)
+		case "destination_lon":
+			request.DestinationLon = parseFloat(column, value)
+		case "scheduled_at":
+			request.ScheduledAt = &value
+		case "quote_id":
+			request.QuoteID = &value
+		case "priority":
+			request.Priority = domain.OrderPriority(value)
+		case "required_capabilities":
+			// Several capabilities share the cell, separated by semicolons
+			for _, capability := range strings.Split(value, ";") {
+				if capability = strings.TrimSpace(capability); capability != "" {
+					request.RequiredCapabilities = append(request.RequiredCapabilities, domain.DroneCapability(capability))
+				}
+			}
+		}
+	}
+
+	return request, errs
+}
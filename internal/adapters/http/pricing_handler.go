@@ -0,0 +1,140 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+
+	"drones/internal/core/domain"
+	"drones/internal/ports"
+	"drones/pkg/utils"
+)
+
+type PricingHandler struct {
+	service   ports.PricingService
+	validator *validator.Validate
+	logger    ports.Logger
+}
+
+func NewPricingHandler(service ports.PricingService, logger ports.Logger) *PricingHandler {
+	return &PricingHandler{
+		service:   service,
+		validator: domain.NewValidator(),
+		logger:    logger,
+	}
+}
+
+// RegisterRoutes registers the pricing rule routes, rules are managed by admins
+func (h *PricingHandler) RegisterRoutes(r *mux.Router) {
+	r.Handle("/rules", AdminGuard(http.HandlerFunc(h.HandleListRules))).Methods("GET")
+	r.Handle("/rules", AdminGuard(http.HandlerFunc(h.HandleCreateRule))).Methods("POST")
+	r.Handle("/rules/{id}", AdminGuard(http.HandlerFunc(h.HandleUpdateRule))).Methods("PUT")
+	r.Handle("/rules/{id}", AdminGuard(http.HandlerFunc(h.HandleDeleteRule))).Methods("DELETE")
+}
+
+// HandleListRules returns the active pricing rules
+func (h *PricingHandler) HandleListRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.service.ListRules(r.Context())
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	ResponseWithJSON(w, http.StatusOK, rules)
+}
+
+// HandleCreateRule adds a tariff or a time-of-day surcharge
+func (h *PricingHandler) HandleCreateRule(w http.ResponseWriter, r *http.Request) {
+	var request domain.PricingRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid request body", err))
+		return
+	}
+
+	if err := h.validator.Struct(request); err != nil {
+		ResponseWithValidationError(w, http.StatusBadRequest, domain.GetValidationErrors(err.(validator.ValidationErrors)))
+		return
+	}
+	user, ok := UserFromContext(r.Context())
+	if !ok || user == nil {
+		ResponseWithCustomError(w, http.StatusUnauthorized, domain.DomainError{
+			Code:    domain.UserNotFoundError,
+			Message: "User not found in context",
+		})
+		return
+	}
+
+	rule, err := h.service.CreateRule(r.Context(), user.ID, &request)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	ResponseWithJSON(w, http.StatusCreated, rule)
+}
+
+// HandleUpdateRule replaces a pricing rule
+func (h *PricingHandler) HandleUpdateRule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if !utils.ValidateUUID(id) {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid pricing rule ID format", nil))
+		return
+	}
+
+	var request domain.PricingRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid request body", err))
+		return
+	}
+
+	if err := h.validator.Struct(request); err != nil {
+		ResponseWithValidationError(w, http.StatusBadRequest, domain.GetValidationErrors(err.(validator.ValidationErrors)))
+		return
+	}
+	user, ok := UserFromContext(r.Context())
+	if !ok || user == nil {
+		ResponseWithCustomError(w, http.StatusUnauthorized, domain.DomainError{
+			Code:    domain.UserNotFoundError,
+			Message: "User not found in context",
+		})
+		return
+	}
+
+	rule, err := h.service.UpdateRule(r.Context(), id, user.ID, &request)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	ResponseWithJSON(w, http.StatusOK, rule)
+}
+
+// HandleDeleteRule deactivates a pricing rule
+func (h *PricingHandler) HandleDeleteRule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if !utils.ValidateUUID(id) {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid pricing rule ID format", nil))
+		return
+	}
+	user, ok := UserFromContext(r.Context())
+	if !ok || user == nil {
+		ResponseWithCustomError(w, http.StatusUnauthorized, domain.DomainError{
+			Code:    domain.UserNotFoundError,
+			Message: "User not found in context",
+		})
+		return
+	}
+
+	if err := h.service.DeleteRule(r.Context(), id, user.ID); err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -10,7 +10,16 @@ import (
 )
 
 func ResponseWithJSON(w http.ResponseWriter, status int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
+	responseWithContentType(w, status, "application/json", data)
+}
+
+// ResponseWithGeoJSON writes a GeoJSON object, the same as JSON with the GeoJSON media type
+func ResponseWithGeoJSON(w http.ResponseWriter, status int, data interface{}) {
+	responseWithContentType(w, status, "application/geo+json", data)
+}
+
+func responseWithContentType(w http.ResponseWriter, status int, contentType string, data interface{}) {
+	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(data)
 }
@@ -0,0 +1,140 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+
+	"drones/internal/core/domain"
+	"drones/internal/ports"
+	"drones/pkg/utils"
+)
+
+type ServiceAreasHandler struct {
+	service   ports.ServiceAreaService
+	validator *validator.Validate
+	logger    ports.Logger
+}
+
+func NewServiceAreasHandler(service ports.ServiceAreaService, logger ports.Logger) *ServiceAreasHandler {
+	return &ServiceAreasHandler{
+		service:   service,
+		validator: domain.NewValidator(),
+		logger:    logger,
+	}
+}
+
+// RegisterRoutes registers the service area routes, service areas are managed by admins
+func (h *ServiceAreasHandler) RegisterRoutes(r *mux.Router) {
+	r.Handle("", AdminGuard(http.HandlerFunc(h.HandleListServiceAreas))).Methods("GET")
+	r.Handle("", AdminGuard(http.HandlerFunc(h.HandleCreateServiceArea))).Methods("POST")
+	r.Handle("/{id}", AdminGuard(http.HandlerFunc(h.HandleUpdateServiceArea))).Methods("PUT")
+	r.Handle("/{id}", AdminGuard(http.HandlerFunc(h.HandleDeleteServiceArea))).Methods("DELETE")
+}
+
+// HandleListServiceAreas returns the service areas
+func (h *ServiceAreasHandler) HandleListServiceAreas(w http.ResponseWriter, r *http.Request) {
+	areas, err := h.service.ListServiceAreas(r.Context())
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	ResponseWithJSON(w, http.StatusOK, areas)
+}
+
+// HandleCreateServiceArea adds a region the fleet operates in
+func (h *ServiceAreasHandler) HandleCreateServiceArea(w http.ResponseWriter, r *http.Request) {
+	var request domain.ServiceAreaRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid request body", err))
+		return
+	}
+
+	if err := h.validator.Struct(request); err != nil {
+		ResponseWithValidationError(w, http.StatusBadRequest, domain.GetValidationErrors(err.(validator.ValidationErrors)))
+		return
+	}
+	user, ok := UserFromContext(r.Context())
+	if !ok || user == nil {
+		ResponseWithCustomError(w, http.StatusUnauthorized, domain.DomainError{
+			Code:    domain.UserNotFoundError,
+			Message: "User not found in context",
+		})
+		return
+	}
+
+	area, err := h.service.CreateServiceArea(r.Context(), user.ID, &request)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	ResponseWithJSON(w, http.StatusCreated, area)
+}
+
+// HandleUpdateServiceArea replaces a service area, orders already placed keep it
+func (h *ServiceAreasHandler) HandleUpdateServiceArea(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if !utils.ValidateUUID(id) {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid service area ID format", nil))
+		return
+	}
+
+	var request domain.ServiceAreaRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid request body", err))
+		return
+	}
+
+	if err := h.validator.Struct(request); err != nil {
+		ResponseWithValidationError(w, http.StatusBadRequest, domain.GetValidationErrors(err.(validator.ValidationErrors)))
+		return
+	}
+	user, ok := UserFromContext(r.Context())
+	if !ok || user == nil {
+		ResponseWithCustomError(w, http.StatusUnauthorized, domain.DomainError{
+			Code:    domain.UserNotFoundError,
+			Message: "User not found in context",
+		})
+		return
+	}
+
+	area, err := h.service.UpdateServiceArea(r.Context(), id, user.ID, &request)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	ResponseWithJSON(w, http.StatusOK, area)
+}
+
+// HandleDeleteServiceArea deactivates a service area
+func (h *ServiceAreasHandler) HandleDeleteServiceArea(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if !utils.ValidateUUID(id) {
+		ResponseWithError(w, domain.NewDomainError(domain.InvalidInputError, "Invalid service area ID format", nil))
+		return
+	}
+	user, ok := UserFromContext(r.Context())
+	if !ok || user == nil {
+		ResponseWithCustomError(w, http.StatusUnauthorized, domain.DomainError{
+			Code:    domain.UserNotFoundError,
+			Message: "User not found in context",
+		})
+		return
+	}
+
+	if err := h.service.DeleteServiceArea(r.Context(), id, user.ID); err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -111,3 +111,27 @@ func GetPaginationParams(r *http.Request) (limit, offset int, err error) {
 
 	return limit, offset, nil
 }
+
+// SetETag exposes the row version of a resource as its ETag
+func SetETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", `"`+strconv.Itoa(version)+`"`)
+}
+
+// GetIfMatchVersion reads the version expected by an If-Match header.
+// It returns nil when the header is missing or is "*", so the update is unconditional.
+func GetIfMatchVersion(r *http.Request) (*int, error) {
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return nil, nil
+	}
+
+	// Versions are strong ETags, weak ones never match
+	if !strings.HasPrefix(ifMatch, `"`) || !strings.HasSuffix(ifMatch, `"`) || len(ifMatch) < 3 {
+		return nil, domain.ErrInvalidIfMatch
+	}
+	version, err := strconv.Atoi(ifMatch[1 : len(ifMatch)-1])
+	if err != nil || version < 1 {
+		return nil, domain.ErrInvalidIfMatch
+	}
+	return &version, nil
+}
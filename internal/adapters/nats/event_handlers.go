@@ -15,15 +15,21 @@ type EventHandlers struct {
 	logger ports.Logger
 
 	// Individual handlers
-	authEventsHandler *AuthEventsEventHandler
+	authEventsHandler   *AuthEventsEventHandler
+	ordersEventsHandler *OrdersEventsEventHandler
 }
 
 // NewEventHandlers creates a new event handlers manager
-func NewEventHandlers(dronesService ports.DronesService, logger ports.Logger) *EventHandlers {
-	return &EventHandlers{
+// dispatchService may be nil when automatic dispatch is disabled
+func NewEventHandlers(dronesService ports.DronesService, dispatchService ports.DispatchService, logger ports.Logger) *EventHandlers {
+	handlers := &EventHandlers{
 		logger:            logger,
 		authEventsHandler: NewAuthEventHandler(dronesService, logger),
 	}
+	if dispatchService != nil {
+		handlers.ordersEventsHandler = NewOrdersEventHandler(dispatchService, logger)
+	}
+	return handlers
 }
 
 // RegisterHandlers registers all event handlers with the consumer
@@ -33,6 +39,16 @@ func (h *EventHandlers) RegisterHandlers(consumer ports.EventConsumer) error {
 		return err
 	}
 
+	// Register order created and released event handlers for automatic dispatch
+	if h.ordersEventsHandler != nil {
+		if err := consumer.RegisterHandler(domain.EventTypeOrderCreated, h.ordersEventsHandler); err != nil {
+			return err
+		}
+		if err := consumer.RegisterHandler(domain.EventTypeOrderReleased, h.ordersEventsHandler); err != nil {
+			return err
+		}
+	}
+
 	h.logger.Info("All event handlers registered successfully")
 	return nil
 }
@@ -112,3 +128,64 @@ func (h *AuthEventsEventHandler) handleDroneLocationUpdated(ctx context.Context,
 
 	return nil
 }
+
+type OrdersEventsEventHandler struct {
+	dispatchService ports.DispatchService
+	logger          ports.Logger
+}
+
+// NewOrdersEventHandler creates a new orders event handler
+func NewOrdersEventHandler(dispatchService ports.DispatchService, logger ports.Logger) *OrdersEventsEventHandler {
+	return &OrdersEventsEventHandler{
+		dispatchService: dispatchService,
+		logger:          logger,
+	}
+}
+
+// Handle handles orders events
+func (h *OrdersEventsEventHandler) Handle(ctx context.Context, event domain.DomainEvent) error {
+	h.logger.Info("Handling orders event",
+		"event_type", string(event.Type),
+		"event_id", event.ID,
+		"aggregate_id", event.AggregateID)
+
+	switch event.Type {
+	case domain.EventTypeOrderCreated, domain.EventTypeOrderReleased:
+		return h.handleOrderReady(ctx, event)
+	default:
+		h.logger.Debug("Unhandled orders event type", "event_type", string(event.Type))
+		return nil
+	}
+}
+
+// handleOrderReady dispatches orders that just became pending, on creation or scheduled release.
+// Orders still scheduled are rejected by the dispatcher and left alone.
+func (h *OrdersEventsEventHandler) handleOrderReady(ctx context.Context, event domain.DomainEvent) error {
+	h.logger.Info("Processing order ready for dispatch event",
+		"event_type", string(event.Type),
+		"event_id", event.ID,
+		"order_id", event.AggregateID)
+
+	if _, err := h.dispatchService.DispatchOrder(ctx, event.AggregateID); err != nil {
+		// The periodic sweep picks up orders that could not be dispatched right away
+		if err == domain.ErrNoEligibleDrone || err == domain.ErrAlreadyReserved || err == domain.ErrReserveNotAllowed {
+			h.logger.Info("Order left for dispatch sweep",
+				"event_id", event.ID,
+				"order_id", event.AggregateID,
+				"reason", err.Error())
+			return nil
+		}
+		h.logger.Error("Failed to dispatch order",
+			"event_type", string(event.Type),
+			"event_id", event.ID,
+			"aggregate_id", event.AggregateID,
+			"error", err)
+		return err
+	}
+
+	h.logger.Info("Order ready for dispatch event processed successfully",
+		"event_id", event.ID,
+		"order_id", event.AggregateID)
+
+	return nil
+}
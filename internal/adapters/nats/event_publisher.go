@@ -128,6 +128,144 @@ func (p *EventPublisher) PublishOrderUpdated(ctx context.Context, event events.O
 	return p.publishEvent(ctx, p.config.Subjects.OrdersEvents, domainEvent)
 }
 
+func (p *EventPublisher) PublishOrderAssigned(ctx context.Context, event events.OrderAssignedEvent) error {
+	domainEvent := domain.DomainEvent{
+		ID:          generateEventID(),
+		Type:        domain.EventTypeOrderAssigned,
+		AggregateID: event.OrderID,
+		Version:     1,
+		Data:        eventToMap(event),
+		Metadata: domain.EventMetadata{
+			Source:        "drones",
+			CorrelationID: getCorrelationID(ctx),
+		},
+		Timestamp: time.Now(),
+	}
+
+	return p.publishEvent(ctx, p.config.Subjects.OrdersEvents, domainEvent)
+}
+
+func (p *EventPublisher) PublishOrderReleased(ctx context.Context, event events.OrderReleasedEvent) error {
+	domainEvent := domain.DomainEvent{
+		ID:          generateEventID(),
+		Type:        domain.EventTypeOrderReleased,
+		AggregateID: event.OrderID,
+		Version:     1,
+		Data:        eventToMap(event),
+		Metadata: domain.EventMetadata{
+			Source:        "drones",
+			CorrelationID: getCorrelationID(ctx),
+		},
+		Timestamp: time.Now(),
+	}
+
+	return p.publishEvent(ctx, p.config.Subjects.OrdersEvents, domainEvent)
+}
+
+func (p *EventPublisher) PublishOrderCancelled(ctx context.Context, event events.OrderCancelledEvent) error {
+	domainEvent := domain.DomainEvent{
+		ID:          generateEventID(),
+		Type:        domain.EventTypeOrderCancelled,
+		AggregateID: event.OrderID,
+		Version:     1,
+		Data:        eventToMap(event),
+		Metadata: domain.EventMetadata{
+			Source:        "drones",
+			CorrelationID: getCorrelationID(ctx),
+		},
+		Timestamp: time.Now(),
+	}
+
+	return p.publishEvent(ctx, p.config.Subjects.OrdersEvents, domainEvent)
+}
+
+func (p *EventPublisher) PublishSendOTP(ctx context.Context, event events.SendOTPEvent) error {
+	domainEvent := domain.DomainEvent{
+		ID:          generateEventID(),
+		Type:        domain.EventTypeSendOTP,
+		AggregateID: event.ID,
+		Version:     1,
+		Data:        eventToMap(event),
+		Metadata: domain.EventMetadata{
+			Source:        "drones",
+			CorrelationID: getCorrelationID(ctx),
+			UserID:        event.UserID,
+		},
+		Timestamp: time.Now(),
+	}
+
+	return p.publishEvent(ctx, p.config.Subjects.NotificationEvents, domainEvent)
+}
+
+func (p *EventPublisher) PublishOrderDeliveryFailed(ctx context.Context, event events.OrderDeliveryFailedEvent) error {
+	domainEvent := domain.DomainEvent{
+		ID:          generateEventID(),
+		Type:        domain.EventTypeOrderDeliveryFailed,
+		AggregateID: event.OrderID,
+		Version:     1,
+		Data:        eventToMap(event),
+		Metadata: domain.EventMetadata{
+			Source:        "drones",
+			CorrelationID: getCorrelationID(ctx),
+			UserID:        event.UserID,
+		},
+		Timestamp: time.Now(),
+	}
+
+	return p.publishEvent(ctx, p.config.Subjects.NotificationEvents, domainEvent)
+}
+
+func (p *EventPublisher) PublishDroneLost(ctx context.Context, event events.DroneLostEvent) error {
+	domainEvent := domain.DomainEvent{
+		ID:          generateEventID(),
+		Type:        domain.EventTypeDroneLost,
+		AggregateID: event.DroneID,
+		Version:     1,
+		Data:        eventToMap(event),
+		Metadata: domain.EventMetadata{
+			Source:        "drones",
+			CorrelationID: getCorrelationID(ctx),
+		},
+		Timestamp: time.Now(),
+	}
+
+	return p.publishEvent(ctx, p.config.Subjects.DronesEvents, domainEvent)
+}
+
+func (p *EventPublisher) PublishDroneRestored(ctx context.Context, event events.DroneRestoredEvent) error {
+	domainEvent := domain.DomainEvent{
+		ID:          generateEventID(),
+		Type:        domain.EventTypeDroneRestored,
+		AggregateID: event.DroneID,
+		Version:     1,
+		Data:        eventToMap(event),
+		Metadata: domain.EventMetadata{
+			Source:        "drones",
+			CorrelationID: getCorrelationID(ctx),
+		},
+		Timestamp: time.Now(),
+	}
+
+	return p.publishEvent(ctx, p.config.Subjects.DronesEvents, domainEvent)
+}
+
+func (p *EventPublisher) PublishGeofenceViolation(ctx context.Context, event events.GeofenceViolationEvent) error {
+	domainEvent := domain.DomainEvent{
+		ID:          generateEventID(),
+		Type:        domain.EventTypeGeofenceViolation,
+		AggregateID: event.DroneID,
+		Version:     1,
+		Data:        eventToMap(event),
+		Metadata: domain.EventMetadata{
+			Source:        "drones",
+			CorrelationID: getCorrelationID(ctx),
+		},
+		Timestamp: time.Now(),
+	}
+
+	return p.publishEvent(ctx, p.config.Subjects.DronesEvents, domainEvent)
+}
+
 // Close closes the NATS connection
 func (p *EventPublisher) Close() error {
 	if p.conn != nil {
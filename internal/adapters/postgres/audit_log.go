@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"drones/internal/core/domain"
+)
+
+// insertAuditLog records an audit entry inside the transaction that made the change
+func insertAuditLog(ctx context.Context, tx *sql.Tx, entry domain.CreateAuditLogRequest) error {
+	oldData, err := json.Marshal(entry.OldData)
+	if err != nil {
+		return err
+	}
+	newData, err := json.Marshal(entry.NewData)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO audit_logs (
+			resource_name, resource_id, action, old_data, new_data,
+			performed_by_id, performed_user_type, created_by_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $6)`,
+		entry.ResourceName,
+		entry.ResourceID,
+		entry.Action,
+		oldData,
+		newData,
+		nullIfEmpty(entry.PerformedBy),
+		nullIfEmpty(entry.UserType),
+	)
+	return err
+}
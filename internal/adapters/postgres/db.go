@@ -2,11 +2,14 @@ package postgres
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 
 	config "drones/configs"
+	"drones/internal/core/domain"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 func InitDB(cfg *config.DatabaseConfig) (*sql.DB, error) {
@@ -24,3 +27,75 @@ func InitDB(cfg *config.DatabaseConfig) (*sql.DB, error) {
 
 	return db, nil
 }
+
+// isUniqueViolation reports whether err violates the given unique index or constraint
+func isUniqueViolation(err error, constraint string) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == "23505" && pqErr.Constraint == constraint
+}
+
+// isForeignKeyViolation reports whether err references a missing row through the given constraint
+func isForeignKeyViolation(err error, constraint string) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == "23503" && pqErr.Constraint == constraint
+}
+
+// capabilitiesArray reads and writes drone capabilities as a VARCHAR[] column.
+// A nil pointer is written as NULL so it can be used with COALESCE in partial updates,
+// an empty list is written as an empty array.
+type capabilitiesArray struct {
+	capabilities *[]domain.DroneCapability
+}
+
+func (a capabilitiesArray) Scan(src interface{}) error {
+	var values pq.StringArray
+	if err := values.Scan(src); err != nil {
+		return err
+	}
+	capabilities := make([]domain.DroneCapability, 0, len(values))
+	for _, value := range values {
+		capabilities = append(capabilities, domain.DroneCapability(value))
+	}
+	*a.capabilities = capabilities
+	return nil
+}
+
+func (a capabilitiesArray) Value() (driver.Value, error) {
+	if a.capabilities == nil {
+		return nil, nil
+	}
+	values := make(pq.StringArray, 0, len(*a.capabilities))
+	for _, capability := range *a.capabilities {
+		values = append(values, string(capability))
+	}
+	return values.Value()
+}
+
+// geoPointsJSON reads and writes polygon vertices as a JSONB column, NULL when there are none.
+type geoPointsJSON struct {
+	points *[]domain.GeoPoint
+}
+
+func (p geoPointsJSON) Scan(src interface{}) error {
+	if src == nil {
+		*p.points = nil
+		return nil
+	}
+	data, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("unsupported type %T for geofence points", src)
+	}
+	return json.Unmarshal(data, p.points)
+}
+
+func (p geoPointsJSON) Value() (driver.Value, error) {
+	if p.points == nil || len(*p.points) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(*p.points)
+	if err != nil {
+		return nil, err
+	}
+	// As text, lib/pq would send []byte as bytea
+	return string(data), nil
+}
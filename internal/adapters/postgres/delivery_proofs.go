@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"drones/internal/core/domain"
+)
+
+// insertDeliveryCode stores the hashed one-time code of a new order
+func insertDeliveryCode(ctx context.Context, tx *sql.Tx, orderID string, code *domain.DeliveryCode, actorID string) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO delivery_proofs (order_id, code_hash, code_salt, created_by_id)
+		VALUES ($1, $2, $3, $4)`,
+		orderID, code.Hash, code.Salt, actorID)
+	return err
+}
+
+// saveDeliveryProof records the drone fix and photo with the delivered transition
+func saveDeliveryProof(ctx context.Context, tx *sql.Tx, orderID string, proof *domain.DeliveryProofUpdate, actorID string) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO delivery_proofs (
+			order_id, drone_id, lat, lon, distance_m, photo_key, photo_content_type,
+			verified_at, created_by_id, updated_by_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), $8, $8)
+		ON CONFLICT (order_id) DO UPDATE SET
+			drone_id = EXCLUDED.drone_id,
+			lat = EXCLUDED.lat,
+			lon = EXCLUDED.lon,
+			distance_m = EXCLUDED.distance_m,
+			photo_key = EXCLUDED.photo_key,
+			photo_content_type = EXCLUDED.photo_content_type,
+			verified_at = EXCLUDED.verified_at,
+			updated_by_id = EXCLUDED.updated_by_id,
+			updated_at = NOW()`,
+		orderID, nullIfEmpty(proof.DroneID), proof.Lat, proof.Lon, proof.DistanceM,
+		proof.PhotoKey, proof.PhotoContentType, nullIfEmpty(actorID))
+	return err
+}
+
+// GetDeliveryProof retrieves the delivery proof of an order
+func (r *OrdersRepositoryImpl) GetDeliveryProof(ctx context.Context, orderID string) (*domain.DeliveryProof, error) {
+	var proof domain.DeliveryProof
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			id, order_id, code_hash, code_salt, failed_attempts, drone_id,
+			lat, lon, distance_m, photo_key, photo_content_type, verified_at,
+			created_at, updated_at
+		FROM delivery_proofs
+		WHERE order_id = $1 AND active = TRUE`, orderID).Scan(
+		&proof.ID,
+		&proof.OrderID,
+		&proof.CodeHash,
+		&proof.CodeSalt,
+		&proof.FailedAttempts,
+		&proof.DroneID,
+		&proof.Lat,
+		&proof.Lon,
+		&proof.DistanceM,
+		&proof.PhotoKey,
+		&proof.PhotoContentType,
+		&proof.VerifiedAt,
+		&proof.CreatedAt,
+		&proof.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrDeliveryProofNotFound
+		}
+		r.logger.Error("Failed to get delivery proof", "orderID", orderID, "error", err)
+		return nil, err
+	}
+
+	return &proof, nil
+}
+
+// CheckDeliveryCode compares a code hash with the stored one and counts a mismatch in the same
+// statement, so concurrent guesses cannot get past maxAttempts. Once the attempts are used up,
+// or without a code to compare, nothing is updated and the code is reported locked.
+func (r *OrdersRepositoryImpl) CheckDeliveryCode(ctx context.Context, orderID string, droneID string, codeHash string, maxAttempts int) (bool, int, error) {
+	var matched bool
+	var attempts int
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE delivery_proofs SET
+			failed_attempts = failed_attempts + CASE WHEN code_hash = $3 THEN 0 ELSE 1 END,
+			drone_id = $2,
+			updated_at = NOW()
+		WHERE order_id = $1 AND active = TRUE AND code_hash IS NOT NULL AND failed_attempts < $4
+		RETURNING code_hash = $3, failed_attempts`,
+		orderID, nullIfEmpty(droneID), codeHash, maxAttempts).Scan(&matched, &attempts)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, 0, domain.ErrDeliveryCodeLocked
+		}
+		r.logger.Error("Failed to check delivery code", "orderID", orderID, "error", err)
+		return false, 0, err
+	}
+
+	return matched, attempts, nil
+}
@@ -0,0 +1,186 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"drones/internal/core/domain"
+	"drones/internal/ports"
+
+	"github.com/lib/pq"
+)
+
+type DroneCommandsRepository struct {
+	db     *sql.DB
+	logger ports.Logger
+}
+
+func NewDroneCommandsRepository(db *sql.DB, logger ports.Logger) ports.DroneCommandsRepository {
+	return &DroneCommandsRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const droneCommandColumns = `
+	id, drone_id, order_id, command, status, lat, lon, note,
+	acknowledged_at, created_at, created_by_id`
+
+func scanDroneCommand(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*domain.DroneCommand, error) {
+	var command domain.DroneCommand
+	err := scanner.Scan(
+		&command.ID,
+		&command.DroneID,
+		&command.OrderID,
+		&command.Command,
+		&command.Status,
+		&command.Lat,
+		&command.Lon,
+		&command.Note,
+		&command.AcknowledgedAt,
+		&command.CreatedAt,
+		&command.CreatedByID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &command, nil
+}
+
+// insertDroneCommand queues a command for a drone inside the transaction that caused it
+func insertDroneCommand(ctx context.Context, tx *sql.Tx, command domain.DroneCommand) (*domain.DroneCommand, error) {
+	return scanDroneCommand(tx.QueryRowContext(ctx, `
+		INSERT INTO drone_commands (
+			drone_id, order_id, command, status, lat, lon, note, created_by_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING`+droneCommandColumns,
+		command.DroneID,
+		command.OrderID,
+		command.Command,
+		domain.DroneCommandStatusPending,
+		command.Lat,
+		command.Lon,
+		command.Note,
+		command.CreatedByID,
+	))
+}
+
+// QueueCommand queues a command for a drone, delivered on its next heartbeat
+func (r *DroneCommandsRepository) QueueCommand(ctx context.Context, command domain.DroneCommand) (*domain.DroneCommand, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	created, err := insertDroneCommand(ctx, tx, command)
+	if err != nil {
+		if isForeignKeyViolation(err, "drone_commands_drone_id_fkey") {
+			return nil, domain.ErrDroneNotFound
+		}
+		if isForeignKeyViolation(err, "drone_commands_order_id_fkey") {
+			return nil, domain.ErrOrderNotFound
+		}
+		r.logger.Error("Failed to queue drone command", "droneID", command.DroneID, "error", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", "error", err)
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// ListCommands retrieves the commands of a drone, newest first
+func (r *DroneCommandsRepository) ListCommands(ctx context.Context, droneID string, filter domain.DroneCommandFilter) ([]*domain.DroneCommand, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT`+droneCommandColumns+`
+		FROM drone_commands
+		WHERE drone_id = $1 AND active = TRUE
+		AND ($2::VARCHAR IS NULL OR status = $2)
+		ORDER BY created_at DESC
+		LIMIT $3`,
+		droneID,
+		filter.Status,
+		filter.Limit,
+	)
+	if err != nil {
+		r.logger.Error("Failed to list drone commands", "droneID", droneID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanCommands(rows, droneID)
+}
+
+// ListPendingCommands retrieves the commands a drone has not acknowledged yet, oldest first
+func (r *DroneCommandsRepository) ListPendingCommands(ctx context.Context, droneID string) ([]*domain.DroneCommand, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT`+droneCommandColumns+`
+		FROM drone_commands
+		WHERE drone_id = $1 AND status = $2 AND active = TRUE
+		ORDER BY created_at ASC`,
+		droneID,
+		domain.DroneCommandStatusPending,
+	)
+	if err != nil {
+		r.logger.Error("Failed to list pending drone commands", "droneID", droneID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanCommands(rows, droneID)
+}
+
+// AcknowledgeCommands marks pending commands of a drone as carried out. IDs of other
+// drones or of commands already acknowledged are ignored.
+func (r *DroneCommandsRepository) AcknowledgeCommands(ctx context.Context, droneID string, userID string, commandIDs []string) ([]*domain.DroneCommand, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE drone_commands SET
+			status = $3,
+			acknowledged_at = NOW(),
+			updated_by_id = $5,
+			updated_at = NOW()
+		WHERE drone_id = $1
+		AND id = ANY($2::UUID[])
+		AND status = $4
+		AND active = TRUE
+		RETURNING`+droneCommandColumns,
+		droneID,
+		pq.Array(commandIDs),
+		domain.DroneCommandStatusAcknowledged,
+		domain.DroneCommandStatusPending,
+		nullIfEmpty(userID),
+	)
+	if err != nil {
+		r.logger.Error("Failed to acknowledge drone commands", "droneID", droneID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanCommands(rows, droneID)
+}
+
+func (r *DroneCommandsRepository) scanCommands(rows *sql.Rows, droneID string) ([]*domain.DroneCommand, error) {
+	var commands []*domain.DroneCommand
+	for rows.Next() {
+		command, err := scanDroneCommand(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan drone command", "droneID", droneID, "error", err)
+			return nil, err
+		}
+		commands = append(commands, command)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Failed to iterate drone commands", "droneID", droneID, "error", err)
+		return nil, err
+	}
+
+	return commands, nil
+}
@@ -0,0 +1,170 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"drones/internal/core/domain"
+	"drones/internal/ports"
+)
+
+type DroneTelemetryRepository struct {
+	db     *sql.DB
+	logger ports.Logger
+}
+
+func NewDroneTelemetryRepository(db *sql.DB, logger ports.Logger) ports.DroneTelemetryRepository {
+	return &DroneTelemetryRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const droneTelemetryColumns = `
+	drone_id, order_id, status, lat, lon, altitude, battery_level_percent,
+	flight_seconds, recorded_at`
+
+func scanDroneTelemetry(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*domain.DroneTelemetry, error) {
+	var telemetry domain.DroneTelemetry
+	err := scanner.Scan(
+		&telemetry.DroneID,
+		&telemetry.OrderID,
+		&telemetry.Status,
+		&telemetry.Lat,
+		&telemetry.Lon,
+		&telemetry.Altitude,
+		&telemetry.BatteryLevelPercent,
+		&telemetry.FlightSeconds,
+		&telemetry.RecordedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &telemetry, nil
+}
+
+// insertDroneTelemetry records a heartbeat inside the transaction that applied it
+func insertDroneTelemetry(ctx context.Context, tx *sql.Tx, telemetry domain.DroneTelemetry) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO drone_telemetry (
+			drone_id, order_id, status, lat, lon, altitude, battery_level_percent, flight_seconds
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		telemetry.DroneID,
+		telemetry.OrderID,
+		telemetry.Status,
+		telemetry.Lat,
+		telemetry.Lon,
+		telemetry.Altitude,
+		telemetry.BatteryLevelPercent,
+		telemetry.FlightSeconds,
+	)
+	return err
+}
+
+// ListTelemetry retrieves the telemetry of a drone in a time range, oldest first. Heartbeats are
+// grouped in buckets of the query interval and the first heartbeat of each bucket is returned.
+func (r *DroneTelemetryRepository) ListTelemetry(ctx context.Context, droneID string, query domain.DroneTelemetryQuery) ([]*domain.DroneTelemetry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT DISTINCT ON (bucket)`+droneTelemetryColumns+`
+		FROM (
+			SELECT *, FLOOR(EXTRACT(EPOCH FROM recorded_at) / $4) AS bucket
+			FROM drone_telemetry
+			WHERE drone_id = $1
+			AND recorded_at >= $2 AND recorded_at < $3
+		) telemetry
+		ORDER BY bucket, recorded_at`,
+		droneID,
+		query.From,
+		query.To,
+		query.Interval.Seconds(),
+	)
+	if err != nil {
+		r.logger.Error("Failed to list drone telemetry", "droneID", droneID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanTelemetry(rows)
+}
+
+// ListOrderTelemetry retrieves every heartbeat recorded while a drone had the order on board, oldest first
+func (r *DroneTelemetryRepository) ListOrderTelemetry(ctx context.Context, orderID string) ([]*domain.DroneTelemetry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT`+droneTelemetryColumns+`
+		FROM drone_telemetry
+		WHERE order_id = $1
+		ORDER BY recorded_at`,
+		orderID,
+	)
+	if err != nil {
+		r.logger.Error("Failed to list order telemetry", "orderID", orderID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanTelemetry(rows)
+}
+
+// SumFlightSeconds returns the flight time credited to a drone by the heartbeats of a time range
+func (r *DroneTelemetryRepository) SumFlightSeconds(ctx context.Context, droneID string, from, to time.Time) (float64, error) {
+	var seconds float64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(flight_seconds), 0)
+		FROM drone_telemetry
+		WHERE drone_id = $1
+		AND recorded_at >= $2 AND recorded_at < $3`,
+		droneID,
+		from,
+		to,
+	).Scan(&seconds)
+	if err != nil {
+		r.logger.Error("Failed to sum drone flight time", "droneID", droneID, "error", err)
+		return 0, err
+	}
+
+	return seconds, nil
+}
+
+// PruneTelemetry deletes up to limit heartbeats recorded before the cutoff, returns how many were deleted
+func (r *DroneTelemetryRepository) PruneTelemetry(ctx context.Context, recordedBefore time.Time, limit int) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM drone_telemetry
+		WHERE id IN (
+			SELECT id
+			FROM drone_telemetry
+			WHERE recorded_at < $1
+			ORDER BY recorded_at
+			LIMIT $2
+		)`,
+		recordedBefore,
+		limit,
+	)
+	if err != nil {
+		r.logger.Error("Failed to prune drone telemetry", "error", err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *DroneTelemetryRepository) scanTelemetry(rows *sql.Rows) ([]*domain.DroneTelemetry, error) {
+	var points []*domain.DroneTelemetry
+	for rows.Next() {
+		point, err := scanDroneTelemetry(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan drone telemetry", "error", err)
+			return nil, err
+		}
+		points = append(points, point)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Failed to iterate drone telemetry", "error", err)
+		return nil, err
+	}
+
+	return points, nil
+}
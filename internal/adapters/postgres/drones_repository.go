@@ -5,9 +5,12 @@ import (
 	"database/sql"
 	"fmt"
 	"math"
+	"time"
 
 	"drones/internal/core/domain"
 	"drones/internal/ports"
+
+	"github.com/lib/pq"
 )
 
 type DronesRepository struct {
@@ -44,7 +47,7 @@ func (r *DronesRepository) prepareStatements() error {
 			max_weight_kg, max_speed_kmh, max_range_km, battery_capacity_mah,
 			status, battery_level_percent, current_lat, current_lon, current_altitude,
 			last_location_update_at, total_flight_hours, total_deliveries,
-			last_maintenance_at, next_maintenance_due_at,
+			last_maintenance_at, next_maintenance_due_at, capabilities, version,
 			created_at, updated_at, active, created_by_id, updated_by_id
 		FROM drones
 		WHERE id = $1 AND active = TRUE`)
@@ -54,14 +57,14 @@ func (r *DronesRepository) prepareStatements() error {
 
 	r.createStmt, err = r.db.Prepare(`
 		INSERT INTO drones (
-			user_id, model, serial_number, manufacturer, battery_capacity_mah, max_weight_kg, created_by_id
-		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+			user_id, model, serial_number, manufacturer, battery_capacity_mah, max_weight_kg, created_by_id, capabilities
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING
 			id, drone_identifier, user_id, model, serial_number, manufacturer,
 			max_weight_kg, max_speed_kmh, max_range_km, battery_capacity_mah,
 			status, battery_level_percent, current_lat, current_lon, current_altitude,
 			last_location_update_at, total_flight_hours, total_deliveries,
-			last_maintenance_at, next_maintenance_due_at,
+			last_maintenance_at, next_maintenance_due_at, capabilities, version,
 			created_at, updated_at, active, created_by_id, updated_by_id`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare createStmt: %w", err)
@@ -74,7 +77,7 @@ func (r *DronesRepository) prepareStatements() error {
 			max_weight_kg, max_speed_kmh, max_range_km, battery_capacity_mah,
 			status, battery_level_percent, current_lat, current_lon, current_altitude,
 			last_location_update_at, total_flight_hours, total_deliveries,
-			last_maintenance_at, next_maintenance_due_at,
+			last_maintenance_at, next_maintenance_due_at, capabilities, version,
 			created_at, updated_at, active, created_by_id, updated_by_id
 		FROM drones
 		WHERE drone_identifier = $1 AND active = TRUE`)
@@ -89,7 +92,7 @@ func (r *DronesRepository) prepareStatements() error {
 			max_weight_kg, max_speed_kmh, max_range_km, battery_capacity_mah,
 			status, battery_level_percent, current_lat, current_lon, current_altitude,
 			last_location_update_at, total_flight_hours, total_deliveries,
-			last_maintenance_at, next_maintenance_due_at,
+			last_maintenance_at, next_maintenance_due_at, capabilities, version,
 			created_at, updated_at, active, created_by_id, updated_by_id
 		FROM drones
 		WHERE user_id = $1 AND active = TRUE
@@ -113,14 +116,15 @@ func (r *DronesRepository) prepareStatements() error {
 			last_maintenance_at = COALESCE($11, last_maintenance_at),
 			next_maintenance_due_at = COALESCE($12, next_maintenance_due_at),
 			updated_by_id = COALESCE($13, updated_by_id),
+			capabilities = COALESCE($14, capabilities),
 			updated_at = NOW()
-		WHERE id = $1 AND active = TRUE
+		WHERE id = $1 AND active = TRUE AND ($15::INTEGER IS NULL OR version = $15)
 		RETURNING
 			id, drone_identifier, user_id, model, serial_number, manufacturer,
 			max_weight_kg, max_speed_kmh, max_range_km, battery_capacity_mah,
 			status, battery_level_percent, current_lat, current_lon, current_altitude,
 			last_location_update_at, total_flight_hours, total_deliveries,
-			last_maintenance_at, next_maintenance_due_at,
+			last_maintenance_at, next_maintenance_due_at, capabilities, version,
 			created_at, updated_at, active, created_by_id, updated_by_id`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare updateStmt: %w", err)
@@ -199,6 +203,8 @@ func (r *DronesRepository) scanDrone(scanner interface {
 		&drone.TotalDeliveries,
 		&drone.LastMaintenanceAt,
 		&drone.NextMaintenanceDueAt,
+		capabilitiesArray{&drone.Capabilities},
+		&drone.Version,
 		&drone.CreatedAt,
 		&drone.UpdatedAt,
 		&drone.Active,
@@ -226,18 +232,19 @@ func (r *DronesRepository) CreateDrone(ctx context.Context, drone *domain.Create
 			drone.BatteryCapacity,
 			drone.PayloadCapacity,
 			drone.CreatedByID,
+			capabilitiesArray{&drone.Capabilities},
 		))
 	} else {
 		query := `
 			INSERT INTO drones (
-				user_id, model, serial_number, manufacturer, battery_capacity_mah, max_weight_kg, created_by_id
-			) VALUES ($1, $2, $3, $4, $5, $6, $7)
+				user_id, model, serial_number, manufacturer, battery_capacity_mah, max_weight_kg, created_by_id, capabilities
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 			RETURNING
 				id, drone_identifier, user_id, model, serial_number, manufacturer,
 				max_weight_kg, max_speed_kmh, max_range_km, battery_capacity_mah,
 				status, battery_level_percent, current_lat, current_lon, current_altitude,
 				last_location_update_at, total_flight_hours, total_deliveries,
-				last_maintenance_at, next_maintenance_due_at,
+				last_maintenance_at, next_maintenance_due_at, capabilities, version,
 				created_at, updated_at, active, created_by_id, updated_by_id`
 		newDrone, err = r.scanDrone(r.db.QueryRowContext(
 			ctx,
@@ -249,6 +256,7 @@ func (r *DronesRepository) CreateDrone(ctx context.Context, drone *domain.Create
 			drone.BatteryCapacity,
 			drone.PayloadCapacity,
 			drone.CreatedByID,
+			capabilitiesArray{&drone.Capabilities},
 		))
 	}
 
@@ -281,6 +289,8 @@ func (r *DronesRepository) UpdateDrone(ctx context.Context, droneID string, req
 			req.LastMaintenanceAt,
 			req.NextMaintenanceAt,
 			req.UpdatedByID,
+			capabilitiesArray{req.Capabilities},
+			req.ExpectedVersion,
 		))
 	} else {
 		query := `
@@ -297,14 +307,15 @@ func (r *DronesRepository) UpdateDrone(ctx context.Context, droneID string, req
 				last_maintenance_at = COALESCE($11, last_maintenance_at),
 				next_maintenance_due_at = COALESCE($12, next_maintenance_due_at),
 				updated_by_id = COALESCE($13, updated_by_id),
+				capabilities = COALESCE($14, capabilities),
 				updated_at = NOW()
-			WHERE id = $1 AND active = TRUE
+			WHERE id = $1 AND active = TRUE AND ($15::INTEGER IS NULL OR version = $15)
 			RETURNING
 				id, drone_identifier, user_id, model, serial_number, manufacturer,
 				max_weight_kg, max_speed_kmh, max_range_km, battery_capacity_mah,
 				status, battery_level_percent, current_lat, current_lon, current_altitude,
 				last_location_update_at, total_flight_hours, total_deliveries,
-				last_maintenance_at, next_maintenance_due_at,
+				last_maintenance_at, next_maintenance_due_at, capabilities, version,
 				created_at, updated_at, active, created_by_id, updated_by_id`
 		updatedDrone, err = r.scanDrone(r.db.QueryRowContext(
 			ctx,
@@ -322,11 +333,17 @@ func (r *DronesRepository) UpdateDrone(ctx context.Context, droneID string, req
 			req.LastMaintenanceAt,
 			req.NextMaintenanceAt,
 			req.UpdatedByID,
+			capabilitiesArray{req.Capabilities},
+			req.ExpectedVersion,
 		))
 	}
 
 	if err != nil {
 		if err == sql.ErrNoRows {
+			if req.ExpectedVersion != nil && r.droneExists(ctx, droneID) {
+				r.logger.Warn("Drone version moved on before update", "droneID", droneID, "expectedVersion", *req.ExpectedVersion)
+				return nil, domain.ErrDroneVersionConflict
+			}
 			return nil, domain.ErrDroneNotFound
 		}
 		r.logger.Error("Failed to update drone", "droneID", droneID, "error", err)
@@ -336,6 +353,19 @@ func (r *DronesRepository) UpdateDrone(ctx context.Context, droneID string, req
 	return updatedDrone, nil
 }
 
+// droneExists reports whether an active drone exists, used to tell a missing drone
+// from a version conflict when a conditional update matched no row
+func (r *DronesRepository) droneExists(ctx context.Context, droneID string) bool {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM drones WHERE id = $1 AND active = TRUE)`, droneID).Scan(&exists)
+	if err != nil {
+		r.logger.Error("Failed to check drone existence", "droneID", droneID, "error", err)
+		return false
+	}
+	return exists
+}
+
 // applyDroneFilters applies drone filters to a query and returns the updated query string and arguments
 func (r *DronesRepository) applyDroneFilters(baseQuery string, filter *domain.DroneFilter, startParamCount int) (string, []interface{}, int) {
 	query := baseQuery
@@ -431,7 +461,7 @@ func (r *DronesRepository) GetDroneByID(ctx context.Context, droneID string) (*d
 				max_weight_kg, max_speed_kmh, max_range_km, battery_capacity_mah,
 				status, battery_level_percent, current_lat, current_lon, current_altitude,
 				last_location_update_at, total_flight_hours, total_deliveries,
-				last_maintenance_at, next_maintenance_due_at,
+				last_maintenance_at, next_maintenance_due_at, capabilities, version,
 				created_at, updated_at, active, created_by_id, updated_by_id
 			FROM drones
 			WHERE id = $1 AND active = TRUE`
@@ -462,7 +492,7 @@ func (r *DronesRepository) GetDroneByIdentifier(ctx context.Context, identifier
 				max_weight_kg, max_speed_kmh, max_range_km, battery_capacity_mah,
 				status, battery_level_percent, current_lat, current_lon, current_altitude,
 				last_location_update_at, total_flight_hours, total_deliveries,
-				last_maintenance_at, next_maintenance_due_at,
+				last_maintenance_at, next_maintenance_due_at, capabilities, version,
 				created_at, updated_at, active, created_by_id, updated_by_id
 			FROM drones
 			WHERE drone_identifier = $1 AND active = TRUE`
@@ -493,7 +523,7 @@ func (r *DronesRepository) GetDronesByUserID(ctx context.Context, userID string)
 				max_weight_kg, max_speed_kmh, max_range_km, battery_capacity_mah,
 				status, battery_level_percent, current_lat, current_lon, current_altitude,
 				last_location_update_at, total_flight_hours, total_deliveries,
-				last_maintenance_at, next_maintenance_due_at,
+				last_maintenance_at, next_maintenance_due_at, capabilities, version,
 				created_at, updated_at, active, created_by_id, updated_by_id
 			FROM drones
 			WHERE user_id = $1 AND active = TRUE
@@ -532,28 +562,40 @@ func (r *DronesRepository) NearbyDrones(ctx context.Context, lat, lon, radiusKm
 	maxLon := lon + lonDelta
 
 	// Query with Haversine distance calculation
+	// Distance is computed in a subquery so it can be filtered on (Postgres has no HAVING without GROUP BY)
+	// LEAST clamps the acos argument to avoid NaN from floating point error on identical points
 	query := `
 		SELECT
 			id, drone_identifier, user_id, model, serial_number, manufacturer,
 			max_weight_kg, max_speed_kmh, max_range_km, battery_capacity_mah,
 			status, battery_level_percent, current_lat, current_lon, current_altitude,
 			last_location_update_at, total_flight_hours, total_deliveries,
-			last_maintenance_at, next_maintenance_due_at,
+			last_maintenance_at, next_maintenance_due_at, capabilities, version,
 			created_at, updated_at, active, created_by_id, updated_by_id,
-			(
-				6371 * acos(
-					cos(radians($1)) * cos(radians(current_lat)) *
-					cos(radians(current_lon) - radians($2)) +
-					sin(radians($1)) * sin(radians(current_lat))
-				)
-			) AS distance
-		FROM drones
-		WHERE active = TRUE
-			AND current_lat IS NOT NULL
-			AND current_lon IS NOT NULL
-			AND current_lat BETWEEN $3 AND $4
-			AND current_lon BETWEEN $5 AND $6
-		HAVING distance <= $7
+			distance
+		FROM (
+			SELECT
+				id, drone_identifier, user_id, model, serial_number, manufacturer,
+				max_weight_kg, max_speed_kmh, max_range_km, battery_capacity_mah,
+				status, battery_level_percent, current_lat, current_lon, current_altitude,
+				last_location_update_at, total_flight_hours, total_deliveries,
+				last_maintenance_at, next_maintenance_due_at, capabilities, version,
+				created_at, updated_at, active, created_by_id, updated_by_id,
+				(
+					6371 * acos(LEAST(1.0,
+						cos(radians($1)) * cos(radians(current_lat)) *
+						cos(radians(current_lon) - radians($2)) +
+						sin(radians($1)) * sin(radians(current_lat))
+					))
+				) AS distance
+			FROM drones
+			WHERE active = TRUE
+				AND current_lat IS NOT NULL
+				AND current_lon IS NOT NULL
+				AND current_lat BETWEEN $3 AND $4
+				AND current_lon BETWEEN $5 AND $6
+		) AS nearby
+		WHERE distance <= $7
 		ORDER BY distance ASC`
 
 	rows, err := r.db.QueryContext(ctx, query, lat, lon, minLat, maxLat, minLon, maxLon, radiusKm)
@@ -587,6 +629,8 @@ func (r *DronesRepository) NearbyDrones(ctx context.Context, lat, lon, radiusKm
 			&drone.TotalDeliveries,
 			&drone.LastMaintenanceAt,
 			&drone.NextMaintenanceDueAt,
+			capabilitiesArray{&drone.Capabilities},
+			&drone.Version,
 			&drone.CreatedAt,
 			&drone.UpdatedAt,
 			&drone.Active,
@@ -625,7 +669,7 @@ func (r *DronesRepository) ListDrones(ctx context.Context, options domain.Pagina
 			max_weight_kg, max_speed_kmh, max_range_km, battery_capacity_mah,
 			status, battery_level_percent, current_lat, current_lon, current_altitude,
 			last_location_update_at, total_flight_hours, total_deliveries,
-			last_maintenance_at, next_maintenance_due_at,
+			last_maintenance_at, next_maintenance_due_at, capabilities, version,
 			created_at, updated_at, active, created_by_id, updated_by_id
 		FROM drones
 		WHERE active = TRUE`, filter, 0)
@@ -678,7 +722,7 @@ func (r *DronesRepository) GetDroneByFilter(ctx context.Context, filter domain.D
 			max_weight_kg, max_speed_kmh, max_range_km, battery_capacity_mah,
 			status, battery_level_percent, current_lat, current_lon, current_altitude,
 			last_location_update_at, total_flight_hours, total_deliveries,
-			last_maintenance_at, next_maintenance_due_at,
+			last_maintenance_at, next_maintenance_due_at, capabilities, version,
 			created_at, updated_at, active, created_by_id, updated_by_id
 		FROM drones
 		WHERE active = TRUE`
@@ -697,13 +741,13 @@ func (r *DronesRepository) GetDroneByFilter(ctx context.Context, filter domain.D
 	return drone, nil
 }
 
-// UpdateStatusBroken updates drone status and if status is broken, updates associated active orders to drone_failed
-func (r *DronesRepository) UpdateStatusBroken(ctx context.Context, userID, droneID string, status domain.DroneStatus) (*domain.Drone, error) {
+// UpdateStatusBroken updates drone status and if status is broken, hands off its active orders and returns them
+func (r *DronesRepository) UpdateStatusBroken(ctx context.Context, userID, droneID string, status domain.DroneStatus) (*domain.Drone, []domain.HandedOffOrder, error) {
 	// Begin transaction
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		r.logger.Error("Failed to begin transaction", "error", err)
-		return nil, err
+		return nil, nil, err
 	}
 	defer tx.Rollback()
 
@@ -720,7 +764,7 @@ func (r *DronesRepository) UpdateStatusBroken(ctx context.Context, userID, drone
 			max_weight_kg, max_speed_kmh, max_range_km, battery_capacity_mah,
 			status, battery_level_percent, current_lat, current_lon, current_altitude,
 			last_location_update_at, total_flight_hours, total_deliveries,
-			last_maintenance_at, next_maintenance_due_at,
+			last_maintenance_at, next_maintenance_due_at, capabilities, version,
 			created_at, updated_at, active, created_by_id, updated_by_id`,
 		droneID, status, userID).Scan(
 		&updatedDrone.ID,
@@ -743,6 +787,8 @@ func (r *DronesRepository) UpdateStatusBroken(ctx context.Context, userID, drone
 		&updatedDrone.TotalDeliveries,
 		&updatedDrone.LastMaintenanceAt,
 		&updatedDrone.NextMaintenanceDueAt,
+		capabilitiesArray{&updatedDrone.Capabilities},
+		&updatedDrone.Version,
 		&updatedDrone.CreatedAt,
 		&updatedDrone.UpdatedAt,
 		&updatedDrone.Active,
@@ -752,44 +798,204 @@ func (r *DronesRepository) UpdateStatusBroken(ctx context.Context, userID, drone
 	if err != nil {
 
 		if err == sql.ErrNoRows {
-			return nil, domain.ErrDroneNotFound
+			return nil, nil, domain.ErrDroneNotFound
 		}
 		r.logger.Error("Failed to update drone status", "droneID", droneID, "error", err)
+		return nil, nil, err
+	}
+
+	// If status is broken, hand off associated active orders and record each transition
+	var handedOff []domain.HandedOffOrder
+	if status == domain.DroneStatusBroken {
+		handedOff, err = handoffDroneOrders(ctx, tx, droneID, nullIfEmpty(userID), domain.OrderStatusReasonDroneBroken)
+		if err != nil {
+			r.logger.Error("Failed to update orders for broken drone", "droneID", droneID, "error", err)
+			return nil, nil, err
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+
+		r.logger.Error("Failed to commit transaction", "error", err)
+		return nil, nil, err
+	}
+
+	return &updatedDrone, handedOff, nil
+}
+
+// RestoreLostDrone moves a lost drone back to idle. The status is checked in the update, so a drone
+// an admin or the watchdog moved on in the meantime is left alone.
+func (r *DronesRepository) RestoreLostDrone(ctx context.Context, userID, droneID string) (*domain.Drone, error) {
+	drone, err := r.scanDrone(r.db.QueryRowContext(ctx, `
+		UPDATE drones SET
+			status = $2,
+			updated_by_id = $3,
+			updated_at = NOW()
+		WHERE id = $1 AND status = $4 AND active = TRUE
+		RETURNING
+			id, drone_identifier, user_id, model, serial_number, manufacturer,
+			max_weight_kg, max_speed_kmh, max_range_km, battery_capacity_mah,
+			status, battery_level_percent, current_lat, current_lon, current_altitude,
+			last_location_update_at, total_flight_hours, total_deliveries,
+			last_maintenance_at, next_maintenance_due_at, capabilities, version,
+			created_at, updated_at, active, created_by_id, updated_by_id`,
+		droneID, domain.DroneStatusIdle, nullIfEmpty(userID), domain.DroneStatusLost))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrDroneNotLost
+		}
+		r.logger.Error("Failed to restore lost drone", "droneID", droneID, "error", err)
 		return nil, err
 	}
 
-	// If status is broken, update associated active orders to drone_failed
-	if status == "broken" {
-		_, err = tx.ExecContext(ctx, `
-			UPDATE orders SET
-				status = 'handoff',
+	return drone, nil
+}
+
+// handoffDroneOrders hands off the active orders of a drone that can no longer fly them and
+// records each transition. Packages already on board wait for a rescue drone at the drone's
+// last position. The drone no longer carries anything, even once it flies again.
+func handoffDroneOrders(ctx context.Context, tx *sql.Tx, droneID string, actorID *string, reason string) ([]domain.HandedOffOrder, error) {
+	rows, err := tx.QueryContext(ctx, `
+		WITH previous AS (
+			SELECT id, status FROM orders
+			WHERE drone_id = $1
+			AND status = ANY($4::VARCHAR[])
+			AND active = TRUE
+			FOR UPDATE
+		), moved AS (
+			UPDATE orders o SET
+				status = $3,
+				current_lat = CASE WHEN previous.status = ANY($6::VARCHAR[]) THEN COALESCE(d.current_lat, o.current_lat) ELSE o.current_lat END,
+				current_lon = CASE WHEN previous.status = ANY($6::VARCHAR[]) THEN COALESCE(d.current_lon, o.current_lon) ELSE o.current_lon END,
+				current_altitude = CASE WHEN previous.status = ANY($6::VARCHAR[]) THEN COALESCE(d.current_altitude, o.current_altitude) ELSE o.current_altitude END,
+				last_location_update_at = CASE WHEN previous.status = ANY($6::VARCHAR[]) THEN COALESCE(d.last_location_update_at, o.last_location_update_at) ELSE o.last_location_update_at END,
 				drone_id = NULL,
+				estimated_arrival_at = NULL,
 				updated_by_id = $2,
 				updated_at = NOW()
-			WHERE (drone_id = $1)
-			AND status NOT IN ('delivered', 'cancelled', 'handoff')
-			AND active = TRUE`,
-			droneID, userID)
+			FROM previous, drones d
+			WHERE o.id = previous.id AND d.id = $1
+			RETURNING o.id, o.user_id, previous.status AS from_status
+		), history AS (
+			INSERT INTO order_status_history (
+				order_id, from_status, to_status, drone_id, actor_id, reason,
+				lat, lon, altitude, created_by_id
+			)
+			SELECT
+				moved.id, moved.from_status, $3, $1, $2, $5,
+				d.current_lat, d.current_lon, d.current_altitude, $2
+			FROM moved
+			LEFT JOIN drones d ON d.id = $1
+		)
+		SELECT id, user_id, from_status FROM moved`,
+		droneID, actorID, domain.OrderStatusHandoff,
+		pq.Array(domain.OrderStatusHandoff.AllowedFrom()), reason,
+		pq.Array(domain.CarryingStatuses))
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []domain.HandedOffOrder
+	for rows.Next() {
+		var order domain.HandedOffOrder
+		if err := rows.Scan(&order.OrderID, &order.UserID, &order.FromStatus); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := releaseDroneCarriers(ctx, tx, droneID, domain.OrderStatusHandoff, actorID); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// MarkLostDrones grounds flying drones whose last heartbeat is older than lastHeartbeatBefore
+// as lost and hands off their orders like a broken drone
+func (r *DronesRepository) MarkLostDrones(ctx context.Context, lastHeartbeatBefore time.Time, limit int) ([]*domain.LostDrone, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// A drone that never reported falls back to its last status change
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE drones d SET
+			status = $1,
+			updated_at = NOW()
+		FROM (
+			SELECT id FROM drones
+			WHERE status = ANY($2::VARCHAR[])
+			AND COALESCE(last_location_update_at, updated_at) < $3
+			AND active = TRUE
+			ORDER BY COALESCE(last_location_update_at, updated_at) ASC
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		) stale
+		WHERE d.id = stale.id
+		RETURNING
+			d.id, d.drone_identifier, d.user_id, d.model, d.serial_number, d.manufacturer,
+			d.max_weight_kg, d.max_speed_kmh, d.max_range_km, d.battery_capacity_mah,
+			d.status, d.battery_level_percent, d.current_lat, d.current_lon, d.current_altitude,
+			d.last_location_update_at, d.total_flight_hours, d.total_deliveries,
+			d.last_maintenance_at, d.next_maintenance_due_at, d.capabilities, d.version,
+			d.created_at, d.updated_at, d.active, d.created_by_id, d.updated_by_id`,
+		domain.DroneStatusLost,
+		pq.Array(domain.HeartbeatWatchedStatuses),
+		lastHeartbeatBefore.UTC(),
+		limit,
+	)
+	if err != nil {
+		r.logger.Error("Failed to mark lost drones", "error", err)
+		return nil, err
+	}
+
+	var lost []*domain.LostDrone
+	for rows.Next() {
+		drone, err := r.scanDrone(rows)
 		if err != nil {
-			r.logger.Error("Failed to update orders for broken drone", "droneID", droneID, "error", err)
+			rows.Close()
+			r.logger.Error("Failed to scan lost drone", "error", err)
 			return nil, err
 		}
+		lost = append(lost, &domain.LostDrone{Drone: drone})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Failed to iterate lost drones", "error", err)
+		return nil, err
 	}
 
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
+	for _, drone := range lost {
+		drone.Orders, err = handoffDroneOrders(ctx, tx, drone.Drone.ID, nil, domain.OrderStatusReasonDroneLost)
+		if err != nil {
+			r.logger.Error("Failed to update orders for lost drone", "droneID", drone.Drone.ID, "error", err)
+			return nil, err
+		}
+	}
 
+	if err := tx.Commit(); err != nil {
 		r.logger.Error("Failed to commit transaction", "error", err)
 		return nil, err
 	}
 
-	return &updatedDrone, nil
+	return lost, nil
 }
 
 // Use transaction to ensure data consistency
 // ProcessHeartbeat processes a heartbeat from a drone and updates its status and location
 // If drone have active order, update order location
-func (r *DronesRepository) ProcessHeartbeat(ctx context.Context, droneID string, userId string, req domain.HeartbeatRequest) (*domain.Drone, error) {
+// The time since the previous heartbeat is credited as flight time when the drone was flying and
+// heard from within maxFlightGap, and every heartbeat is recorded in the telemetry history.
+func (r *DronesRepository) ProcessHeartbeat(ctx context.Context, droneID string, userId string, req domain.HeartbeatRequest, maxFlightGap time.Duration) (*domain.Drone, error) {
 	// Begin transaction
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -798,7 +1004,30 @@ func (r *DronesRepository) ProcessHeartbeat(ctx context.Context, droneID string,
 	}
 	defer tx.Rollback()
 
-	// Update drone location and battery level
+	// Flight time since the previous heartbeat, a longer silence is not counted
+	var flightSeconds float64
+	err = tx.QueryRowContext(ctx, `
+		SELECT CASE
+			WHEN status = ANY($2::VARCHAR[]) AND last_location_update_at > NOW() - $3 * INTERVAL '1 second'
+			THEN EXTRACT(EPOCH FROM NOW() - last_location_update_at)
+			ELSE 0
+		END
+		FROM drones
+		WHERE id = $1 AND active = TRUE
+		FOR UPDATE`,
+		droneID,
+		pq.Array(domain.HeartbeatWatchedStatuses),
+		maxFlightGap.Seconds(),
+	).Scan(&flightSeconds)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrDroneNotFound
+		}
+		r.logger.Error("Failed to lock drone in heartbeat", "droneID", droneID, "error", err)
+		return nil, err
+	}
+
+	// Update drone location, battery level and flight hours
 	var updatedDrone domain.Drone
 	err = tx.QueryRowContext(ctx, `
 		UPDATE drones SET
@@ -807,6 +1036,7 @@ func (r *DronesRepository) ProcessHeartbeat(ctx context.Context, droneID string,
 			current_altitude = $4,
 			battery_level_percent = $5,
 			last_location_update_at = NOW(),
+			total_flight_hours = total_flight_hours + $7 / 3600.0,
 			updated_by_id = $6,
 			updated_at = NOW()
 		WHERE id = $1 AND active = TRUE
@@ -815,7 +1045,7 @@ func (r *DronesRepository) ProcessHeartbeat(ctx context.Context, droneID string,
 			max_weight_kg, max_speed_kmh, max_range_km, battery_capacity_mah,
 			status, battery_level_percent, current_lat, current_lon, current_altitude,
 			last_location_update_at, total_flight_hours, total_deliveries,
-			last_maintenance_at, next_maintenance_due_at,
+			last_maintenance_at, next_maintenance_due_at, capabilities, version,
 			created_at, updated_at, active, created_by_id, updated_by_id`,
 		droneID,
 		req.Latitude,
@@ -823,6 +1053,7 @@ func (r *DronesRepository) ProcessHeartbeat(ctx context.Context, droneID string,
 		req.Altitude,
 		req.Battery,
 		userId,
+		flightSeconds,
 	).Scan(
 		&updatedDrone.ID,
 		&updatedDrone.DroneIdentifier,
@@ -844,6 +1075,8 @@ func (r *DronesRepository) ProcessHeartbeat(ctx context.Context, droneID string,
 		&updatedDrone.TotalDeliveries,
 		&updatedDrone.LastMaintenanceAt,
 		&updatedDrone.NextMaintenanceDueAt,
+		capabilitiesArray{&updatedDrone.Capabilities},
+		&updatedDrone.Version,
 		&updatedDrone.CreatedAt,
 		&updatedDrone.UpdatedAt,
 		&updatedDrone.Active,
@@ -858,24 +1091,44 @@ func (r *DronesRepository) ProcessHeartbeat(ctx context.Context, droneID string,
 		return nil, err
 	}
 
-	// Update location of active order assigned to this drone
-	_, err = tx.ExecContext(ctx, `
+	// Update location of the order carried by this drone. A rescue drone still flying
+	// to a handoff pickup point must not move it.
+	var carriedOrderID *string
+	err = tx.QueryRowContext(ctx, `
 		UPDATE orders SET
 			current_lat = $1,
 			current_lon = $2,
 			updated_at = NOW()
 		WHERE drone_id = $3
-		AND status IN ('picked_up', 'in_transit', 'arrived', 'handoff', 'reassigned')
-		AND active = TRUE`,
+		AND status = ANY($4::VARCHAR[])
+		AND active = TRUE
+		RETURNING id`,
 		req.Latitude,
 		req.Longitude,
 		droneID,
-	)
-	if err != nil {
+		pq.Array(domain.CarryingStatuses),
+	).Scan(&carriedOrderID)
+	if err != nil && err != sql.ErrNoRows {
 		r.logger.Error("Failed to update order location in heartbeat", "droneID", droneID, "error", err)
 		return nil, err
 	}
 
+	// Keep the heartbeat, the drone row only holds the latest one
+	err = insertDroneTelemetry(ctx, tx, domain.DroneTelemetry{
+		DroneID:             droneID,
+		OrderID:             carriedOrderID,
+		Status:              updatedDrone.Status,
+		Lat:                 req.Latitude,
+		Lon:                 req.Longitude,
+		Altitude:            req.Altitude,
+		BatteryLevelPercent: req.Battery,
+		FlightSeconds:       flightSeconds,
+	})
+	if err != nil {
+		r.logger.Error("Failed to record drone telemetry in heartbeat", "droneID", droneID, "error", err)
+		return nil, err
+	}
+
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
 		r.logger.Error("Failed to commit heartbeat transaction", "error", err)
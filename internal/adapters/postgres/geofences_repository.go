@@ -0,0 +1,271 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"drones/internal/core/domain"
+	"drones/internal/ports"
+)
+
+type GeofencesRepository struct {
+	db     *sql.DB
+	logger ports.Logger
+}
+
+func NewGeofencesRepository(db *sql.DB, logger ports.Logger) ports.GeofencesRepository {
+	return &GeofencesRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const geofenceColumns = `
+	id, name, shape, points, center_lat, center_lon, radius_m,
+	start_time, end_time, active_from, active_until,
+	created_at, updated_at, active, created_by_id, updated_by_id`
+
+func scanGeofence(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*domain.Geofence, error) {
+	var zone domain.Geofence
+	err := scanner.Scan(
+		&zone.ID,
+		&zone.Name,
+		&zone.Shape,
+		geoPointsJSON{&zone.Points},
+		&zone.CenterLat,
+		&zone.CenterLon,
+		&zone.RadiusMeters,
+		&zone.StartTime,
+		&zone.EndTime,
+		&zone.ActiveFrom,
+		&zone.ActiveUntil,
+		&zone.CreatedAt,
+		&zone.UpdatedAt,
+		&zone.Active,
+		&zone.CreatedByID,
+		&zone.UpdatedByID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &zone, nil
+}
+
+// ListGeofences retrieves the geofences not deleted, whatever their time window
+func (r *GeofencesRepository) ListGeofences(ctx context.Context) ([]*domain.Geofence, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT`+geofenceColumns+`
+		FROM geofences
+		WHERE active = TRUE
+		ORDER BY created_at ASC`)
+	if err != nil {
+		r.logger.Error("Failed to list geofences", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var zones []*domain.Geofence
+	for rows.Next() {
+		zone, err := scanGeofence(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan geofence", "error", err)
+			return nil, err
+		}
+		zones = append(zones, zone)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Failed to iterate geofences", "error", err)
+		return nil, err
+	}
+
+	return zones, nil
+}
+
+// CreateGeofence inserts a new geofence
+func (r *GeofencesRepository) CreateGeofence(ctx context.Context, userID string, request *domain.GeofenceRequest) (*domain.Geofence, error) {
+	zone, err := scanGeofence(r.db.QueryRowContext(ctx, `
+		INSERT INTO geofences (
+			name, shape, points, center_lat, center_lon, radius_m,
+			start_time, end_time, active_from, active_until, created_by_id, updated_by_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $11)
+		RETURNING`+geofenceColumns,
+		request.Name,
+		request.Shape,
+		geoPointsJSON{&request.Points},
+		request.CenterLat,
+		request.CenterLon,
+		request.RadiusMeters,
+		request.StartTime,
+		request.EndTime,
+		request.ActiveFrom,
+		request.ActiveUntil,
+		userID,
+	))
+	if err != nil {
+		r.logger.Error("Failed to create geofence", "error", err)
+		return nil, err
+	}
+
+	return zone, nil
+}
+
+// UpdateGeofence replaces a geofence
+func (r *GeofencesRepository) UpdateGeofence(ctx context.Context, geofenceID string, userID string, request *domain.GeofenceRequest) (*domain.Geofence, error) {
+	zone, err := scanGeofence(r.db.QueryRowContext(ctx, `
+		UPDATE geofences SET
+			name = $2,
+			shape = $3,
+			points = $4,
+			center_lat = $5,
+			center_lon = $6,
+			radius_m = $7,
+			start_time = $8,
+			end_time = $9,
+			active_from = $10,
+			active_until = $11,
+			updated_by_id = $12,
+			updated_at = NOW()
+		WHERE id = $1 AND active = TRUE
+		RETURNING`+geofenceColumns,
+		geofenceID,
+		request.Name,
+		request.Shape,
+		geoPointsJSON{&request.Points},
+		request.CenterLat,
+		request.CenterLon,
+		request.RadiusMeters,
+		request.StartTime,
+		request.EndTime,
+		request.ActiveFrom,
+		request.ActiveUntil,
+		userID,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrGeofenceNotFound
+		}
+		r.logger.Error("Failed to update geofence", "geofenceID", geofenceID, "error", err)
+		return nil, err
+	}
+
+	return zone, nil
+}
+
+// DeleteGeofence deactivates a geofence, its violations are kept
+func (r *GeofencesRepository) DeleteGeofence(ctx context.Context, geofenceID string, userID string) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE geofences SET
+			active = FALSE,
+			updated_by_id = $2,
+			updated_at = NOW()
+		WHERE id = $1 AND active = TRUE`, geofenceID, userID)
+	if err != nil {
+		r.logger.Error("Failed to delete geofence", "geofenceID", geofenceID, "error", err)
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrGeofenceNotFound
+	}
+
+	return nil
+}
+
+const geofenceViolationColumns = `
+	v.id, v.geofence_id, g.name, v.drone_id, v.order_id, v.lat, v.lon, v.altitude, v.created_at`
+
+func scanGeofenceViolation(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*domain.GeofenceViolation, error) {
+	var violation domain.GeofenceViolation
+	err := scanner.Scan(
+		&violation.ID,
+		&violation.GeofenceID,
+		&violation.GeofenceName,
+		&violation.DroneID,
+		&violation.OrderID,
+		&violation.Lat,
+		&violation.Lon,
+		&violation.Altitude,
+		&violation.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &violation, nil
+}
+
+// RecordViolation records a drone entering a geofence
+func (r *GeofencesRepository) RecordViolation(ctx context.Context, violation domain.GeofenceViolation) (*domain.GeofenceViolation, error) {
+	recorded, err := scanGeofenceViolation(r.db.QueryRowContext(ctx, `
+		WITH v AS (
+			INSERT INTO geofence_violations (
+				geofence_id, drone_id, order_id, lat, lon, altitude
+			) VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING *
+		)
+		SELECT`+geofenceViolationColumns+`
+		FROM v
+		JOIN geofences g ON g.id = v.geofence_id`,
+		violation.GeofenceID,
+		violation.DroneID,
+		violation.OrderID,
+		violation.Lat,
+		violation.Lon,
+		violation.Altitude,
+	))
+	if err != nil {
+		if isForeignKeyViolation(err, "geofence_violations_geofence_id_fkey") {
+			return nil, domain.ErrGeofenceNotFound
+		}
+		r.logger.Error("Failed to record geofence violation", "geofenceID", violation.GeofenceID, "droneID", violation.DroneID, "error", err)
+		return nil, err
+	}
+
+	return recorded, nil
+}
+
+// ListViolations retrieves geofence violations, newest first
+func (r *GeofencesRepository) ListViolations(ctx context.Context, filter domain.GeofenceViolationFilter) ([]*domain.GeofenceViolation, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT`+geofenceViolationColumns+`
+		FROM geofence_violations v
+		JOIN geofences g ON g.id = v.geofence_id
+		WHERE ($1::UUID IS NULL OR v.geofence_id = $1)
+		AND ($2::UUID IS NULL OR v.drone_id = $2)
+		ORDER BY v.created_at DESC
+		LIMIT $3`,
+		filter.GeofenceID,
+		filter.DroneID,
+		filter.Limit,
+	)
+	if err != nil {
+		r.logger.Error("Failed to list geofence violations", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var violations []*domain.GeofenceViolation
+	for rows.Next() {
+		violation, err := scanGeofenceViolation(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan geofence violation", "error", err)
+			return nil, err
+		}
+		violations = append(violations, violation)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Failed to iterate geofence violations", "error", err)
+		return nil, err
+	}
+
+	return violations, nil
+}
@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"drones/internal/core/domain"
+)
+
+// syncOrderCarriers keeps the carrier legs in step with a transition: picking the package
+// up starts a leg, delivering, returning, re-queueing, holding at the depot, handing off or cancelling ends it
+func syncOrderCarriers(ctx context.Context, tx *sql.Tx, orderID string, droneID *string, to domain.OrderStatus, actorID *string) error {
+	switch to {
+	case domain.OrderStatusPickedUp:
+		if droneID == nil || *droneID == "" {
+			return nil
+		}
+		return insertOrderCarrier(ctx, tx, orderID, *droneID, actorID)
+	case domain.OrderStatusDelivered, domain.OrderStatusReturned, domain.OrderStatusPending, domain.OrderStatusHeldAtDepot, domain.OrderStatusHandoff, domain.OrderStatusCancelled:
+		return releaseOrderCarriers(ctx, tx, orderID, to, actorID)
+	default:
+		return nil
+	}
+}
+
+// insertOrderCarrier starts a carrier leg when a drone picks the package up, at the drone position
+func insertOrderCarrier(ctx context.Context, tx *sql.Tx, orderID, droneID string, actorID *string) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO order_carriers (order_id, drone_id, pickup_lat, pickup_lon, created_by_id)
+		SELECT $1::UUID, d.id, d.current_lat, d.current_lon, $3::UUID
+		FROM drones d
+		WHERE d.id = $2::UUID`,
+		orderID, droneID, actorID)
+	return err
+}
+
+// releaseOrderCarriers closes the open carrier leg of an order with the position of its drone
+func releaseOrderCarriers(ctx context.Context, tx *sql.Tx, orderID string, status domain.OrderStatus, actorID *string) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE order_carriers c SET
+			released_at = NOW(),
+			release_lat = d.current_lat,
+			release_lon = d.current_lon,
+			release_status = $2,
+			updated_by_id = $3,
+			updated_at = NOW()
+		FROM drones d
+		WHERE c.order_id = $1 AND c.released_at IS NULL AND d.id = c.drone_id`,
+		orderID, status, actorID)
+	return err
+}
+
+// releaseDroneCarriers closes every open carrier leg of a drone, used when it breaks
+func releaseDroneCarriers(ctx context.Context, tx *sql.Tx, droneID string, status domain.OrderStatus, actorID *string) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE order_carriers c SET
+			released_at = NOW(),
+			release_lat = d.current_lat,
+			release_lon = d.current_lon,
+			release_status = $2,
+			updated_by_id = $3,
+			updated_at = NOW()
+		FROM drones d
+		WHERE c.drone_id = $1 AND c.released_at IS NULL AND d.id = c.drone_id`,
+		droneID, status, actorID)
+	return err
+}
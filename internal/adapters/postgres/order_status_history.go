@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"drones/internal/core/domain"
+)
+
+// insertOrderStatusHistory records a transition inside the transaction that made it.
+// The position is taken from the drone when one is involved, otherwise from the
+// order's last known location, falling back to its origin.
+func insertOrderStatusHistory(ctx context.Context, tx *sql.Tx, entry domain.OrderStatusHistory) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO order_status_history (
+			order_id, from_status, to_status, drone_id, actor_id, reason,
+			lat, lon, altitude, created_by_id
+		)
+		SELECT
+			o.id, $2::VARCHAR, $3::VARCHAR, $4::UUID, $5::UUID, $6::TEXT,
+			COALESCE(d.current_lat, o.current_lat, o.origin_lat),
+			COALESCE(d.current_lon, o.current_lon, o.origin_lon),
+			COALESCE(d.current_altitude, o.current_altitude),
+			$5::UUID
+		FROM orders o
+		LEFT JOIN drones d ON d.id = $4::UUID
+		WHERE o.id = $1`,
+		entry.OrderID,
+		entry.FromStatus,
+		entry.ToStatus,
+		entry.DroneID,
+		entry.ActorID,
+		entry.Reason,
+	)
+	return err
+}
+
+// lockOrderStatus reads the current status of an order and locks the row until the transaction ends
+func lockOrderStatus(ctx context.Context, tx *sql.Tx, orderID string) (domain.OrderStatus, error) {
+	var status domain.OrderStatus
+	err := tx.QueryRowContext(ctx, `
+		SELECT status FROM orders
+		WHERE id = $1 AND active = TRUE
+		FOR UPDATE`, orderID).Scan(&status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", domain.ErrOrderNotFound
+		}
+		return "", err
+	}
+	return status, nil
+}
+
+// nullIfEmpty maps an empty id to NULL
+func nullIfEmpty(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
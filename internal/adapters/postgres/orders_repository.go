@@ -4,11 +4,53 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
 	"drones/internal/core/domain"
 	"drones/internal/ports"
+
+	"github.com/lib/pq"
 )
 
+// updateOrderQuery updates the non-null fields of an order, see updateOrderArgs
+const updateOrderQuery = `
+		UPDATE orders SET
+			receiver_name = COALESCE($2, receiver_name),
+			receiver_phone = COALESCE($3, receiver_phone),
+			delivery_note = COALESCE($4, delivery_note),
+			package_weight_kg = COALESCE($5, package_weight_kg),
+			origin_address = COALESCE($6, origin_address),
+			origin_lat = COALESCE($7, origin_lat),
+			origin_lon = COALESCE($8, origin_lon),
+			destination_address = COALESCE($9, destination_address),
+			destination_lat = COALESCE($10, destination_lat),
+			destination_lon = COALESCE($11, destination_lon),
+			status = COALESCE($12, status),
+			scheduled_at = COALESCE($13, scheduled_at),
+			delivered_at = COALESCE($14, delivered_at),
+			cancelled_at = COALESCE($15, cancelled_at),
+			delivered_by_drone_id = COALESCE($16, delivered_by_drone_id),
+			updated_by_id = COALESCE($17, updated_by_id),
+			withdrawn_at = COALESCE($18, withdrawn_at),
+			current_lat = COALESCE($19, current_lat),
+			current_lon = COALESCE($20, current_lon),
+			current_altitude = COALESCE($21, current_altitude),
+			last_location_update_at = COALESCE($22, last_location_update_at),
+			estimated_arrival_at = COALESCE($23, estimated_arrival_at),
+			drone_id = COALESCE($24, drone_id),
+			updated_at = NOW()
+		WHERE id = $1 AND active = TRUE AND ($25::INTEGER IS NULL OR version = $25)
+	RETURNING
+		id, order_number, user_id, receiver_name, receiver_phone, delivery_note,
+		package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
+		destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
+		delivered_by_drone_id, drone_id, withdrawn_at, current_lat, current_lon, current_altitude,
+		last_location_update_at, estimated_arrival_at, cancellation_reason, cancellation_note, cancelled_by_id,
+		price, currency, quote_id, priority, required_capabilities, version,
+		delivery_attempts, max_delivery_attempts, failure_action, service_area_id,
+		created_at, updated_at, active`
+
 type OrdersRepositoryImpl struct {
 	db                    *sql.DB
 	logger                ports.Logger
@@ -44,53 +86,26 @@ func (r *OrdersRepositoryImpl) prepareStatements() error {
 		INSERT INTO orders (
 			user_id, receiver_name, receiver_phone, delivery_note,
 			package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
-			destination_lat, destination_lon, scheduled_at, created_by_id
+			destination_lat, destination_lon, scheduled_at, created_by_id, status,
+			price, currency, quote_id, priority, required_capabilities, max_delivery_attempts,
+			service_area_id
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21
 	) RETURNING
 		id, order_number, user_id, receiver_name, receiver_phone, delivery_note,
 		package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
 		destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
 		delivered_by_drone_id, drone_id, withdrawn_at, current_lat, current_lon, current_altitude,
-		last_location_update_at, estimated_arrival_at, created_at, updated_at, active`)
+		last_location_update_at, estimated_arrival_at, cancellation_reason, cancellation_note, cancelled_by_id,
+		price, currency, quote_id, priority, required_capabilities, version,
+		delivery_attempts, max_delivery_attempts, failure_action, service_area_id,
+		created_at, updated_at, active`)
 	if err != nil {
 		return err
 	}
 
 	// Update statement with comprehensive fields
-	r.updateStmt, err = r.db.Prepare(`
-		UPDATE orders SET
-			receiver_name = COALESCE($2, receiver_name),
-			receiver_phone = COALESCE($3, receiver_phone),
-			delivery_note = COALESCE($4, delivery_note),
-			package_weight_kg = COALESCE($5, package_weight_kg),
-			origin_address = COALESCE($6, origin_address),
-			origin_lat = COALESCE($7, origin_lat),
-			origin_lon = COALESCE($8, origin_lon),
-			destination_address = COALESCE($9, destination_address),
-			destination_lat = COALESCE($10, destination_lat),
-			destination_lon = COALESCE($11, destination_lon),
-			status = COALESCE($12, status),
-			scheduled_at = COALESCE($13, scheduled_at),
-			delivered_at = COALESCE($14, delivered_at),
-			cancelled_at = COALESCE($15, cancelled_at),
-			delivered_by_drone_id = COALESCE($16, delivered_by_drone_id),
-			updated_by_id = COALESCE($17, updated_by_id),
-			withdrawn_at = COALESCE($18, withdrawn_at),
-			current_lat = COALESCE($19, current_lat),
-			current_lon = COALESCE($20, current_lon),
-			current_altitude = COALESCE($21, current_altitude),
-			last_location_update_at = COALESCE($22, last_location_update_at),
-			estimated_arrival_at = COALESCE($23, estimated_arrival_at),
-			drone_id = COALESCE($24, drone_id),
-			updated_at = NOW()
-		WHERE id = $1 AND active = TRUE
-	RETURNING
-		id, order_number, user_id, receiver_name, receiver_phone, delivery_note,
-		package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
-		destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
-		delivered_by_drone_id, drone_id, withdrawn_at, current_lat, current_lon, current_altitude,
-		last_location_update_at, estimated_arrival_at, created_at, updated_at, active`)
+	r.updateStmt, err = r.db.Prepare(updateOrderQuery)
 	if err != nil {
 		return err
 	}
@@ -108,7 +123,10 @@ func (r *OrdersRepositoryImpl) prepareStatements() error {
 			package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
 			destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
 			delivered_by_drone_id,drone_id , withdrawn_at, current_lat, current_lon, current_altitude,
-			last_location_update_at, estimated_arrival_at, created_at, updated_at, active
+			last_location_update_at, estimated_arrival_at, cancellation_reason, cancellation_note, cancelled_by_id,
+			price, currency, quote_id, priority, required_capabilities, version,
+			delivery_attempts, max_delivery_attempts, failure_action, service_area_id,
+			created_at, updated_at, active
 		FROM orders
 		WHERE order_number = $1 AND active = TRUE`)
 	if err != nil {
@@ -127,7 +145,10 @@ func (r *OrdersRepositoryImpl) prepareStatements() error {
 		package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
 		destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
 		delivered_by_drone_id,drone_id, withdrawn_at, current_lat, current_lon, current_altitude,
-		last_location_update_at, estimated_arrival_at, created_at, updated_at, active`)
+		last_location_update_at, estimated_arrival_at, cancellation_reason, cancellation_note, cancelled_by_id,
+		price, currency, quote_id, priority, required_capabilities, version,
+		delivery_attempts, max_delivery_attempts, failure_action, service_area_id,
+		created_at, updated_at, active`)
 	if err != nil {
 		return err
 	}
@@ -139,7 +160,10 @@ func (r *OrdersRepositoryImpl) prepareStatements() error {
 			package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
 			destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
 			delivered_by_drone_id, drone_id, withdrawn_at, current_lat, current_lon, current_altitude,
-			last_location_update_at, estimated_arrival_at, created_at, updated_at, active
+			last_location_update_at, estimated_arrival_at, cancellation_reason, cancellation_note, cancelled_by_id,
+			price, currency, quote_id, priority, required_capabilities, version,
+			delivery_attempts, max_delivery_attempts, failure_action, service_area_id,
+			created_at, updated_at, active
 		FROM orders
 		WHERE user_id = $1 AND active = TRUE
 		ORDER BY created_at DESC`)
@@ -154,7 +178,10 @@ func (r *OrdersRepositoryImpl) prepareStatements() error {
 			package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
 			destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
 			delivered_by_drone_id,drone_id, withdrawn_at, current_lat, current_lon, current_altitude,
-			last_location_update_at, estimated_arrival_at, created_at, updated_at, active
+			last_location_update_at, estimated_arrival_at, cancellation_reason, cancellation_note, cancelled_by_id,
+			price, currency, quote_id, priority, required_capabilities, version,
+			delivery_attempts, max_delivery_attempts, failure_action, service_area_id,
+			created_at, updated_at, active
 		FROM orders
 		WHERE active = TRUE AND status = $1
 		ORDER BY updated_at DESC`)
@@ -233,11 +260,12 @@ func (r *OrdersRepositoryImpl) GetDB() *sql.DB {
 }
 
 // scanOrder scans a row into an Order struct
+// extra receives computed columns selected after the order columns
 func (r *OrdersRepositoryImpl) scanOrder(scanner interface {
 	Scan(dest ...interface{}) error
-}) (*domain.Order, error) {
+}, extra ...interface{}) (*domain.Order, error) {
 	var order domain.Order
-	err := scanner.Scan(
+	dest := []interface{}{
 		&order.ID,
 		&order.OrderNumber,
 		&order.UserID,
@@ -263,10 +291,24 @@ func (r *OrdersRepositoryImpl) scanOrder(scanner interface {
 		&order.CurrentAltitude,
 		&order.LastLocationUpdateAt,
 		&order.EstimatedArrivalAt,
+		&order.CancellationReason,
+		&order.CancellationNote,
+		&order.CancelledByID,
+		&order.Price,
+		&order.Currency,
+		&order.QuoteID,
+		&order.Priority,
+		capabilitiesArray{&order.RequiredCapabilities},
+		&order.Version,
+		&order.DeliveryAttempts,
+		&order.MaxDeliveryAttempts,
+		&order.FailureAction,
+		&order.ServiceAreaID,
 		&order.CreatedAt,
 		&order.UpdatedAt,
 		&order.Active,
-	)
+	}
+	err := scanner.Scan(append(dest, extra...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -275,11 +317,64 @@ func (r *OrdersRepositoryImpl) scanOrder(scanner interface {
 
 // CreateOrder creates a new order in the repository
 func (r *OrdersRepositoryImpl) CreateOrder(ctx context.Context, userID string, createOrder *domain.CreateOrderRequest) (*domain.Order, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	order, err := r.insertOrder(ctx, tx, userID, createOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", "error", err)
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// CreateOrders inserts all orders in a single transaction, either every order is created or none
+func (r *OrdersRepositoryImpl) CreateOrders(ctx context.Context, userID string, createOrders []*domain.CreateOrderRequest) ([]*domain.Order, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	orders := make([]*domain.Order, 0, len(createOrders))
+	for _, createOrder := range createOrders {
+		order, err := r.insertOrder(ctx, tx, userID, createOrder)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", "error", err)
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// insertOrder inserts a single order and its initial status history within tx
+func (r *OrdersRepositoryImpl) insertOrder(ctx context.Context, tx *sql.Tx, userID string, createOrder *domain.CreateOrderRequest) (*domain.Order, error) {
 	var order *domain.Order
 	var err error
 
+	status := createOrder.Status
+	if status == "" {
+		status = domain.OrderStatusPending
+	}
+
 	if r.createStmt != nil {
-		order, err = r.scanOrder(r.createStmt.QueryRowContext(ctx,
+		order, err = r.scanOrder(tx.StmtContext(ctx, r.createStmt).QueryRowContext(ctx,
 			userID,
 			createOrder.ReceiverName,
 			createOrder.ReceiverPhone,
@@ -293,20 +388,33 @@ func (r *OrdersRepositoryImpl) CreateOrder(ctx context.Context, userID string, c
 			createOrder.DestinationLon,
 			createOrder.ScheduledAt,
 			userID,
+			status,
+			createOrder.Price,
+			createOrder.Currency,
+			createOrder.QuoteReference,
+			createOrder.Priority.OrDefault(),
+			capabilitiesArray{&createOrder.RequiredCapabilities},
+			createOrder.MaxDeliveryAttempts,
+			createOrder.ServiceAreaID,
 		))
 	} else {
-		order, err = r.scanOrder(r.db.QueryRowContext(ctx, `
+		order, err = r.scanOrder(tx.QueryRowContext(ctx, `
 			INSERT INTO orders (
 				user_id, receiver_name, receiver_phone, delivery_note,
 				package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
-				destination_lat, destination_lon, scheduled_at, created_by_id
-			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+				destination_lat, destination_lon, scheduled_at, created_by_id, status,
+				price, currency, quote_id, priority, required_capabilities, max_delivery_attempts,
+				service_area_id
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
 			RETURNING
 				id, order_number, user_id, receiver_name, receiver_phone, delivery_note,
 				package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
 				destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
 				delivered_by_drone_id, drone_id, withdrawn_at, current_lat, current_lon, current_altitude,
-				last_location_update_at, estimated_arrival_at, created_at, updated_at, active`,
+				last_location_update_at, estimated_arrival_at, cancellation_reason, cancellation_note, cancelled_by_id,
+				price, currency, quote_id, priority, required_capabilities, version,
+				delivery_attempts, max_delivery_attempts, failure_action, service_area_id,
+				created_at, updated_at, active`,
 			userID,
 			createOrder.ReceiverName,
 			createOrder.ReceiverPhone,
@@ -320,14 +428,44 @@ func (r *OrdersRepositoryImpl) CreateOrder(ctx context.Context, userID string, c
 			createOrder.DestinationLon,
 			createOrder.ScheduledAt,
 			userID,
+			status,
+			createOrder.Price,
+			createOrder.Currency,
+			createOrder.QuoteReference,
+			createOrder.Priority.OrDefault(),
+			capabilitiesArray{&createOrder.RequiredCapabilities},
+			createOrder.MaxDeliveryAttempts,
+			createOrder.ServiceAreaID,
 		))
 	}
 
 	if err != nil {
+		if isUniqueViolation(err, "idx_orders_quote_id") {
+			return nil, domain.ErrQuoteAlreadyUsed
+		}
 		r.logger.Error("Failed to create order", "error", err)
 		return nil, err
 	}
 
+	reason := domain.OrderStatusReasonCreated
+	err = insertOrderStatusHistory(ctx, tx, domain.OrderStatusHistory{
+		OrderID:  order.ID,
+		ToStatus: order.Status,
+		ActorID:  &userID,
+		Reason:   &reason,
+	})
+	if err != nil {
+		r.logger.Error("Failed to record order status history", "orderID", order.ID, "error", err)
+		return nil, err
+	}
+
+	if createOrder.DeliveryCode != nil {
+		if err := insertDeliveryCode(ctx, tx, order.ID, createOrder.DeliveryCode, userID); err != nil {
+			r.logger.Error("Failed to store delivery code", "orderID", order.ID, "error", err)
+			return nil, err
+		}
+	}
+
 	return order, nil
 }
 
@@ -340,7 +478,10 @@ func (r *OrdersRepositoryImpl) GetOrderByID(ctx context.Context, orderID string,
 			package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
 			destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
 			delivered_by_drone_id, drone_id, withdrawn_at, current_lat, current_lon, current_altitude,
-			last_location_update_at, estimated_arrival_at, created_at, updated_at, active
+			last_location_update_at, estimated_arrival_at, cancellation_reason, cancellation_note, cancelled_by_id,
+			price, currency, quote_id, priority, required_capabilities, version,
+			delivery_attempts, max_delivery_attempts, failure_action, service_area_id,
+			created_at, updated_at, active
 		FROM orders
 		WHERE id = $1 AND active = TRUE`
 
@@ -364,104 +505,32 @@ func (r *OrdersRepositoryImpl) GetOrderByID(ctx context.Context, orderID string,
 	return order, nil
 }
 
-// UpdateOrder updates an existing order in the repository
+// UpdateOrder updates an existing order in the repository.
+// When the update changes the status it runs in a transaction that checks the
+// order state machine and records the transition.
 func (r *OrdersRepositoryImpl) UpdateOrder(ctx context.Context, orderID string, update *domain.UpdateOrderRequest) (*domain.Order, error) {
 	var err error
 
 	r.logger.Info("Updating order", "orderID", orderID, "update", update)
 
+	if update.Status != nil {
+		return r.updateOrderWithStatus(ctx, orderID, update)
+	}
+
 	var order *domain.Order
 	if r.updateStmt != nil {
-		order, err = r.scanOrder(r.updateStmt.QueryRowContext(ctx,
-			orderID,
-			update.ReceiverName,
-			update.ReceiverPhone,
-			update.DeliveryNote,
-			update.PackageWeightKg,
-			update.OriginAddress,
-			update.OriginLat,
-			update.OriginLon,
-			update.DestinationAddress,
-			update.DestinationLat,
-			update.DestinationLon,
-			update.Status,
-			update.ScheduledAt,
-			update.DeliveredAt,
-			update.CancelledAt,
-			update.DeliveredByDroneID,
-			update.UpdatedByID,
-			update.WithdrawnAt,
-			update.CurrentLat,
-			update.CurrentLon,
-			update.CurrentAltitude,
-			update.LastLocationUpdateAt,
-			update.EstimatedArrivalAt,
-			update.DroneID,
-		))
+		order, err = r.scanOrder(r.updateStmt.QueryRowContext(ctx, updateOrderArgs(orderID, update)...))
 	} else {
 		// Fallback to regular query with COALESCE for null handling
-		order, err = r.scanOrder(r.db.QueryRowContext(ctx, `
-			UPDATE orders SET
-				receiver_name = COALESCE($2, receiver_name),
-				receiver_phone = COALESCE($3, receiver_phone),
-				delivery_note = COALESCE($4, delivery_note),
-				package_weight_kg = COALESCE($5, package_weight_kg),
-				origin_address = COALESCE($6, origin_address),
-				origin_lat = COALESCE($7, origin_lat),
-				origin_lon = COALESCE($8, origin_lon),
-				destination_address = COALESCE($9, destination_address),
-				destination_lat = COALESCE($10, destination_lat),
-				destination_lon = COALESCE($11, destination_lon),
-				status = COALESCE($12, status),
-				scheduled_at = COALESCE($13, scheduled_at),
-				delivered_at = COALESCE($14, delivered_at),
-				cancelled_at = COALESCE($15, cancelled_at),
-				delivered_by_drone_id = COALESCE($16, delivered_by_drone_id),
-				updated_by_id = COALESCE($17, updated_by_id),
-				withdrawn_at = COALESCE($18, withdrawn_at),
-				current_lat = COALESCE($19, current_lat),
-				current_lon = COALESCE($20, current_lon),
-				current_altitude = COALESCE($21, current_altitude),
-				last_location_update_at = COALESCE($22, last_location_update_at),
-				estimated_arrival_at = COALESCE($23, estimated_arrival_at),
-				drone_id = COALESCE($24, drone_id),
-				updated_at = NOW()
-			WHERE id = $1 AND active = TRUE
-			RETURNING
-				id, order_number, user_id, receiver_name, receiver_phone, delivery_note,
-				package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
-				destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
-				delivered_by_drone_id, drone_id, withdrawn_at, current_lat, current_lon, current_altitude,
-				last_location_update_at, estimated_arrival_at, created_at, updated_at, active`,
-			orderID,
-			update.ReceiverName,
-			update.ReceiverPhone,
-			update.DeliveryNote,
-			update.PackageWeightKg,
-			update.OriginAddress,
-			update.OriginLat,
-			update.OriginLon,
-			update.DestinationAddress,
-			update.DestinationLat,
-			update.DestinationLon,
-			update.Status,
-			update.ScheduledAt,
-			update.DeliveredAt,
-			update.CancelledAt,
-			update.DeliveredByDroneID,
-			update.UpdatedByID,
-			update.WithdrawnAt,
-			update.CurrentLat,
-			update.CurrentLon,
-			update.CurrentAltitude,
-			update.LastLocationUpdateAt,
-			update.EstimatedArrivalAt,
-			update.DroneID,
-		))
+		order, err = r.scanOrder(r.db.QueryRowContext(ctx, updateOrderQuery, updateOrderArgs(orderID, update)...))
 	}
 
 	if err != nil {
 		if err == sql.ErrNoRows {
+			if update.ExpectedVersion != nil && r.orderExists(ctx, orderID) {
+				r.logger.Warn("Order version moved on before update", "orderID", orderID, "expectedVersion", *update.ExpectedVersion)
+				return nil, domain.ErrOrderVersionConflict
+			}
 			r.logger.Warn("Order not found for update", "orderID", orderID)
 			return nil, domain.ErrOrderNotFound
 		}
@@ -472,6 +541,115 @@ func (r *OrdersRepositoryImpl) UpdateOrder(ctx context.Context, orderID string,
 	return order, nil
 }
 
+// orderExists reports whether an active order exists, used to tell a missing order
+// from a version conflict when a conditional update matched no row
+func (r *OrdersRepositoryImpl) orderExists(ctx context.Context, orderID string) bool {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM orders WHERE id = $1 AND active = TRUE)`, orderID).Scan(&exists)
+	if err != nil {
+		r.logger.Error("Failed to check order existence", "orderID", orderID, "error", err)
+		return false
+	}
+	return exists
+}
+
+// updateOrderWithStatus locks the order, rejects transitions the state machine does not
+// allow and writes the history entry together with the update
+func (r *OrdersRepositoryImpl) updateOrderWithStatus(ctx context.Context, orderID string, update *domain.UpdateOrderRequest) (*domain.Order, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	from, err := lockOrderStatus(ctx, tx, orderID)
+	if err != nil {
+		if err != domain.ErrOrderNotFound {
+			r.logger.Error("Failed to lock order for update", "orderID", orderID, "error", err)
+		}
+		return nil, err
+	}
+
+	to := *update.Status
+	if to != from && !to.IsTransitionAllowed(from) {
+		return nil, from.TransitionErr()
+	}
+
+	order, err := r.scanOrder(tx.QueryRowContext(ctx, updateOrderQuery, updateOrderArgs(orderID, update)...))
+	if err != nil {
+		// The row is locked, so no match means the version moved on
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrOrderVersionConflict
+		}
+		r.logger.Error("Failed to update order", "orderID", orderID, "error", err)
+		return nil, err
+	}
+
+	if to != from {
+		droneID := order.DroneID
+		if droneID == nil {
+			droneID = order.DeliveredByDroneID
+		}
+		err = insertOrderStatusHistory(ctx, tx, domain.OrderStatusHistory{
+			OrderID:    orderID,
+			FromStatus: &from,
+			ToStatus:   to,
+			DroneID:    droneID,
+			ActorID:    update.UpdatedByID,
+			Reason:     update.StatusReason,
+		})
+		if err != nil {
+			r.logger.Error("Failed to record order status history", "orderID", orderID, "error", err)
+			return nil, err
+		}
+
+		if err := syncOrderCarriers(ctx, tx, orderID, droneID, to, update.UpdatedByID); err != nil {
+			r.logger.Error("Failed to record order carrier", "orderID", orderID, "error", err)
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", "error", err)
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// updateOrderArgs returns the parameters of updateOrderQuery
+func updateOrderArgs(orderID string, update *domain.UpdateOrderRequest) []interface{} {
+	return []interface{}{
+		orderID,
+		update.ReceiverName,
+		update.ReceiverPhone,
+		update.DeliveryNote,
+		update.PackageWeightKg,
+		update.OriginAddress,
+		update.OriginLat,
+		update.OriginLon,
+		update.DestinationAddress,
+		update.DestinationLat,
+		update.DestinationLon,
+		update.Status,
+		update.ScheduledAt,
+		update.DeliveredAt,
+		update.CancelledAt,
+		update.DeliveredByDroneID,
+		update.UpdatedByID,
+		update.WithdrawnAt,
+		update.CurrentLat,
+		update.CurrentLon,
+		update.CurrentAltitude,
+		update.LastLocationUpdateAt,
+		update.EstimatedArrivalAt,
+		update.DroneID,
+		update.ExpectedVersion,
+	}
+}
+
 // DeleteOrder permanently deletes an order from the repository
 func (r *OrdersRepositoryImpl) DeleteOrder(ctx context.Context, orderID string) error {
 	var result sql.Result
@@ -524,6 +702,18 @@ func (r *OrdersRepositoryImpl) applyOrderFilters(baseQuery string, filter *domai
 		args = append(args, *filter.Status)
 	}
 
+	if len(filter.Statuses) > 0 {
+		paramCount++
+		query += fmt.Sprintf(" AND status = ANY($%d::VARCHAR[])", paramCount)
+		args = append(args, pq.Array(filter.Statuses))
+	}
+
+	if filter.Priority != nil && *filter.Priority != "" {
+		paramCount++
+		query += fmt.Sprintf(" AND priority = $%d", paramCount)
+		args = append(args, *filter.Priority)
+	}
+
 	if filter.DroneID != nil && *filter.DroneID != "" && filter.DeliveredByDroneID != nil && *filter.DeliveredByDroneID != "" && filter.DeliveredByDroneID == filter.DroneID {
 		// Combaine two statements with ord
 		paramCount++
@@ -630,12 +820,20 @@ func (r *OrdersRepositoryImpl) ListOrders(ctx context.Context, options domain.Pa
 			package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
 			destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
 			delivered_by_drone_id, drone_id, withdrawn_at, current_lat, current_lon, current_altitude,
-			last_location_update_at, estimated_arrival_at, created_at, updated_at, active
+			last_location_update_at, estimated_arrival_at, cancellation_reason, cancellation_note, cancelled_by_id,
+			price, currency, quote_id, priority, required_capabilities, version,
+			delivery_attempts, max_delivery_attempts, failure_action, service_area_id,
+			created_at, updated_at, active
 		FROM orders
 		WHERE active = TRUE`, filter, 0)
 
 
-	query += " ORDER BY created_at DESC"
+	// Upcoming scheduled orders are listed soonest first
+	if filter != nil && (filter.ScheduledAtFrom != nil || filter.ScheduledAtTo != nil) {
+		query += " ORDER BY scheduled_at ASC"
+	} else {
+		query += " ORDER BY created_at DESC"
+	}
 
 	// Add limit and offset
 	paramCount++
@@ -692,7 +890,10 @@ func (r *OrdersRepositoryImpl) GetByOrderNumber(ctx context.Context, orderNumber
 				package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
 				destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
 				delivered_by_drone_id, drone_id, withdrawn_at, current_lat, current_lon, current_altitude,
-				last_location_update_at, estimated_arrival_at, created_at, updated_at, active
+				last_location_update_at, estimated_arrival_at, cancellation_reason, cancellation_note, cancelled_by_id,
+				price, currency, quote_id, priority, required_capabilities, version,
+				delivery_attempts, max_delivery_attempts, failure_action, service_area_id,
+				created_at, updated_at, active
 			FROM orders
 			WHERE order_number = $1 AND active = TRUE`, orderNumber))
 	}
@@ -727,7 +928,10 @@ func (r *OrdersRepositoryImpl) UpdateStatus(ctx context.Context, orderID string,
 				package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
 				destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
 				delivered_by_drone_id, drone_id, withdrawn_at, current_lat, current_lon, current_altitude,
-				last_location_update_at, estimated_arrival_at, created_at, updated_at, active`, orderID, status, updatedByID))
+				last_location_update_at, estimated_arrival_at, cancellation_reason, cancellation_note, cancelled_by_id,
+				price, currency, quote_id, priority, required_capabilities, version,
+				delivery_attempts, max_delivery_attempts, failure_action, service_area_id,
+				created_at, updated_at, active`, orderID, status, updatedByID))
 	}
 
 	if err != nil {
@@ -755,7 +959,10 @@ func (r *OrdersRepositoryImpl) GetByUserID(ctx context.Context, userID string) (
 				package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
 				destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
 				delivered_by_drone_id, drone_id, withdrawn_at, current_lat, current_lon, current_altitude,
-				last_location_update_at, estimated_arrival_at, created_at, updated_at, active
+				last_location_update_at, estimated_arrival_at, cancellation_reason, cancellation_note, cancelled_by_id,
+				price, currency, quote_id, priority, required_capabilities, version,
+				delivery_attempts, max_delivery_attempts, failure_action, service_area_id,
+				created_at, updated_at, active
 			FROM orders
 			WHERE user_id = $1 AND active = TRUE
 			ORDER BY created_at DESC`, userID)
@@ -793,7 +1000,10 @@ func (r *OrdersRepositoryImpl) GetOrdersByStatus(ctx context.Context, status str
 				package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
 				destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
 				delivered_by_drone_id, drone_id, withdrawn_at, current_lat, current_lon, current_altitude,
-				last_location_update_at, estimated_arrival_at, created_at, updated_at, active
+				last_location_update_at, estimated_arrival_at, cancellation_reason, cancellation_note, cancelled_by_id,
+				price, currency, quote_id, priority, required_capabilities, version,
+				delivery_attempts, max_delivery_attempts, failure_action, service_area_id,
+				created_at, updated_at, active
 			FROM orders
 			WHERE active = TRUE AND status = $1
 			ORDER BY updated_at DESC`, status)
@@ -856,7 +1066,10 @@ func (r *OrdersRepositoryImpl) GetOrderByFilter(ctx context.Context, options dom
 			package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
 			destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
 			delivered_by_drone_id, drone_id, withdrawn_at, current_lat, current_lon, current_altitude,
-			last_location_update_at, estimated_arrival_at, created_at, updated_at, active
+			last_location_update_at, estimated_arrival_at, cancellation_reason, cancellation_note, cancelled_by_id,
+			price, currency, quote_id, priority, required_capabilities, version,
+			delivery_attempts, max_delivery_attempts, failure_action, service_area_id,
+			created_at, updated_at, active
 		FROM orders
 		WHERE active = TRUE`
 
@@ -893,11 +1106,21 @@ func (r *OrdersRepositoryImpl) UpdateOrderStatus(ctx context.Context, orderID st
 		r.logger.Error("Failed to begin transaction", "error", err)
 		return nil, err
 	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
+	defer tx.Rollback()
+
+	// Lock the order and check the transition against the state machine
+	from, err := lockOrderStatus(ctx, tx, orderID)
+	if err != nil {
+		if err == domain.ErrOrderNotFound {
+			r.logger.Warn("Order not found for status update", "orderID", orderID)
+		} else {
+			r.logger.Error("Failed to lock order for status update", "orderID", orderID, "error", err)
 		}
-	}()
+		return nil, err
+	}
+	if !status.IsTransitionAllowed(from) {
+		return nil, from.TransitionErr()
+	}
 
 	// Update order status
 	order, err := r.scanOrder(tx.QueryRowContext(ctx, `
@@ -905,14 +1128,21 @@ func (r *OrdersRepositoryImpl) UpdateOrderStatus(ctx context.Context, orderID st
 			status = $2::VARCHAR,
 			updated_by_id = $3,
 			drone_id = CASE 
-				WHEN $2::VARCHAR IN ('delivered') THEN NULL
+				WHEN $2::VARCHAR IN ('delivered', 'returned', 'pending', 'held_at_depot') THEN NULL
 				WHEN $2::VARCHAR IN ('reserved') AND $4::UUID IS NOT NULL THEN $4
 				ELSE COALESCE($4, drone_id)
 			END,
+			current_lat = CASE WHEN $2::VARCHAR = 'pending' THEN origin_lat ELSE current_lat END,
+			current_lon = CASE WHEN $2::VARCHAR = 'pending' THEN origin_lon ELSE current_lon END,
+			current_altitude = CASE WHEN $2::VARCHAR IN ('pending', 'held_at_depot') THEN NULL ELSE current_altitude END,
 			delivered_by_drone_id = CASE
 				WHEN $2::VARCHAR = 'delivered' THEN COALESCE($4, delivered_by_drone_id)
 				ELSE delivered_by_drone_id
 			END,
+			delivery_attempts = CASE
+				WHEN $2::VARCHAR = 'failed' THEN delivery_attempts + 1
+				ELSE delivery_attempts
+			END,
 			updated_at = NOW()
 		WHERE id = $1 AND active = TRUE
 		RETURNING
@@ -920,7 +1150,10 @@ func (r *OrdersRepositoryImpl) UpdateOrderStatus(ctx context.Context, orderID st
 			package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
 			destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
 			delivered_by_drone_id, drone_id, withdrawn_at, current_lat, current_lon, current_altitude,
-			last_location_update_at, estimated_arrival_at, created_at, updated_at, active`,
+			last_location_update_at, estimated_arrival_at, cancellation_reason, cancellation_note, cancelled_by_id,
+			price, currency, quote_id, priority, required_capabilities, version,
+			delivery_attempts, max_delivery_attempts, failure_action, service_area_id,
+			created_at, updated_at, active`,
 		orderID, status, updatedByID, droneID))
 
 	if err != nil {
@@ -937,7 +1170,7 @@ func (r *OrdersRepositoryImpl) UpdateOrderStatus(ctx context.Context, orderID st
 	shouldUpdateDrone := true
 
 	switch status {
-	case domain.OrderStatusReserved:
+	case domain.OrderStatusReserved, domain.OrderStatusReassigned:
 		droneStatus = domain.DroneStatusLoading
 	case domain.OrderStatusInTransit:
 		droneStatus = domain.DroneStatusDelivering
@@ -945,6 +1178,9 @@ func (r *OrdersRepositoryImpl) UpdateOrderStatus(ctx context.Context, orderID st
 		droneStatus = domain.DroneStatusReturning
 	case domain.OrderStatusFailed:
 		droneStatus = domain.DroneStatusReturning
+	case domain.OrderStatusReturned, domain.OrderStatusPending:
+		// The drone is back at the origin with the package
+		droneStatus = domain.DroneStatusIdle
 	default:
 		shouldUpdateDrone = false
 	}
@@ -965,6 +1201,32 @@ func (r *OrdersRepositoryImpl) UpdateOrderStatus(ctx context.Context, orderID st
 		}
 	}
 
+	// Record the transition
+	err = insertOrderStatusHistory(ctx, tx, domain.OrderStatusHistory{
+		OrderID:    orderID,
+		FromStatus: &from,
+		ToStatus:   status,
+		DroneID:    nullIfEmpty(droneID),
+		ActorID:    nullIfEmpty(updatedByID),
+		Reason:     options.Reason,
+	})
+	if err != nil {
+		r.logger.Error("Failed to record order status history", "orderID", orderID, "error", err)
+		return nil, err
+	}
+
+	if err := syncOrderCarriers(ctx, tx, orderID, nullIfEmpty(droneID), status, nullIfEmpty(updatedByID)); err != nil {
+		r.logger.Error("Failed to record order carrier", "orderID", orderID, "error", err)
+		return nil, err
+	}
+
+	if options.Proof != nil {
+		if err := saveDeliveryProof(ctx, tx, orderID, options.Proof, updatedByID); err != nil {
+			r.logger.Error("Failed to record delivery proof", "orderID", orderID, "error", err)
+			return nil, err
+		}
+	}
+
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		r.logger.Error("Failed to commit transaction", "error", err)
@@ -973,3 +1235,1178 @@ func (r *OrdersRepositoryImpl) UpdateOrderStatus(ctx context.Context, orderID st
 
 	return order, nil
 }
+
+// CancelOrder cancels an open order and releases its drone. A drone that has not picked
+// the package up yet goes back to idle, a drone carrying it is set to returning and gets a
+// return_to_origin command. Broken drones are left as they are.
+func (r *OrdersRepositoryImpl) CancelOrder(ctx context.Context, orderID string, cancelledByID string, request *domain.CancelOrderRequest) (*domain.OrderCancellation, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	from, err := lockOrderStatus(ctx, tx, orderID)
+	if err != nil {
+		if err == domain.ErrOrderNotFound {
+			r.logger.Warn("Order not found for cancellation", "orderID", orderID)
+		} else {
+			r.logger.Error("Failed to lock order for cancellation", "orderID", orderID, "error", err)
+		}
+		return nil, err
+	}
+	if !domain.OrderStatusCancelled.IsTransitionAllowed(from) {
+		return nil, from.TransitionErr()
+	}
+
+	// Remember the drone before it is detached from the order
+	var droneID *string
+	if err := tx.QueryRowContext(ctx, `SELECT drone_id FROM orders WHERE id = $1`, orderID).Scan(&droneID); err != nil {
+		r.logger.Error("Failed to read order drone", "orderID", orderID, "error", err)
+		return nil, err
+	}
+
+	cancelledAt := time.Now().UTC().Format(time.RFC3339)
+	order, err := r.scanOrder(tx.QueryRowContext(ctx, `
+		UPDATE orders SET
+			status = $2,
+			cancelled_at = $3,
+			cancellation_reason = $4,
+			cancellation_note = $5,
+			cancelled_by_id = $6,
+			updated_by_id = $6,
+			drone_id = NULL,
+			estimated_arrival_at = NULL,
+			updated_at = NOW()
+		WHERE id = $1 AND active = TRUE
+		RETURNING
+			id, order_number, user_id, receiver_name, receiver_phone, delivery_note,
+			package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
+			destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
+			delivered_by_drone_id, drone_id, withdrawn_at, current_lat, current_lon, current_altitude,
+			last_location_update_at, estimated_arrival_at, cancellation_reason, cancellation_note, cancelled_by_id,
+			price, currency, quote_id, priority, required_capabilities, version,
+			delivery_attempts, max_delivery_attempts, failure_action, service_area_id,
+			created_at, updated_at, active`,
+		orderID,
+		domain.OrderStatusCancelled,
+		cancelledAt,
+		request.ReasonCode,
+		request.Note,
+		cancelledByID,
+	))
+	if err != nil {
+		r.logger.Error("Failed to cancel order", "orderID", orderID, "error", err)
+		return nil, err
+	}
+
+	result := &domain.OrderCancellation{
+		Order:          order,
+		PreviousStatus: from,
+	}
+
+	if droneID != nil {
+		packageOnBoard := from.CarriesPackage()
+
+		releasedStatus := domain.DroneStatusIdle
+		if packageOnBoard {
+			releasedStatus = domain.DroneStatusReturning
+		}
+
+		res, err := tx.ExecContext(ctx, `
+			UPDATE drones SET
+				status = $2,
+				updated_by_id = $3,
+				updated_at = NOW()
+			WHERE id = $1 AND active = TRUE AND status = ANY($4::VARCHAR[])`,
+			*droneID,
+			releasedStatus,
+			cancelledByID,
+			pq.Array([]domain.DroneStatus{domain.DroneStatusLoading, domain.DroneStatusDelivering, domain.DroneStatusReturning}),
+		)
+		if err != nil {
+			r.logger.Error("Failed to release drone", "droneID", *droneID, "orderID", orderID, "error", err)
+			return nil, err
+		}
+
+		if released, _ := res.RowsAffected(); released > 0 {
+			result.DroneID = droneID
+
+			if packageOnBoard {
+				note := "Order cancelled, return the package to the origin"
+				result.Command, err = insertDroneCommand(ctx, tx, domain.DroneCommand{
+					DroneID:     *droneID,
+					OrderID:     &order.ID,
+					Command:     domain.DroneCommandReturnToOrigin,
+					Lat:         &order.OriginLat,
+					Lon:         &order.OriginLon,
+					Note:        &note,
+					CreatedByID: &cancelledByID,
+				})
+				if err != nil {
+					r.logger.Error("Failed to queue return to origin command", "droneID", *droneID, "orderID", orderID, "error", err)
+					return nil, err
+				}
+			}
+		}
+	}
+
+	reason := string(request.ReasonCode)
+	err = insertOrderStatusHistory(ctx, tx, domain.OrderStatusHistory{
+		OrderID:    orderID,
+		FromStatus: &from,
+		ToStatus:   domain.OrderStatusCancelled,
+		DroneID:    droneID,
+		ActorID:    &cancelledByID,
+		Reason:     &reason,
+	})
+	if err != nil {
+		r.logger.Error("Failed to record order status history", "orderID", orderID, "error", err)
+		return nil, err
+	}
+
+	if err := syncOrderCarriers(ctx, tx, orderID, droneID, domain.OrderStatusCancelled, &cancelledByID); err != nil {
+		r.logger.Error("Failed to record order carrier", "orderID", orderID, "error", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", "error", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// HandoffOrder puts an order up for a rescue drone. The drone is detached and its last known
+// position becomes the pickup point of the order.
+func (r *OrdersRepositoryImpl) HandoffOrder(ctx context.Context, orderID string, updatedByID string, reason string) (*domain.Order, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	from, err := lockOrderStatus(ctx, tx, orderID)
+	if err != nil {
+		if err == domain.ErrOrderNotFound {
+			r.logger.Warn("Order not found for handoff", "orderID", orderID)
+		} else {
+			r.logger.Error("Failed to lock order for handoff", "orderID", orderID, "error", err)
+		}
+		return nil, err
+	}
+	if !domain.OrderStatusHandoff.IsTransitionAllowed(from) {
+		return nil, from.TransitionErr()
+	}
+
+	// Remember the drone before it is detached from the order
+	var droneID *string
+	if err := tx.QueryRowContext(ctx, `SELECT drone_id FROM orders WHERE id = $1`, orderID).Scan(&droneID); err != nil {
+		r.logger.Error("Failed to read order drone", "orderID", orderID, "error", err)
+		return nil, err
+	}
+
+	order, err := r.scanOrder(tx.QueryRowContext(ctx, `
+		UPDATE orders o SET
+			status = $2,
+			current_lat = COALESCE(d.current_lat, o.current_lat),
+			current_lon = COALESCE(d.current_lon, o.current_lon),
+			current_altitude = COALESCE(d.current_altitude, o.current_altitude),
+			last_location_update_at = COALESCE(d.last_location_update_at, o.last_location_update_at),
+			drone_id = NULL,
+			estimated_arrival_at = NULL,
+			updated_by_id = $3,
+			updated_at = NOW()
+		FROM orders src
+		LEFT JOIN drones d ON d.id = src.drone_id
+		WHERE o.id = src.id AND o.id = $1 AND o.active = TRUE
+		RETURNING
+			o.id, o.order_number, o.user_id, o.receiver_name, o.receiver_phone, o.delivery_note,
+			o.package_weight_kg, o.origin_address, o.origin_lat, o.origin_lon, o.destination_address,
+			o.destination_lat, o.destination_lon, o.status, o.scheduled_at, o.delivered_at, o.cancelled_at,
+			o.delivered_by_drone_id, o.drone_id, o.withdrawn_at, o.current_lat, o.current_lon, o.current_altitude,
+			o.last_location_update_at, o.estimated_arrival_at, o.cancellation_reason, o.cancellation_note, o.cancelled_by_id,
+			o.price, o.currency, o.quote_id, o.priority, o.required_capabilities, o.version,
+			o.delivery_attempts, o.max_delivery_attempts, o.failure_action, o.service_area_id,
+			o.created_at, o.updated_at, o.active`,
+		orderID,
+		domain.OrderStatusHandoff,
+		nullIfEmpty(updatedByID),
+	))
+	if err != nil {
+		r.logger.Error("Failed to hand off order", "orderID", orderID, "error", err)
+		return nil, err
+	}
+
+	err = insertOrderStatusHistory(ctx, tx, domain.OrderStatusHistory{
+		OrderID:    orderID,
+		FromStatus: &from,
+		ToStatus:   domain.OrderStatusHandoff,
+		DroneID:    droneID,
+		ActorID:    nullIfEmpty(updatedByID),
+		Reason:     nullIfEmpty(reason),
+	})
+	if err != nil {
+		r.logger.Error("Failed to record order status history", "orderID", orderID, "error", err)
+		return nil, err
+	}
+
+	if err := syncOrderCarriers(ctx, tx, orderID, droneID, domain.OrderStatusHandoff, nullIfEmpty(updatedByID)); err != nil {
+		r.logger.Error("Failed to record order carrier", "orderID", orderID, "error", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", "error", err)
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// ResolveDeliveryFailure records a failed attempt and moves the order on as decided by the failure
+// policy in one transaction, then queues the matching command for the drone. An order left failed
+// by an earlier attempt is only moved on. The package stays on board, so the order stays with its
+// drone until the drone confirms the drop-off at the origin or the depot.
+func (r *OrdersRepositoryImpl) ResolveDeliveryFailure(ctx context.Context, orderID string, resolution domain.DeliveryFailureResolution) (*domain.DeliveryFailureOutcome, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	from, err := lockOrderStatus(ctx, tx, orderID)
+	if err != nil {
+		if err == domain.ErrOrderNotFound {
+			r.logger.Warn("Order not found for delivery failure", "orderID", orderID)
+		} else {
+			r.logger.Error("Failed to lock order for delivery failure", "orderID", orderID, "error", err)
+		}
+		return nil, err
+	}
+	if from != domain.OrderStatusFailed && !domain.OrderStatusFailed.IsTransitionAllowed(from) {
+		return nil, from.TransitionErr()
+	}
+
+	var droneID *string
+	var originLat, originLon float64
+	var version int
+	if err := tx.QueryRowContext(ctx, `SELECT drone_id, origin_lat, origin_lon, version FROM orders WHERE id = $1`, orderID).Scan(&droneID, &originLat, &originLon, &version); err != nil {
+		r.logger.Error("Failed to read order drone", "orderID", orderID, "error", err)
+		return nil, err
+	}
+	// The policy decided on the order as it was read
+	if resolution.ExpectedVersion != nil && *resolution.ExpectedVersion != version {
+		r.logger.Warn("Order version moved on before delivery failure", "orderID", orderID, "expectedVersion", *resolution.ExpectedVersion)
+		return nil, domain.ErrOrderVersionConflict
+	}
+	if droneID == nil {
+		droneID = nullIfEmpty(resolution.DroneID)
+	}
+
+	// Record the failed attempt, the drone heads back with the package
+	if from != domain.OrderStatusFailed {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE orders SET
+				status = $2,
+				drone_id = $3,
+				delivery_attempts = delivery_attempts + 1,
+				updated_by_id = $4,
+				updated_at = NOW()
+			WHERE id = $1 AND active = TRUE`,
+			orderID, domain.OrderStatusFailed, droneID, nullIfEmpty(resolution.UpdatedByID))
+		if err != nil {
+			r.logger.Error("Failed to record failed delivery", "orderID", orderID, "error", err)
+			return nil, err
+		}
+
+		if droneID != nil {
+			_, err = tx.ExecContext(ctx, `
+				UPDATE drones SET
+					status = $2,
+					updated_by_id = $3,
+					updated_at = NOW()
+				WHERE id = $1 AND active = TRUE`,
+				*droneID, domain.DroneStatusReturning, nullIfEmpty(resolution.UpdatedByID))
+			if err != nil {
+				r.logger.Error("Failed to update drone status", "droneID", *droneID, "status", domain.DroneStatusReturning, "error", err)
+				return nil, err
+			}
+		}
+
+		err = insertOrderStatusHistory(ctx, tx, domain.OrderStatusHistory{
+			OrderID:    orderID,
+			FromStatus: &from,
+			ToStatus:   domain.OrderStatusFailed,
+			DroneID:    droneID,
+			ActorID:    nullIfEmpty(resolution.UpdatedByID),
+			Reason:     resolution.Reason,
+		})
+		if err != nil {
+			r.logger.Error("Failed to record order status history", "orderID", orderID, "error", err)
+			return nil, err
+		}
+		from = domain.OrderStatusFailed
+	}
+
+	to := resolution.Action.Status()
+	if !to.IsTransitionAllowed(from) {
+		return nil, from.TransitionErr()
+	}
+
+	// Where the drone takes the package
+	command := domain.DroneCommand{
+		OrderID:     &orderID,
+		Command:     domain.DroneCommandReturnToOrigin,
+		Lat:         &originLat,
+		Lon:         &originLon,
+		CreatedByID: nullIfEmpty(resolution.UpdatedByID),
+	}
+	var note string
+	switch resolution.Action {
+	case domain.DeliveryFailureActionRequeue:
+		// The order is re-queued once the drone confirms the package is back
+		note = "Delivery failed, return the package to the origin for another attempt"
+	case domain.DeliveryFailureActionReturnToSender:
+		note = "Delivery failed, return the package to the sender"
+	case domain.DeliveryFailureActionHoldAtDepot:
+		if resolution.Depot == nil {
+			return nil, domain.ErrDepotNotConfigured
+		}
+		note = "Delivery failed, leave the package at the depot: " + resolution.Depot.Address
+		command.Command = domain.DroneCommandDeliverToDepot
+		command.Lat = &resolution.Depot.Lat
+		command.Lon = &resolution.Depot.Lon
+	default:
+		return nil, domain.ErrInvalidDeliveryFailureAction
+	}
+	command.Note = &note
+
+	order, err := r.scanOrder(tx.QueryRowContext(ctx, `
+		UPDATE orders SET
+			status = $2,
+			failure_action = $3,
+			estimated_arrival_at = NULL,
+			updated_by_id = $4,
+			updated_at = NOW()
+		WHERE id = $1 AND active = TRUE
+		RETURNING
+			id, order_number, user_id, receiver_name, receiver_phone, delivery_note,
+			package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
+			destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
+			delivered_by_drone_id, drone_id, withdrawn_at, current_lat, current_lon, current_altitude,
+			last_location_update_at, estimated_arrival_at, cancellation_reason, cancellation_note, cancelled_by_id,
+			price, currency, quote_id, priority, required_capabilities, version,
+			delivery_attempts, max_delivery_attempts, failure_action, service_area_id,
+			created_at, updated_at, active`,
+		orderID,
+		to,
+		resolution.Action,
+		nullIfEmpty(resolution.UpdatedByID),
+	))
+	if err != nil {
+		r.logger.Error("Failed to resolve delivery failure", "orderID", orderID, "error", err)
+		return nil, err
+	}
+
+	outcome := &domain.DeliveryFailureOutcome{
+		Order:  order,
+		Action: resolution.Action,
+	}
+
+	if droneID != nil {
+		command.DroneID = *droneID
+		outcome.Command, err = insertDroneCommand(ctx, tx, command)
+		if err != nil {
+			r.logger.Error("Failed to queue delivery failure command", "droneID", *droneID, "orderID", orderID, "error", err)
+			return nil, err
+		}
+	}
+
+	reason := string(resolution.Action)
+	err = insertOrderStatusHistory(ctx, tx, domain.OrderStatusHistory{
+		OrderID:    orderID,
+		FromStatus: &from,
+		ToStatus:   to,
+		DroneID:    droneID,
+		ActorID:    nullIfEmpty(resolution.UpdatedByID),
+		Reason:     &reason,
+	})
+	if err != nil {
+		r.logger.Error("Failed to record order status history", "orderID", orderID, "error", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", "error", err)
+		return nil, err
+	}
+
+	return outcome, nil
+}
+
+// ChangeOrderRoute writes the new route and price of an order, queues the point the assigned
+// drone now flies to and records the old and new route in the audit log
+func (r *OrdersRepositoryImpl) ChangeOrderRoute(ctx context.Context, orderID string, change domain.RouteChange) (*domain.RouteChangeOutcome, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	status, err := lockOrderStatus(ctx, tx, orderID)
+	if err != nil {
+		if err == domain.ErrOrderNotFound {
+			r.logger.Warn("Order not found for route change", "orderID", orderID)
+		} else {
+			r.logger.Error("Failed to lock order for route change", "orderID", orderID, "error", err)
+		}
+		return nil, err
+	}
+	// The drone may have picked the package up since the service checked
+	if status.IsFinal() {
+		return nil, domain.ErrRouteChangeNotAllowed
+	}
+	if change.ChangesOrigin && !status.IsBeforePickup() {
+		return nil, domain.ErrOriginChangeAfterPickup
+	}
+
+	current, err := r.scanOrder(tx.QueryRowContext(ctx, `
+		SELECT
+			id, order_number, user_id, receiver_name, receiver_phone, delivery_note,
+			package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
+			destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
+			delivered_by_drone_id, drone_id, withdrawn_at, current_lat, current_lon, current_altitude,
+			last_location_update_at, estimated_arrival_at, cancellation_reason, cancellation_note, cancelled_by_id,
+			price, currency, quote_id, priority, required_capabilities, version,
+			delivery_attempts, max_delivery_attempts, failure_action, service_area_id,
+			created_at, updated_at, active
+		FROM orders
+		WHERE id = $1`, orderID))
+	if err != nil {
+		r.logger.Error("Failed to read order route", "orderID", orderID, "error", err)
+		return nil, err
+	}
+	if change.ExpectedVersion != nil && *change.ExpectedVersion != current.Version {
+		r.logger.Warn("Order version moved on before route change", "orderID", orderID, "expectedVersion", *change.ExpectedVersion)
+		return nil, domain.ErrOrderVersionConflict
+	}
+
+	order, err := r.scanOrder(tx.QueryRowContext(ctx, `
+		UPDATE orders SET
+			origin_address = $2,
+			origin_lat = $3,
+			origin_lon = $4,
+			destination_address = $5,
+			destination_lat = $6,
+			destination_lon = $7,
+			price = COALESCE($8, price),
+			estimated_arrival_at = NULL,
+			updated_by_id = $9,
+			updated_at = NOW()
+		WHERE id = $1 AND active = TRUE
+		RETURNING
+			id, order_number, user_id, receiver_name, receiver_phone, delivery_note,
+			package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
+			destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
+			delivered_by_drone_id, drone_id, withdrawn_at, current_lat, current_lon, current_altitude,
+			last_location_update_at, estimated_arrival_at, cancellation_reason, cancellation_note, cancelled_by_id,
+			price, currency, quote_id, priority, required_capabilities, version,
+			delivery_attempts, max_delivery_attempts, failure_action, service_area_id,
+			created_at, updated_at, active`,
+		orderID,
+		change.Route.OriginAddress,
+		change.Route.OriginLat,
+		change.Route.OriginLon,
+		change.Route.DestinationAddress,
+		change.Route.DestinationLat,
+		change.Route.DestinationLon,
+		change.Route.Price,
+		nullIfEmpty(change.ChangedByID),
+	))
+	if err != nil {
+		r.logger.Error("Failed to change order route", "orderID", orderID, "error", err)
+		return nil, err
+	}
+
+	outcome := &domain.RouteChangeOutcome{
+		Order:    order,
+		Previous: current.Route(),
+	}
+
+	// Before pickup the drone flies to the origin, after it to the destination
+	if order.DroneID != nil {
+		command := domain.DroneCommand{
+			DroneID:     *order.DroneID,
+			OrderID:     &orderID,
+			Command:     domain.DroneCommandNewDestination,
+			CreatedByID: nullIfEmpty(change.ChangedByID),
+		}
+		var note string
+		switch {
+		case status.IsBeforePickup() && change.ChangesOrigin:
+			note = "Route changed, pick the package up at: " + order.OriginAddress
+			command.Lat, command.Lon = &order.OriginLat, &order.OriginLon
+		case !status.IsBeforePickup() && change.ChangesDestination:
+			note = "Route changed, deliver the package to: " + order.DestinationAddress
+			command.Lat, command.Lon = &order.DestinationLat, &order.DestinationLon
+		}
+		if note != "" {
+			command.Note = &note
+			outcome.Command, err = insertDroneCommand(ctx, tx, command)
+			if err != nil {
+				r.logger.Error("Failed to queue route change command", "droneID", *order.DroneID, "orderID", orderID, "error", err)
+				return nil, err
+			}
+		}
+	}
+
+	err = insertAuditLog(ctx, tx, domain.CreateAuditLogRequest{
+		PerformedBy:  change.ChangedByID,
+		UserType:     string(change.UserType),
+		Action:       domain.AuditActionChangeRoute,
+		ResourceName: "orders",
+		ResourceID:   orderID,
+		OldData:      outcome.Previous,
+		NewData: struct {
+			domain.OrderRoute
+			Reason *string `json:"reason,omitempty"`
+		}{change.Route, change.Reason},
+	})
+	if err != nil {
+		r.logger.Error("Failed to record route change audit log", "orderID", orderID, "error", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", "error", err)
+		return nil, err
+	}
+
+	return outcome, nil
+}
+
+// ListOrderCarriers retrieves every drone that carried an order, in pickup order
+func (r *OrdersRepositoryImpl) ListOrderCarriers(ctx context.Context, orderID string) ([]*domain.OrderCarrier, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			c.id, c.order_id, c.drone_id, d.drone_identifier,
+			c.pickup_lat, c.pickup_lon, c.picked_up_at,
+			c.release_lat, c.release_lon, c.released_at, c.release_status
+		FROM order_carriers c
+		LEFT JOIN drones d ON d.id = c.drone_id
+		WHERE c.order_id = $1 AND c.active = TRUE
+		ORDER BY c.picked_up_at ASC`, orderID)
+	if err != nil {
+		r.logger.Error("Failed to list order carriers", "orderID", orderID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var carriers []*domain.OrderCarrier
+	for rows.Next() {
+		var carrier domain.OrderCarrier
+		if err := rows.Scan(
+			&carrier.ID,
+			&carrier.OrderID,
+			&carrier.DroneID,
+			&carrier.DroneIdentifier,
+			&carrier.PickupLat,
+			&carrier.PickupLon,
+			&carrier.PickedUpAt,
+			&carrier.ReleaseLat,
+			&carrier.ReleaseLon,
+			&carrier.ReleasedAt,
+			&carrier.ReleaseStatus,
+		); err != nil {
+			r.logger.Error("Failed to scan order carrier", "orderID", orderID, "error", err)
+			return nil, err
+		}
+		carriers = append(carriers, &carrier)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Failed to iterate order carriers", "orderID", orderID, "error", err)
+		return nil, err
+	}
+
+	return carriers, nil
+}
+
+// ListPendingOrders retrieves pending orders that have no drone yet, most urgent first.
+// Urgency is the waiting time plus the priority head start, so higher priorities
+// jump the queue while old standard orders still move up over time.
+func (r *OrdersRepositoryImpl) ListPendingOrders(ctx context.Context, limit int) ([]*domain.Order, error) {
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT
+			id, order_number, user_id, receiver_name, receiver_phone, delivery_note,
+			package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
+			destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
+			delivered_by_drone_id, drone_id, withdrawn_at, current_lat, current_lon, current_altitude,
+			last_location_update_at, estimated_arrival_at, cancellation_reason, cancellation_note, cancelled_by_id,
+			price, currency, quote_id, priority, required_capabilities, version,
+			delivery_attempts, max_delivery_attempts, failure_action, service_area_id,
+			created_at, updated_at, active
+		FROM orders
+		WHERE active = TRUE AND status = $1 AND drone_id IS NULL
+		ORDER BY %s + (EXTRACT(EPOCH FROM (NOW() - created_at)) / 60) * $3 DESC, created_at ASC
+		LIMIT $2`, priorityHeadStartSQL("priority")),
+		domain.OrderStatusPending, limit, domain.JobAgeWeightKmPerMinute)
+	if err != nil {
+		r.logger.Error("Failed to list pending orders", "limit", limit, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []*domain.Order
+	for rows.Next() {
+		order, err := r.scanOrder(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan pending order row", "error", err)
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, rows.Err()
+}
+
+// AssignOrder reserves a pending order for a drone and marks the drone as loading.
+// The order must still be pending and unassigned, and the drone must still be idle,
+// otherwise nothing is changed so concurrent dispatchers cannot double-book.
+func (r *OrdersRepositoryImpl) AssignOrder(ctx context.Context, orderID string, droneID string) (*domain.Order, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	order, err := r.scanOrder(tx.QueryRowContext(ctx, `
+		UPDATE orders SET
+			status = $3,
+			drone_id = $2,
+			updated_at = NOW()
+		WHERE id = $1 AND status = $4 AND drone_id IS NULL AND active = TRUE
+		RETURNING
+			id, order_number, user_id, receiver_name, receiver_phone, delivery_note,
+			package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
+			destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
+			delivered_by_drone_id, drone_id, withdrawn_at, current_lat, current_lon, current_altitude,
+			last_location_update_at, estimated_arrival_at, cancellation_reason, cancellation_note, cancelled_by_id,
+			price, currency, quote_id, priority, required_capabilities, version,
+			delivery_attempts, max_delivery_attempts, failure_action, service_area_id,
+			created_at, updated_at, active`,
+		orderID, droneID, domain.OrderStatusReserved, domain.OrderStatusPending))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.logger.Warn("Order no longer available for assignment", "orderID", orderID)
+			return nil, domain.ErrAlreadyReserved
+		}
+		r.logger.Error("Failed to assign order", "orderID", orderID, "droneID", droneID, "error", err)
+		return nil, err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE drones SET
+			status = $2,
+			updated_at = NOW()
+		WHERE id = $1 AND status = $3 AND active = TRUE`,
+		droneID, domain.DroneStatusLoading, domain.DroneStatusIdle)
+	if err != nil {
+		r.logger.Error("Failed to update drone status", "droneID", droneID, "error", err)
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error("Failed to get rows affected", "droneID", droneID, "error", err)
+		return nil, err
+	}
+	if rowsAffected == 0 {
+		r.logger.Warn("Drone no longer idle for assignment", "droneID", droneID)
+		return nil, domain.ErrDroneMustBeIdle
+	}
+
+	from := domain.OrderStatusPending
+	reason := domain.OrderStatusReasonAutoDispatch
+	err = insertOrderStatusHistory(ctx, tx, domain.OrderStatusHistory{
+		OrderID:    orderID,
+		FromStatus: &from,
+		ToStatus:   order.Status,
+		DroneID:    &droneID,
+		Reason:     &reason,
+	})
+	if err != nil {
+		r.logger.Error("Failed to record order status history", "orderID", orderID, "error", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", "error", err)
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// ListAvailableOrders retrieves reservable orders around a position.
+// Pending unassigned orders are picked up at their origin, handoff orders at the last
+// known position of the order. Orders too heavy for the drone, needing equipment the
+// drone is not fitted with, outside the radius or beyond the drone range (pickup plus
+// trip) are left out. Results are ranked by
+// distance, with older orders moved up by JobAgeWeightKmPerMinute and higher
+// priorities by their OrderPriorityHeadStartKm.
+func (r *OrdersRepositoryImpl) ListAvailableOrders(ctx context.Context, query domain.AvailableJobsQuery) ([]*domain.AvailableJob, error) {
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT
+			id, order_number, user_id, receiver_name, receiver_phone, delivery_note,
+			package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
+			destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
+			delivered_by_drone_id, drone_id, withdrawn_at, current_lat, current_lon, current_altitude,
+			last_location_update_at, estimated_arrival_at, cancellation_reason, cancellation_note, cancelled_by_id,
+			price, currency, quote_id, priority, required_capabilities, version,
+			delivery_attempts, max_delivery_attempts, failure_action, service_area_id,
+			created_at, updated_at, active,
+			pickup_lat, pickup_lon, distance_km, trip_km, waiting_minutes
+		FROM (
+			SELECT jobs.*, %s AS distance_km, %s AS trip_km
+			FROM (
+				SELECT
+					orders.*,
+					CASE WHEN status = $3 THEN COALESCE(current_lat, origin_lat) ELSE origin_lat END AS pickup_lat,
+					CASE WHEN status = $3 THEN COALESCE(current_lon, origin_lon) ELSE origin_lon END AS pickup_lon,
+					EXTRACT(EPOCH FROM (NOW() - created_at)) / 60 AS waiting_minutes
+				FROM orders
+				WHERE active = TRUE
+					AND ((status = $4 AND drone_id IS NULL) OR status = $3)
+					AND (package_weight_kg IS NULL OR package_weight_kg <= $5)
+					AND required_capabilities <@ $10::VARCHAR[]
+			) AS jobs
+		) AS ranked
+		WHERE distance_km <= $6 AND distance_km + trip_km <= $7
+		ORDER BY distance_km - waiting_minutes * $8 - %s ASC
+		LIMIT $9`,
+		haversineSQL("$1", "$2", "pickup_lat", "pickup_lon"),
+		haversineSQL("pickup_lat", "pickup_lon", "destination_lat", "destination_lon"),
+		priorityHeadStartSQL("priority")),
+		query.Lat,
+		query.Lon,
+		domain.OrderStatusHandoff,
+		domain.OrderStatusPending,
+		query.MaxWeightKg,
+		query.RadiusKm,
+		query.MaxRangeKm,
+		domain.JobAgeWeightKmPerMinute,
+		query.Limit,
+		capabilitiesArray{&query.Capabilities},
+	)
+	if err != nil {
+		r.logger.Error("Failed to list available orders", "query", query, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []*domain.AvailableJob
+	for rows.Next() {
+		var job domain.AvailableJob
+		order, err := r.scanOrder(rows, &job.PickupLat, &job.PickupLon, &job.DistanceKm, &job.TripKm, &job.WaitingMinutes)
+		if err != nil {
+			r.logger.Error("Failed to scan available order row", "error", err)
+			return nil, err
+		}
+		job.Order = order.ToDTO()
+		job.IsHandoff = order.Status == domain.OrderStatusHandoff
+		jobs = append(jobs, &job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// haversineSQL builds the great-circle distance in km between two lat/lon SQL expressions
+// LEAST clamps the acos argument to avoid NaN from floating point error on identical points
+func haversineSQL(lat1, lon1, lat2, lon2 string) string {
+	return fmt.Sprintf(`(6371 * acos(LEAST(1.0,
+		cos(radians(%[1]s)) * cos(radians(%[3]s)) *
+		cos(radians(%[4]s) - radians(%[2]s)) +
+		sin(radians(%[1]s)) * sin(radians(%[3]s))
+	)))`, lat1, lon1, lat2, lon2)
+}
+
+// priorityHeadStartSQL builds the OrderPriorityHeadStartKm of a priority SQL column,
+// unknown priorities get no head start
+func priorityHeadStartSQL(column string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "(CASE %s", column)
+	for _, priority := range domain.OrderPriorities {
+		fmt.Fprintf(&b, " WHEN '%s' THEN %g", priority, domain.OrderPriorityHeadStartKm[priority])
+	}
+	b.WriteString(" ELSE 0 END)")
+	return b.String()
+}
+
+// ClaimNextOrder picks the best pending or handoff order reachable by the drone and reserves it.
+// Pending orders become reserved, handoff orders become reassigned to the rescuing drone.
+// The drone row is locked first so the same drone cannot claim twice, and candidate
+// orders are locked with SKIP LOCKED so concurrent drones never wait on, or take,
+// the same order. Ranking matches ListAvailableOrders.
+func (r *OrdersRepositoryImpl) ClaimNextOrder(ctx context.Context, droneID string, updatedByID string, query domain.AvailableJobsQuery) (*domain.Order, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock the drone and make sure it is still idle
+	var droneStatus domain.DroneStatus
+	err = tx.QueryRowContext(ctx, `
+		SELECT status FROM drones
+		WHERE id = $1 AND active = TRUE
+		FOR UPDATE`, droneID).Scan(&droneStatus)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrDroneNotFound
+		}
+		r.logger.Error("Failed to lock drone for claim", "droneID", droneID, "error", err)
+		return nil, err
+	}
+	if droneStatus != domain.DroneStatusIdle {
+		return nil, droneStatus.GetErr()
+	}
+
+	// Pick the best job, skipping rows other drones are claiming. Handoff orders are
+	// picked up where the previous drone left them.
+	pickupLat := "(CASE WHEN status = $8 THEN COALESCE(current_lat, origin_lat) ELSE origin_lat END)"
+	pickupLon := "(CASE WHEN status = $8 THEN COALESCE(current_lon, origin_lon) ELSE origin_lon END)"
+	distance := haversineSQL("$1", "$2", pickupLat, pickupLon)
+	trip := haversineSQL(pickupLat, pickupLon, "destination_lat", "destination_lon")
+
+	var orderID string
+	var from domain.OrderStatus
+	err = tx.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT id, status FROM orders
+		WHERE active = TRUE
+			AND ((status = $3 AND drone_id IS NULL) OR status = $8)
+			AND (package_weight_kg IS NULL OR package_weight_kg <= $4)
+			AND required_capabilities <@ $9::VARCHAR[]
+			AND %[1]s <= $5
+			AND %[1]s + %[2]s <= $6
+		ORDER BY %[1]s - (EXTRACT(EPOCH FROM (NOW() - created_at)) / 60) * $7 - %[3]s ASC
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED`, distance, trip, priorityHeadStartSQL("priority")),
+		query.Lat,
+		query.Lon,
+		domain.OrderStatusPending,
+		query.MaxWeightKg,
+		query.RadiusKm,
+		query.MaxRangeKm,
+		domain.JobAgeWeightKmPerMinute,
+		domain.OrderStatusHandoff,
+		capabilitiesArray{&query.Capabilities},
+	).Scan(&orderID, &from)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNoJobAvailable
+		}
+		r.logger.Error("Failed to select order to claim", "droneID", droneID, "error", err)
+		return nil, err
+	}
+
+	to := domain.OrderStatusReserved
+	reason := domain.OrderStatusReasonClaimed
+	if from == domain.OrderStatusHandoff {
+		to = domain.OrderStatusReassigned
+		reason = domain.OrderStatusReasonRescue
+	}
+
+	order, err := r.scanOrder(tx.QueryRowContext(ctx, `
+		UPDATE orders SET
+			status = $3,
+			drone_id = $2,
+			updated_by_id = $4,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING
+			id, order_number, user_id, receiver_name, receiver_phone, delivery_note,
+			package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
+			destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
+			delivered_by_drone_id, drone_id, withdrawn_at, current_lat, current_lon, current_altitude,
+			last_location_update_at, estimated_arrival_at, cancellation_reason, cancellation_note, cancelled_by_id,
+			price, currency, quote_id, priority, required_capabilities, version,
+			delivery_attempts, max_delivery_attempts, failure_action, service_area_id,
+			created_at, updated_at, active`,
+		orderID, droneID, to, updatedByID))
+	if err != nil {
+		r.logger.Error("Failed to reserve claimed order", "orderID", orderID, "droneID", droneID, "error", err)
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE drones SET
+			status = $2,
+			updated_by_id = $3,
+			updated_at = NOW()
+		WHERE id = $1`,
+		droneID, domain.DroneStatusLoading, updatedByID)
+	if err != nil {
+		r.logger.Error("Failed to update drone status", "droneID", droneID, "error", err)
+		return nil, err
+	}
+
+	err = insertOrderStatusHistory(ctx, tx, domain.OrderStatusHistory{
+		OrderID:    orderID,
+		FromStatus: &from,
+		ToStatus:   order.Status,
+		DroneID:    &droneID,
+		ActorID:    nullIfEmpty(updatedByID),
+		Reason:     &reason,
+	})
+	if err != nil {
+		r.logger.Error("Failed to record order status history", "orderID", orderID, "error", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", "error", err)
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// ListOrderStatusHistory retrieves the recorded transitions of an order, oldest first
+func (r *OrdersRepositoryImpl) ListOrderStatusHistory(ctx context.Context, orderID string) ([]*domain.OrderStatusHistory, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			h.id, h.order_id, h.from_status, h.to_status, h.drone_id, d.drone_identifier,
+			h.actor_id, h.reason, h.lat, h.lon, h.altitude, h.created_at
+		FROM order_status_history h
+		LEFT JOIN drones d ON d.id = h.drone_id
+		WHERE h.order_id = $1 AND h.active = TRUE
+		ORDER BY h.created_at ASC`, orderID)
+	if err != nil {
+		r.logger.Error("Failed to list order status history", "orderID", orderID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []*domain.OrderStatusHistory
+	for rows.Next() {
+		var entry domain.OrderStatusHistory
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.OrderID,
+			&entry.FromStatus,
+			&entry.ToStatus,
+			&entry.DroneID,
+			&entry.DroneIdentifier,
+			&entry.ActorID,
+			&entry.Reason,
+			&entry.Lat,
+			&entry.Lon,
+			&entry.Altitude,
+			&entry.CreatedAt,
+		); err != nil {
+			r.logger.Error("Failed to scan order status history row", "orderID", orderID, "error", err)
+			return nil, err
+		}
+		history = append(history, &entry)
+	}
+
+	return history, rows.Err()
+}
+
+// ReleaseScheduledOrders moves scheduled orders due before releaseBefore to pending,
+// soonest first. Rows locked by a concurrent release are skipped.
+func (r *OrdersRepositoryImpl) ReleaseScheduledOrders(ctx context.Context, releaseBefore time.Time, limit int) ([]*domain.Order, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE orders SET
+			status = $2,
+			updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM orders
+			WHERE active = TRUE AND status = $3 AND scheduled_at <= $1::TIMESTAMP
+			ORDER BY scheduled_at ASC
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING
+			id, order_number, user_id, receiver_name, receiver_phone, delivery_note,
+			package_weight_kg, origin_address, origin_lat, origin_lon, destination_address,
+			destination_lat, destination_lon, status, scheduled_at, delivered_at, cancelled_at,
+			delivered_by_drone_id, drone_id, withdrawn_at, current_lat, current_lon, current_altitude,
+			last_location_update_at, estimated_arrival_at, cancellation_reason, cancellation_note, cancelled_by_id,
+			price, currency, quote_id, priority, required_capabilities, version,
+			delivery_attempts, max_delivery_attempts, failure_action, service_area_id,
+			created_at, updated_at, active`,
+		releaseBefore.UTC().Format("2006-01-02 15:04:05"),
+		domain.OrderStatusPending,
+		domain.OrderStatusScheduled,
+		limit,
+	)
+	if err != nil {
+		r.logger.Error("Failed to release scheduled orders", "error", err)
+		return nil, err
+	}
+
+	var orders []*domain.Order
+	for rows.Next() {
+		order, err := r.scanOrder(rows)
+		if err != nil {
+			rows.Close()
+			r.logger.Error("Failed to scan released order row", "error", err)
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	from := domain.OrderStatusScheduled
+	reason := domain.OrderStatusReasonReleased
+	for _, order := range orders {
+		err = insertOrderStatusHistory(ctx, tx, domain.OrderStatusHistory{
+			OrderID:    order.ID,
+			FromStatus: &from,
+			ToStatus:   order.Status,
+			Reason:     &reason,
+		})
+		if err != nil {
+			r.logger.Error("Failed to record order status history", "orderID", order.ID, "error", err)
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", "error", err)
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// ExpireReservations moves orders reserved before reservedBefore back to pending, oldest
+// order first, and resets their drones from loading to idle. The reservation time is
+// read from the status history. Rows locked by a concurrent pickup or expiry are skipped.
+func (r *OrdersRepositoryImpl) ExpireReservations(ctx context.Context, reservedBefore time.Time, limit int) ([]*domain.ExpiredReservation, error) {
+	// Every expired row makes the same transition, only reserved orders are selected
+	from, to := domain.OrderStatusReserved, domain.OrderStatusPending
+	if !to.IsTransitionAllowed(from) {
+		return nil, from.TransitionErr()
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE orders o SET
+			status = $2,
+			drone_id = NULL,
+			estimated_arrival_at = NULL,
+			updated_at = NOW()
+		FROM (
+			SELECT src.id, src.drone_id FROM orders src
+			WHERE src.active = TRUE AND src.status = $3
+				AND (
+					SELECT MAX(h.created_at) FROM order_status_history h
+					WHERE h.order_id = src.id AND h.to_status = $3
+				) <= $1
+			ORDER BY src.created_at ASC
+			LIMIT $4
+			FOR UPDATE OF src SKIP LOCKED
+		) expired
+		WHERE o.id = expired.id
+		RETURNING
+			o.id, o.order_number, o.user_id, o.receiver_name, o.receiver_phone, o.delivery_note,
+			o.package_weight_kg, o.origin_address, o.origin_lat, o.origin_lon, o.destination_address,
+			o.destination_lat, o.destination_lon, o.status, o.scheduled_at, o.delivered_at, o.cancelled_at,
+			o.delivered_by_drone_id, o.drone_id, o.withdrawn_at, o.current_lat, o.current_lon, o.current_altitude,
+			o.last_location_update_at, o.estimated_arrival_at, o.cancellation_reason, o.cancellation_note, o.cancelled_by_id,
+			o.price, o.currency, o.quote_id, o.priority, o.required_capabilities, o.version,
+			o.delivery_attempts, o.max_delivery_attempts, o.failure_action, o.service_area_id,
+			o.created_at, o.updated_at, o.active,
+			expired.drone_id`,
+		reservedBefore.UTC(),
+		to,
+		from,
+		limit,
+	)
+	if err != nil {
+		r.logger.Error("Failed to expire reservations", "error", err)
+		return nil, err
+	}
+
+	var expired []*domain.ExpiredReservation
+	for rows.Next() {
+		var droneID *string
+		order, err := r.scanOrder(rows, &droneID)
+		if err != nil {
+			rows.Close()
+			r.logger.Error("Failed to scan expired reservation row", "error", err)
+			return nil, err
+		}
+		expired = append(expired, &domain.ExpiredReservation{Order: order, DroneID: droneID})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	reason := domain.OrderStatusReasonReservationExpired
+	for _, reservation := range expired {
+		if reservation.DroneID != nil {
+			// The drone may have moved on already (broken, repaired), only a loading drone is freed
+			_, err := tx.ExecContext(ctx, `
+				UPDATE drones SET
+					status = $2,
+					updated_at = NOW()
+				WHERE id = $1 AND active = TRUE AND status = $3`,
+				*reservation.DroneID,
+				domain.DroneStatusIdle,
+				domain.DroneStatusLoading,
+			)
+			if err != nil {
+				r.logger.Error("Failed to release drone", "droneID", *reservation.DroneID, "orderID", reservation.Order.ID, "error", err)
+				return nil, err
+			}
+		}
+
+		err = insertOrderStatusHistory(ctx, tx, domain.OrderStatusHistory{
+			OrderID:    reservation.Order.ID,
+			FromStatus: &from,
+			ToStatus:   reservation.Order.Status,
+			DroneID:    reservation.DroneID,
+			Reason:     &reason,
+		})
+		if err != nil {
+			r.logger.Error("Failed to record order status history", "orderID", reservation.Order.ID, "error", err)
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", "error", err)
+		return nil, err
+	}
+
+	return expired, nil
+}
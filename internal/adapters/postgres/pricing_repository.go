@@ -0,0 +1,185 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"drones/internal/core/domain"
+	"drones/internal/ports"
+)
+
+type PricingRepository struct {
+	db     *sql.DB
+	logger ports.Logger
+}
+
+func NewPricingRepository(db *sql.DB, logger ports.Logger) ports.PricingRepository {
+	return &PricingRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const pricingRuleColumns = `
+	id, name, rule_type, priority, base_fare, per_km, per_kg, min_fare,
+	surcharge_percent, start_time, end_time,
+	created_at, updated_at, active, created_by_id, updated_by_id`
+
+func scanPricingRule(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*domain.PricingRule, error) {
+	var rule domain.PricingRule
+	err := scanner.Scan(
+		&rule.ID,
+		&rule.Name,
+		&rule.RuleType,
+		&rule.Priority,
+		&rule.BaseFare,
+		&rule.PerKm,
+		&rule.PerKg,
+		&rule.MinFare,
+		&rule.SurchargePercent,
+		&rule.StartTime,
+		&rule.EndTime,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+		&rule.Active,
+		&rule.CreatedByID,
+		&rule.UpdatedByID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// ListPricingRules retrieves the active pricing rules, tariffs first
+func (r *PricingRepository) ListPricingRules(ctx context.Context) ([]*domain.PricingRule, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT`+pricingRuleColumns+`
+		FROM pricing_rules
+		WHERE active = TRUE
+		ORDER BY rule_type ASC, priority ASC NULLS LAST, created_at ASC`)
+	if err != nil {
+		r.logger.Error("Failed to list pricing rules", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []*domain.PricingRule
+	for rows.Next() {
+		rule, err := scanPricingRule(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan pricing rule", "error", err)
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Failed to iterate pricing rules", "error", err)
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// CreatePricingRule inserts a new rule
+func (r *PricingRepository) CreatePricingRule(ctx context.Context, userID string, request *domain.PricingRuleRequest) (*domain.PricingRule, error) {
+	rule, err := scanPricingRule(r.db.QueryRowContext(ctx, `
+		INSERT INTO pricing_rules (
+			name, rule_type, priority, base_fare, per_km, per_kg, min_fare,
+			surcharge_percent, start_time, end_time, created_by_id, updated_by_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $11)
+		RETURNING`+pricingRuleColumns,
+		request.Name,
+		request.RuleType,
+		request.Priority,
+		request.BaseFare,
+		request.PerKm,
+		request.PerKg,
+		request.MinFare,
+		request.SurchargePercent,
+		request.StartTime,
+		request.EndTime,
+		userID,
+	))
+	if err != nil {
+		if isUniqueViolation(err, "idx_pricing_rules_tariff") {
+			return nil, domain.ErrPricingRuleConflict
+		}
+		r.logger.Error("Failed to create pricing rule", "error", err)
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// UpdatePricingRule replaces an active rule
+func (r *PricingRepository) UpdatePricingRule(ctx context.Context, ruleID string, userID string, request *domain.PricingRuleRequest) (*domain.PricingRule, error) {
+	rule, err := scanPricingRule(r.db.QueryRowContext(ctx, `
+		UPDATE pricing_rules SET
+			name = $2,
+			rule_type = $3,
+			priority = $4,
+			base_fare = $5,
+			per_km = $6,
+			per_kg = $7,
+			min_fare = $8,
+			surcharge_percent = $9,
+			start_time = $10,
+			end_time = $11,
+			updated_by_id = $12,
+			updated_at = NOW()
+		WHERE id = $1 AND active = TRUE
+		RETURNING`+pricingRuleColumns,
+		ruleID,
+		request.Name,
+		request.RuleType,
+		request.Priority,
+		request.BaseFare,
+		request.PerKm,
+		request.PerKg,
+		request.MinFare,
+		request.SurchargePercent,
+		request.StartTime,
+		request.EndTime,
+		userID,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrPricingRuleNotFound
+		}
+		if isUniqueViolation(err, "idx_pricing_rules_tariff") {
+			return nil, domain.ErrPricingRuleConflict
+		}
+		r.logger.Error("Failed to update pricing rule", "ruleID", ruleID, "error", err)
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// DeletePricingRule deactivates a rule, orders keep the price they were created with
+func (r *PricingRepository) DeletePricingRule(ctx context.Context, ruleID string, userID string) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE pricing_rules SET
+			active = FALSE,
+			updated_by_id = $2,
+			updated_at = NOW()
+		WHERE id = $1 AND active = TRUE`, ruleID, userID)
+	if err != nil {
+		r.logger.Error("Failed to delete pricing rule", "ruleID", ruleID, "error", err)
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrPricingRuleNotFound
+	}
+
+	return nil
+}
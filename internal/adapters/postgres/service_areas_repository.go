@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"drones/internal/core/domain"
+	"drones/internal/ports"
+
+	"github.com/lib/pq"
+)
+
+type ServiceAreasRepository struct {
+	db     *sql.DB
+	logger ports.Logger
+}
+
+func NewServiceAreasRepository(db *sql.DB, logger ports.Logger) ports.ServiceAreasRepository {
+	return &ServiceAreasRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const serviceAreaColumns = `
+	id, name, points, phone_formats,
+	created_at, updated_at, active, created_by_id, updated_by_id`
+
+func scanServiceArea(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*domain.ServiceArea, error) {
+	var area domain.ServiceArea
+	err := scanner.Scan(
+		&area.ID,
+		&area.Name,
+		geoPointsJSON{&area.Points},
+		pq.Array(&area.PhoneFormats),
+		&area.CreatedAt,
+		&area.UpdatedAt,
+		&area.Active,
+		&area.CreatedByID,
+		&area.UpdatedByID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &area, nil
+}
+
+// ListServiceAreas retrieves the service areas not deleted, oldest first
+func (r *ServiceAreasRepository) ListServiceAreas(ctx context.Context) ([]*domain.ServiceArea, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT`+serviceAreaColumns+`
+		FROM service_areas
+		WHERE active = TRUE
+		ORDER BY created_at ASC`)
+	if err != nil {
+		r.logger.Error("Failed to list service areas", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var areas []*domain.ServiceArea
+	for rows.Next() {
+		area, err := scanServiceArea(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan service area", "error", err)
+			return nil, err
+		}
+		areas = append(areas, area)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Failed to iterate service areas", "error", err)
+		return nil, err
+	}
+
+	return areas, nil
+}
+
+// CreateServiceArea inserts a new service area
+func (r *ServiceAreasRepository) CreateServiceArea(ctx context.Context, userID string, request *domain.ServiceAreaRequest) (*domain.ServiceArea, error) {
+	area, err := scanServiceArea(r.db.QueryRowContext(ctx, `
+		INSERT INTO service_areas (
+			name, points, phone_formats, created_by_id, updated_by_id
+		) VALUES ($1, $2, $3, $4, $4)
+		RETURNING`+serviceAreaColumns,
+		request.Name,
+		geoPointsJSON{&request.Points},
+		pq.Array(request.PhoneFormats),
+		userID,
+	))
+	if err != nil {
+		r.logger.Error("Failed to create service area", "error", err)
+		return nil, err
+	}
+
+	return area, nil
+}
+
+// UpdateServiceArea replaces a service area, orders already placed keep it
+func (r *ServiceAreasRepository) UpdateServiceArea(ctx context.Context, serviceAreaID string, userID string, request *domain.ServiceAreaRequest) (*domain.ServiceArea, error) {
+	area, err := scanServiceArea(r.db.QueryRowContext(ctx, `
+		UPDATE service_areas SET
+			name = $2,
+			points = $3,
+			phone_formats = $4,
+			updated_by_id = $5,
+			updated_at = NOW()
+		WHERE id = $1 AND active = TRUE
+		RETURNING`+serviceAreaColumns,
+		serviceAreaID,
+		request.Name,
+		geoPointsJSON{&request.Points},
+		pq.Array(request.PhoneFormats),
+		userID,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrServiceAreaNotFound
+		}
+		r.logger.Error("Failed to update service area", "serviceAreaID", serviceAreaID, "error", err)
+		return nil, err
+	}
+
+	return area, nil
+}
+
+// DeleteServiceArea deactivates a service area, its orders keep referencing it
+func (r *ServiceAreasRepository) DeleteServiceArea(ctx context.Context, serviceAreaID string, userID string) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE service_areas SET
+			active = FALSE,
+			updated_by_id = $2,
+			updated_at = NOW()
+		WHERE id = $1 AND active = TRUE`, serviceAreaID, userID)
+	if err != nil {
+		r.logger.Error("Failed to delete service area", "serviceAreaID", serviceAreaID, "error", err)
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrServiceAreaNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	config "drones/configs"
+	"drones/internal/core/domain"
+	"drones/internal/ports"
+)
+
+// LocalBlobStorage implements the BlobStorage interface on the local filesystem
+type LocalBlobStorage struct {
+	root   string
+	logger ports.Logger
+}
+
+// NewLocalBlobStorage creates the storage root if needed
+func NewLocalBlobStorage(config config.StorageConfig, logger ports.Logger) (ports.BlobStorage, error) {
+	root, err := filepath.Abs(config.LocalPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve storage path: %w", err)
+	}
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create storage path: %w", err)
+	}
+
+	return &LocalBlobStorage{
+		root:   root,
+		logger: logger,
+	}, nil
+}
+
+// path maps a key to a file below the root, keys escaping the root are rejected
+func (s *LocalBlobStorage) path(key string) (string, error) {
+	path := filepath.Join(s.root, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, s.root+string(os.PathSeparator)) {
+		return "", fmt.Errorf("invalid storage key: %q", key)
+	}
+	return path, nil
+}
+
+// Put writes data to a temporary file and renames it so readers never see partial objects
+func (s *LocalBlobStorage) Put(ctx context.Context, key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store file: %w", err)
+	}
+
+	return nil
+}
+
+// Get reads the object stored under key
+func (s *LocalBlobStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, domain.ErrBlobNotFound
+		}
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return data, nil
+}
+
+// Delete removes the object stored under key, missing objects are ignored
+func (s *LocalBlobStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	return nil
+}
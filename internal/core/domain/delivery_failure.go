@@ -0,0 +1,85 @@
+package domain
+
+// DeliveryFailureAction is what happens to an order after a failed delivery attempt
+type DeliveryFailureAction string
+
+const (
+	// The drone returns the package to the origin, then the order is back to pending for another attempt
+	DeliveryFailureActionRequeue DeliveryFailureAction = "requeue"
+	// The drone keeps the package and brings it back to the sender
+	DeliveryFailureActionReturnToSender DeliveryFailureAction = "return_to_sender"
+	// The drone leaves the package at the depot until an admin re-queues it
+	DeliveryFailureActionHoldAtDepot DeliveryFailureAction = "hold_at_depot"
+)
+
+func (action DeliveryFailureAction) IsValid() bool {
+	switch action {
+	case DeliveryFailureActionRequeue, DeliveryFailureActionReturnToSender, DeliveryFailureActionHoldAtDepot:
+		return true
+	default:
+		return false
+	}
+}
+
+// Status is the order status the action moves a failed order to
+func (action DeliveryFailureAction) Status() OrderStatus {
+	switch action {
+	case DeliveryFailureActionReturnToSender:
+		return OrderStatusReturningToSender
+	case DeliveryFailureActionHoldAtDepot:
+		return OrderStatusDeliveringToDepot
+	default:
+		return OrderStatusReturningToOrigin
+	}
+}
+
+type DeliveryFailureReason string
+
+// Reason codes a drone reports with a failed delivery
+const (
+	DeliveryFailureReasonReceiverUnavailable DeliveryFailureReason = "receiver_unavailable"
+	DeliveryFailureReasonAddressUnreachable  DeliveryFailureReason = "address_unreachable"
+	DeliveryFailureReasonAccessDenied        DeliveryFailureReason = "access_denied"
+	DeliveryFailureReasonWeather             DeliveryFailureReason = "weather"
+	DeliveryFailureReasonPackageDamaged      DeliveryFailureReason = "package_damaged"
+	DeliveryFailureReasonOther               DeliveryFailureReason = "other"
+)
+
+// IsRetryable reports whether another attempt at the same address can succeed
+func (reason DeliveryFailureReason) IsRetryable() bool {
+	return reason != DeliveryFailureReasonAddressUnreachable && reason != DeliveryFailureReasonPackageDamaged
+}
+
+type DeliveryFailedRequest struct {
+	ReasonCode DeliveryFailureReason `json:"reason_code,omitempty" validate:"omitempty,oneof=receiver_unavailable address_unreachable access_denied weather package_damaged other"`
+	Note       *string               `json:"note,omitempty" validate:"omitempty,max=1000"`
+}
+
+// Depot is where held packages are dropped off
+type Depot struct {
+	Address string
+	Lat     float64
+	Lon     float64
+}
+
+// DeliveryFailureResolution records a failed attempt and applies the action the failure
+// policy chose for it. An order that is already failed only gets the action.
+type DeliveryFailureResolution struct {
+	Action      DeliveryFailureAction
+	DroneID     string
+	UpdatedByID string
+	// Reason code recorded with the failed transition
+	Reason *string
+	// Version of the order the policy decided on, the resolution fails when the order moved on
+	ExpectedVersion *int
+	// Required by hold_at_depot
+	Depot *Depot
+}
+
+// DeliveryFailureOutcome is the order after the failure policy ran and the command
+// queued for the drone that failed the delivery
+type DeliveryFailureOutcome struct {
+	Order   *Order
+	Action  DeliveryFailureAction
+	Command *DroneCommand
+}
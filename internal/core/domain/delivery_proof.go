@@ -0,0 +1,94 @@
+package domain
+
+import "time"
+
+// DeliveryCodeTemplateID is the SMS template used to send the delivery code to the receiver
+const DeliveryCodeTemplateID = "delivery_code"
+
+// DeliveryPhotoContentTypes are the accepted photo types and their file extension
+var DeliveryPhotoContentTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+// DeliveryCode is the one-time code the receiver gives the drone at the door.
+// Code is only kept in memory until it has been sent to the receiver.
+type DeliveryCode struct {
+	Code string
+	Hash string
+	Salt string
+}
+
+// ConfirmDeliveryRequest is submitted by the drone at the destination
+type ConfirmDeliveryRequest struct {
+	DeliveryCode string  `json:"delivery_code" validate:"required,numeric,min=4,max=10"`
+	Lat          float64 `json:"lat" validate:"required,latitude"`
+	Lon          float64 `json:"lon" validate:"required,longitude"`
+
+	// Optional photo of the dropped package, read from a multipart upload
+	Photo            []byte `json:"-"`
+	PhotoContentType string `json:"-"`
+}
+
+// DeliveryProof is the record kept for every order that gets a delivery code,
+// the code hash is empty for orders created before codes were issued
+type DeliveryProof struct {
+	ID               string     `json:"id"`
+	OrderID          string     `json:"order_id"`
+	CodeHash         *string    `json:"-"`
+	CodeSalt         *string    `json:"-"`
+	FailedAttempts   int        `json:"failed_attempts"`
+	DroneID          *string    `json:"drone_id,omitempty"`
+	Lat              *float64   `json:"lat,omitempty"`
+	Lon              *float64   `json:"lon,omitempty"`
+	DistanceM        *float64   `json:"distance_m,omitempty"`
+	PhotoKey         *string    `json:"-"`
+	PhotoContentType *string    `json:"-"`
+	VerifiedAt       *time.Time `json:"verified_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// DeliveryProofUpdate is stored with the delivered transition
+type DeliveryProofUpdate struct {
+	DroneID          string
+	Lat              float64
+	Lon              float64
+	DistanceM        float64
+	PhotoKey         *string
+	PhotoContentType *string
+}
+
+type DeliveryProofDTO struct {
+	OrderID        string     `json:"order_id"`
+	DroneID        *string    `json:"drone_id,omitempty"`
+	Verified       bool       `json:"verified"`
+	FailedAttempts int        `json:"failed_attempts"`
+	Lat            *float64   `json:"lat,omitempty"`
+	Lon            *float64   `json:"lon,omitempty"`
+	DistanceM      *float64   `json:"distance_m,omitempty"`
+	HasPhoto       bool       `json:"has_photo"`
+	VerifiedAt     *time.Time `json:"verified_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func (p *DeliveryProof) ToDTO() DeliveryProofDTO {
+	return DeliveryProofDTO{
+		OrderID:        p.OrderID,
+		DroneID:        p.DroneID,
+		Verified:       p.VerifiedAt != nil,
+		FailedAttempts: p.FailedAttempts,
+		Lat:            p.Lat,
+		Lon:            p.Lon,
+		DistanceM:      p.DistanceM,
+		HasPhoto:       p.PhotoKey != nil,
+		VerifiedAt:     p.VerifiedAt,
+		CreatedAt:      p.CreatedAt,
+	}
+}
+
+// DeliveryPhoto is the stored photo of a delivery
+type DeliveryPhoto struct {
+	ContentType string
+	Data        []byte
+}
@@ -0,0 +1,71 @@
+package domain
+
+import "drones/pkg/utils"
+
+// DispatchPolicyName selects how the dispatcher ranks eligible drones for a pending order.
+//
+//   - nearest_first: the closest drone to the order origin wins, battery breaks ties
+//   - balanced_utilization: spreads work across the fleet by favouring drones with
+//     more battery and fewer deliveries/flight hours, while still penalising distance
+type DispatchPolicyName string
+
+// Dispatch policies
+const (
+	DispatchPolicyNearestFirst        DispatchPolicyName = "nearest_first"
+	DispatchPolicyBalancedUtilization DispatchPolicyName = "balanced_utilization"
+)
+
+// DispatchCandidate is an idle drone that passed the hard eligibility checks for an order
+type DispatchCandidate struct {
+	Drone              *Drone  `json:"drone"`
+	DistanceToOriginKm float64 `json:"distance_to_origin_km"`
+	TripKm             float64 `json:"trip_km"`
+	Score              float64 `json:"score"`
+}
+
+// RequiredRangeKm is the distance the drone has to fly to reach the origin and complete the delivery
+func (c *DispatchCandidate) RequiredRangeKm() float64 {
+	return c.DistanceToOriginKm + c.TripKm
+}
+
+// AvailableRangeKm estimates the remaining range of the drone from its battery level
+func (d *Drone) AvailableRangeKm() float64 {
+	if d.BatteryLevelPercent == nil {
+		return d.MaxRangeKm
+	}
+	return d.MaxRangeKm * (*d.BatteryLevelPercent / 100)
+}
+
+// CanCarry reports whether the package weight fits the drone payload
+func (d *Drone) CanCarry(weightKg *float64) bool {
+	if weightKg == nil {
+		return true
+	}
+	return *weightKg <= d.MaxWeightKg
+}
+
+// RequiredRangeKm is the distance the drone has to fly to complete the order, through the
+// pickup point when the package is not on board. When the drone position is unknown only
+// the trip from the pickup point is counted.
+func (d *Drone) RequiredRangeKm(order *Order) float64 {
+	if d.CurrentLat == nil || d.CurrentLon == nil {
+		pickupLat, pickupLon := order.PickupPoint()
+		return utils.HaversineKm(pickupLat, pickupLon, order.DestinationLat, order.DestinationLon)
+	}
+	return order.RemainingDistanceKm(*d.CurrentLat, *d.CurrentLon)
+}
+
+// CheckCanPerform returns why the drone cannot physically perform the order,
+// nil when payload, equipment and remaining range all fit
+func (d *Drone) CheckCanPerform(order *Order) error {
+	if !d.CanCarry(order.PackageWeightKg) {
+		return ErrPackageTooHeavy
+	}
+	if missing := d.MissingCapabilities(order.RequiredCapabilities); len(missing) > 0 {
+		return missing[0].MissingErr()
+	}
+	if d.AvailableRangeKm() < d.RequiredRangeKm(order) {
+		return ErrTripOutOfRange
+	}
+	return nil
+}
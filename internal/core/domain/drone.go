@@ -11,17 +11,19 @@ type DroneStatus string
 //   - returning: After successful delivery, drone enters returning state to go back to base
 //   - charging: From idle or returning, drone can enter charging when battery is low
 //   - broken: Drone can transition to broken from any operational state due to malfunction
+//   - lost: A flying drone stopped sending heartbeats, it is restored to idle when they resume
 //   - under_repair: Broken drones move to under_repair when maintenance begins
 //   - maintenanced: Scheduled maintenance state, typically from idle for preventive care
 //
 // Valid transitions:
 //
 //	idle -> loading, charging, maintenanced, broken
-//	loading -> delivering, broken
-//	delivering -> returning, broken
-//	returning -> idle, charging, broken
+//	loading -> delivering, broken, lost
+//	delivering -> returning, broken, lost
+//	returning -> idle, charging, broken, lost
 //	charging -> idle, broken, returning
 //	broken -> under_repair
+//	lost -> idle, broken
 //	under_repair -> maintenanced
 //	maintenanced -> idle, returning
 const (
@@ -31,89 +33,99 @@ const (
 	DroneStatusReturning    DroneStatus = "returing"     // Returning to base after delivery
 	DroneStatusCharging     DroneStatus = "charging"     // Charging its battery
 	DroneStatusBroken       DroneStatus = "broken"       // Drone is broken
+	DroneStatusLost         DroneStatus = "lost"         // No heartbeat within the timeout while flying
 	DroneStatusUnderRepair  DroneStatus = "under_repair" // Undergoing repairs
 	DroneStatusMaintenanced DroneStatus = "maintenanced" // Undergoing maintenance
 )
 
 type Drone struct {
 	BaseModel
-	DroneIdentifier      string      `json:"drone_identifier"`
-	UserID               string      `json:"user_id"`
-	SerialNumber         string      `json:"serial_number"`
-	Model                string      `json:"model"`
-	Manufacturer         string      `json:"manufacturer"`
-	MaxWeightKg          float64     `json:"max_weight_kg"`
-	MaxSpeedKmh          float64     `json:"max_speed_kmh"`
-	MaxRangeKm           float64     `json:"max_range_km"`
-	BatteryCapacityMah   int         `json:"battery_capacity_mah"`
-	Status               DroneStatus `json:"status"`
-	BatteryLevelPercent  *float64    `json:"battery_level_percent,omitempty"`
-	CurrentLat           *float64    `json:"current_lat,omitempty"`
-	CurrentLon           *float64    `json:"current_lon,omitempty"`
-	CurrentAltitude      *float64    `json:"current_altitude,omitempty"`
-	LastLocationUpdateAt *string     `json:"last_location_update_at,omitempty"`
-	TotalFlightHours     float64     `json:"total_flight_hours"`
-	TotalDeliveries      int         `json:"total_deliveries"`
-	LastMaintenanceAt    *string     `json:"last_maintenance_at,omitempty"`
-	NextMaintenanceDueAt *string     `json:"next_maintenance_due_at,omitempty"`
+	DroneIdentifier      string            `json:"drone_identifier"`
+	UserID               string            `json:"user_id"`
+	SerialNumber         string            `json:"serial_number"`
+	Model                string            `json:"model"`
+	Manufacturer         string            `json:"manufacturer"`
+	MaxWeightKg          float64           `json:"max_weight_kg"`
+	MaxSpeedKmh          float64           `json:"max_speed_kmh"`
+	MaxRangeKm           float64           `json:"max_range_km"`
+	BatteryCapacityMah   int               `json:"battery_capacity_mah"`
+	Status               DroneStatus       `json:"status"`
+	BatteryLevelPercent  *float64          `json:"battery_level_percent,omitempty"`
+	CurrentLat           *float64          `json:"current_lat,omitempty"`
+	CurrentLon           *float64          `json:"current_lon,omitempty"`
+	CurrentAltitude      *float64          `json:"current_altitude,omitempty"`
+	LastLocationUpdateAt *string           `json:"last_location_update_at,omitempty"`
+	TotalFlightHours     float64           `json:"total_flight_hours"`
+	TotalDeliveries      int               `json:"total_deliveries"`
+	LastMaintenanceAt    *string           `json:"last_maintenance_at,omitempty"`
+	NextMaintenanceDueAt *string           `json:"next_maintenance_due_at,omitempty"`
+	Capabilities         []DroneCapability `json:"capabilities"`
+	Version              int               `json:"version"`
 }
 
 type DroneDTO struct {
-	ID                  string      `json:"id"`
-	UserID              string      `json:"user_id"`
-	CreatedAt           string      `json:"created_at"`
-	UpdatedAt           string      `json:"updated_at"`
-	Active              bool        `json:"active"`
-	CreatedByID         *string     `json:"created_by_id"`
-	UpdatedByID         *string     `json:"updated_by_id"`
-	DroneIdentifier     string      `json:"drone_identifier"`
-	Model               string      `json:"model"`
-	SerialNumber        string      `json:"serial_number"`
-	BatteryCapacity     int         `json:"battery_capacity"`
-	PayloadCapacity     float64     `json:"payload_capacity"`
-	Manufacturer        string      `json:"manufacturer"`
-	LastChargedAt       *string     `json:"last_charged_at"`
-	IsCharging          *bool       `json:"is_charging"`
-	LastKnownLat        *float64    `json:"last_known_lat"`
-	LastKnownLng        *float64    `json:"last_known_lng"`
-	LastAltitudeM       *float64    `json:"last_altitude_m"`
-	LastSpeedKmh        *float64    `json:"last_speed_kmh"`
-	CurrentOrderID      *string     `json:"current_order_id"`
-	CrashesCount        *int        `json:"crashes_count"`
-	MaintenanceRequired *bool       `json:"maintenance_required"`
-	LastMaintenanceAt   *string     `json:"last_maintenance_at"`
-	NextMaintenanceAt   *string     `json:"next_maintenance_at"`
-	Status              DroneStatus `json:"status"`
+	ID                  string            `json:"id"`
+	UserID              string            `json:"user_id"`
+	CreatedAt           string            `json:"created_at"`
+	UpdatedAt           string            `json:"updated_at"`
+	Active              bool              `json:"active"`
+	CreatedByID         *string           `json:"created_by_id"`
+	UpdatedByID         *string           `json:"updated_by_id"`
+	DroneIdentifier     string            `json:"drone_identifier"`
+	Model               string            `json:"model"`
+	SerialNumber        string            `json:"serial_number"`
+	BatteryCapacity     int               `json:"battery_capacity"`
+	PayloadCapacity     float64           `json:"payload_capacity"`
+	Manufacturer        string            `json:"manufacturer"`
+	LastChargedAt       *string           `json:"last_charged_at"`
+	IsCharging          *bool             `json:"is_charging"`
+	LastKnownLat        *float64          `json:"last_known_lat"`
+	LastKnownLng        *float64          `json:"last_known_lng"`
+	LastAltitudeM       *float64          `json:"last_altitude_m"`
+	LastSpeedKmh        *float64          `json:"last_speed_kmh"`
+	CurrentOrderID      *string           `json:"current_order_id"`
+	CrashesCount        *int              `json:"crashes_count"`
+	MaintenanceRequired *bool             `json:"maintenance_required"`
+	LastMaintenanceAt   *string           `json:"last_maintenance_at"`
+	NextMaintenanceAt   *string           `json:"next_maintenance_at"`
+	Status              DroneStatus       `json:"status"`
+	Capabilities        []DroneCapability `json:"capabilities"`
+	Version             int               `json:"version"`
 }
 
 type CreateDroneRequest struct {
-	Model           string  `json:"model" validate:"required,min=2,max=50"`
-	SerialNumber    string  `json:"serial_number" validate:"required,alphanum,min=5,max=100"`
-	Manufacturer    string  `json:"manufacturer" validate:"required,min=2,max=50"`
-	BatteryCapacity int     `json:"battery_capacity" validate:"required,min=1000,max=100000"`
-	PayloadCapacity float64 `json:"payload_capacity" validate:"required,min=0.1,max=500"`
-	CreatedByID     string  `json:"created_by_id" validate:"required,uuid4"`
+	Model           string            `json:"model" validate:"required,min=2,max=50"`
+	SerialNumber    string            `json:"serial_number" validate:"required,alphanum,min=5,max=100"`
+	Manufacturer    string            `json:"manufacturer" validate:"required,min=2,max=50"`
+	BatteryCapacity int               `json:"battery_capacity" validate:"required,min=1000,max=100000"`
+	PayloadCapacity float64           `json:"payload_capacity" validate:"required,min=0.1,max=500"`
+	Capabilities    []DroneCapability `json:"capabilities,omitempty" validate:"omitempty,dive,oneof=cold_chain heavy_lift secure_box"`
+	CreatedByID     string            `json:"created_by_id" validate:"required,uuid4"`
 }
 
 type UpdateDroneRequest struct {
-	Model               *string      `json:"model,omitempty" validate:"omitempty,min=2,max=50"`
-	SerialNumber        *string      `json:"serial_number,omitempty" validate:"omitempty,alphanum,min=5,max=100"`
-	Manufacturer        *string      `json:"manufacturer,omitempty" validate:"omitempty,min=2,max=50"`
-	BatteryCapacity     *int         `json:"battery_capacity,omitempty" validate:"omitempty,min=1000,max=100000"`
-	PayloadCapacity     *float64     `json:"payload_capacity,omitempty" validate:"omitempty,min=0.1,max=500"`
-	UpdatedByID         *string      `json:"updated_by_id,omitempty" validate:"omitempty,required,uuid4"`
-	LastChargedAt       *string      `json:"last_charged_at,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
-	IsCharging          *bool        `json:"is_charging,omitempty"`
-	LastKnownLat        *float64     `json:"last_known_lat,omitempty" validate:"omitempty,min=-90,max=90"`
-	LastKnownLng        *float64     `json:"last_known_lng,omitempty" validate:"omitempty,min=-180,max=180"`
-	LastAltitudeM       *float64     `json:"last_altitude_m,omitempty" validate:"omitempty,min=0,max=10000"`
-	LastSpeedKmh        *float64     `json:"last_speed_kmh,omitempty" validate:"omitempty,min=0,max=500"`
-	CurrentOrderID      *string      `json:"current_order_id,omitempty" validate:"omitempty,uuid4"`
-	CrashesCount        *int         `json:"crashes_count,omitempty" validate:"omitempty,min=0"`
-	MaintenanceRequired *bool        `json:"maintenance_required,omitempty"`
-	LastMaintenanceAt   *string      `json:"last_maintenance_at,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
-	NextMaintenanceAt   *string      `json:"next_maintenance_at,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
-	Status              *DroneStatus `json:"status,omitempty" validate:"omitempty,oneof=idle loading delivering returning charging maintenance"`
+	Model               *string            `json:"model,omitempty" validate:"omitempty,min=2,max=50"`
+	SerialNumber        *string            `json:"serial_number,omitempty" validate:"omitempty,alphanum,min=5,max=100"`
+	Manufacturer        *string            `json:"manufacturer,omitempty" validate:"omitempty,min=2,max=50"`
+	BatteryCapacity     *int               `json:"battery_capacity,omitempty" validate:"omitempty,min=1000,max=100000"`
+	PayloadCapacity     *float64           `json:"payload_capacity,omitempty" validate:"omitempty,min=0.1,max=500"`
+	UpdatedByID         *string            `json:"updated_by_id,omitempty" validate:"omitempty,required,uuid4"`
+	LastChargedAt       *string            `json:"last_charged_at,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	IsCharging          *bool              `json:"is_charging,omitempty"`
+	LastKnownLat        *float64           `json:"last_known_lat,omitempty" validate:"omitempty,min=-90,max=90"`
+	LastKnownLng        *float64           `json:"last_known_lng,omitempty" validate:"omitempty,min=-180,max=180"`
+	LastAltitudeM       *float64           `json:"last_altitude_m,omitempty" validate:"omitempty,min=0,max=10000"`
+	LastSpeedKmh        *float64           `json:"last_speed_kmh,omitempty" validate:"omitempty,min=0,max=500"`
+	CurrentOrderID      *string            `json:"current_order_id,omitempty" validate:"omitempty,uuid4"`
+	CrashesCount        *int               `json:"crashes_count,omitempty" validate:"omitempty,min=0"`
+	MaintenanceRequired *bool              `json:"maintenance_required,omitempty"`
+	LastMaintenanceAt   *string            `json:"last_maintenance_at,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	NextMaintenanceAt   *string            `json:"next_maintenance_at,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Status              *DroneStatus       `json:"status,omitempty" validate:"omitempty,oneof=idle loading delivering returning charging maintenance"`
+	Capabilities        *[]DroneCapability `json:"capabilities,omitempty" validate:"omitempty,dive,oneof=cold_chain heavy_lift secure_box"`
+
+	// Version the caller last read (If-Match), the update fails when the drone moved on
+	ExpectedVersion *int `json:"-"`
 }
 
 type DroneFilter struct {
@@ -150,6 +162,8 @@ func (d *Drone) ToDTO() *DroneDTO {
 		Active:            d.Active,
 		CreatedByID:       d.CreatedByID,
 		UpdatedByID:       d.UpdatedByID,
+		Capabilities:      d.Capabilities,
+		Version:           d.Version,
 	}
 }
 
@@ -167,6 +181,8 @@ func (status DroneStatus) GetErr() error {
 		return ErrDroneInMaintenance
 	case DroneStatusBroken:
 		return ErrDroneIsBroken
+	case DroneStatusLost:
+		return ErrDroneIsLost
 	case DroneStatusUnderRepair:
 		return ErrDroneUnderRepair
 	default:
@@ -177,11 +193,12 @@ func (status DroneStatus) GetErr() error {
 // Force workflow to update drone status to broken
 //
 //	idle -> loading, charging, maintenanced, broken
-//	loading -> delivering, broken
-//	delivering -> returning, broken
-//	returning -> idle, charging, broken
+//	loading -> delivering, broken, lost
+//	delivering -> returning, broken, lost
+//	returning -> idle, charging, broken, lost
 //	charging -> idle, broken, returning
 //	broken -> under_repair
+//	lost -> idle, broken
 //	under_repair -> maintenanced
 //	maintenanced -> idle, returning
 func (status DroneStatus) IsTransitionAllowed(from DroneStatus) bool {
@@ -189,15 +206,17 @@ func (status DroneStatus) IsTransitionAllowed(from DroneStatus) bool {
 	case DroneStatusIdle:
 		return status == DroneStatusLoading || status == DroneStatusCharging || status == DroneStatusMaintenanced || status == DroneStatusBroken
 	case DroneStatusLoading:
-		return status == DroneStatusDelivering || status == DroneStatusBroken
+		return status == DroneStatusDelivering || status == DroneStatusBroken || status == DroneStatusLost
 	case DroneStatusDelivering:
-		return status == DroneStatusReturning || status == DroneStatusBroken
+		return status == DroneStatusReturning || status == DroneStatusBroken || status == DroneStatusLost
 	case DroneStatusReturning:
-		return status == DroneStatusIdle || status == DroneStatusCharging || status == DroneStatusBroken
+		return status == DroneStatusIdle || status == DroneStatusCharging || status == DroneStatusBroken || status == DroneStatusLost
 	case DroneStatusCharging:
 		return status == DroneStatusIdle || status == DroneStatusReturning || status == DroneStatusBroken
 	case DroneStatusBroken:
 		return status == DroneStatusUnderRepair
+	case DroneStatusLost:
+		return status == DroneStatusIdle || status == DroneStatusBroken
 	case DroneStatusUnderRepair:
 		return status == DroneStatusMaintenanced
 	case DroneStatusMaintenanced:
@@ -207,6 +226,13 @@ func (status DroneStatus) IsTransitionAllowed(from DroneStatus) bool {
 	}
 }
 
+// HeartbeatWatchedStatuses are the drone statuses where a missing heartbeat means the drone is lost
+var HeartbeatWatchedStatuses = []DroneStatus{
+	DroneStatusLoading,
+	DroneStatusDelivering,
+	DroneStatusReturning,
+}
+
 func (status DroneStatus) TransitionErr() error {
 	switch status {
 	case DroneStatusIdle:
@@ -221,6 +247,8 @@ func (status DroneStatus) TransitionErr() error {
 		return ErrChargingTransition
 	case DroneStatusBroken:
 		return ErrBrokenTransition
+	case DroneStatusLost:
+		return ErrLostTransition
 	case DroneStatusUnderRepair:
 		return ErrUnderRepairTransition
 	case DroneStatusMaintenanced:
@@ -0,0 +1,60 @@
+package domain
+
+type DroneCapability string
+
+// Equipment a drone can be fitted with, orders list the ones their payload needs
+const (
+	DroneCapabilityColdChain DroneCapability = "cold_chain" // Temperature controlled compartment
+	DroneCapabilityHeavyLift DroneCapability = "heavy_lift" // Reinforced frame for heavy or bulky payloads
+	DroneCapabilitySecureBox DroneCapability = "secure_box" // Locked compartment for high value or controlled payloads
+)
+
+var DroneCapabilities = []DroneCapability{
+	DroneCapabilityColdChain,
+	DroneCapabilityHeavyLift,
+	DroneCapabilitySecureBox,
+}
+
+func (c DroneCapability) IsValid() bool {
+	for _, capability := range DroneCapabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// MissingErr is the error returned when a drone without the capability reserves an order that needs it
+func (c DroneCapability) MissingErr() error {
+	switch c {
+	case DroneCapabilityColdChain:
+		return ErrColdChainRequired
+	case DroneCapabilityHeavyLift:
+		return ErrHeavyLiftRequired
+	case DroneCapabilitySecureBox:
+		return ErrSecureBoxRequired
+	default:
+		return ErrDroneCapabilityRequired
+	}
+}
+
+// HasCapability reports whether the drone is fitted with the capability
+func (d *Drone) HasCapability(capability DroneCapability) bool {
+	for _, c := range d.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// MissingCapabilities returns the required capabilities the drone is not fitted with
+func (d *Drone) MissingCapabilities(required []DroneCapability) []DroneCapability {
+	var missing []DroneCapability
+	for _, capability := range required {
+		if !d.HasCapability(capability) {
+			missing = append(missing, capability)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,58 @@
+package domain
+
+type DroneCommandType string
+
+const (
+	// Fly the package back to the order origin
+	DroneCommandReturnToOrigin DroneCommandType = "return_to_origin"
+	// Fly the package to the depot and leave it there
+	DroneCommandDeliverToDepot DroneCommandType = "deliver_to_depot"
+	// Fly to the new pickup or drop-off point of the order after a route change
+	DroneCommandNewDestination DroneCommandType = "new_destination"
+	// Abort and fly back to base
+	DroneCommandReturnToBase DroneCommandType = "return_to_base"
+	// Hover in place until the next command
+	DroneCommandHold DroneCommandType = "hold"
+	// Land at the nearest safe spot
+	DroneCommandLandNow DroneCommandType = "land_now"
+	// Leave the no-fly zone the drone entered, back to the last position outside of it
+	DroneCommandExitGeofence DroneCommandType = "exit_geofence"
+)
+
+type DroneCommandStatus string
+
+const (
+	DroneCommandStatusPending      DroneCommandStatus = "pending"
+	DroneCommandStatusAcknowledged DroneCommandStatus = "acknowledged"
+)
+
+// DroneCommand is an instruction queued for a drone. Lat and Lon hold the target
+// position for commands that move the drone.
+type DroneCommand struct {
+	ID             string             `json:"id"`
+	DroneID        string             `json:"drone_id"`
+	OrderID        *string            `json:"order_id,omitempty"`
+	Command        DroneCommandType   `json:"command"`
+	Status         DroneCommandStatus `json:"status"`
+	Lat            *float64           `json:"lat,omitempty"`
+	Lon            *float64           `json:"lon,omitempty"`
+	Note           *string            `json:"note,omitempty"`
+	AcknowledgedAt *string            `json:"acknowledged_at,omitempty"`
+	CreatedAt      string             `json:"created_at"`
+	CreatedByID    *string            `json:"created_by_id,omitempty"`
+}
+
+// QueueDroneCommandRequest is a command an admin queues for a drone
+type QueueDroneCommandRequest struct {
+	Command DroneCommandType `json:"command" validate:"required,oneof=return_to_base hold land_now new_destination"`
+	OrderID *string          `json:"order_id,omitempty" validate:"omitempty,uuid"`
+	Lat     *float64         `json:"lat,omitempty" validate:"required_if=Command new_destination,omitempty,latitude"`
+	Lon     *float64         `json:"lon,omitempty" validate:"required_if=Command new_destination,omitempty,longitude"`
+	Note    *string          `json:"note,omitempty" validate:"omitempty,max=1000"`
+}
+
+// DroneCommandFilter narrows the commands listed for a drone
+type DroneCommandFilter struct {
+	Status *DroneCommandStatus `json:"status,omitempty"`
+	Limit  int                 `json:"limit,omitempty"`
+}
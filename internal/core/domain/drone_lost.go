@@ -0,0 +1,14 @@
+package domain
+
+// HandedOffOrder is an order taken from a drone that can no longer fly it
+type HandedOffOrder struct {
+	OrderID    string
+	UserID     string
+	FromStatus OrderStatus
+}
+
+// LostDrone is a drone the heartbeat watchdog grounded and the orders it handed off
+type LostDrone struct {
+	Drone  *Drone
+	Orders []HandedOffOrder
+}
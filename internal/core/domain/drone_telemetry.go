@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+const (
+	// DefaultTelemetryWindow is how far back telemetry is listed when no range is given
+	DefaultTelemetryWindow = time.Hour
+	// MaxTelemetryPoints bounds a telemetry query, the interval is widened to stay below it
+	MaxTelemetryPoints = 5000
+)
+
+// DroneTelemetry is a drone as it reported itself on one heartbeat
+type DroneTelemetry struct {
+	DroneID             string      `json:"drone_id"`
+	OrderID             *string     `json:"order_id,omitempty"`
+	Status              DroneStatus `json:"status"`
+	Lat                 float64     `json:"lat"`
+	Lon                 float64     `json:"lon"`
+	Altitude            float64     `json:"altitude"`
+	BatteryLevelPercent int         `json:"battery_level_percent"`
+	// Flight time credited to the drone by this heartbeat
+	FlightSeconds float64 `json:"flight_seconds"`
+	RecordedAt    string  `json:"recorded_at"`
+}
+
+// DroneTelemetryQuery selects the telemetry of a drone in [From, To), one point per Interval
+type DroneTelemetryQuery struct {
+	From     time.Time
+	To       time.Time
+	Interval time.Duration
+}
+
+// Downsample sets the interval to what was asked for, widened so the range fits in MaxTelemetryPoints.
+// A one second interval returns every heartbeat.
+func (q *DroneTelemetryQuery) Downsample(requested time.Duration) {
+	interval := max(requested, q.To.Sub(q.From)/MaxTelemetryPoints, time.Second)
+	// Whole seconds, rounded up so the point count stays within the limit
+	if remainder := interval % time.Second; remainder != 0 {
+		interval += time.Second - remainder
+	}
+	q.Interval = interval
+}
+
+// DroneTelemetryHistory is the downsampled flight history of a drone over a time range
+type DroneTelemetryHistory struct {
+	DroneID         string `json:"drone_id"`
+	From            string `json:"from"`
+	To              string `json:"to"`
+	IntervalSeconds int    `json:"interval_seconds"`
+	// Flight time over the whole range, not only the points returned
+	FlightHours float64           `json:"flight_hours"`
+	Points      []*DroneTelemetry `json:"points"`
+}
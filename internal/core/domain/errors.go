@@ -87,7 +87,7 @@ var (
 
 	ErrWithdrawNotAllowed = &DomainError{
 		Code:    UnableToProcessError,
-		Message: "Only pending orders can be withdrawn",
+		Message: "Only pending or scheduled orders can be withdrawn",
 	}
 	ErrAlreadyWithdrawed = &DomainError{
 		Code:    UnableToProcessError,
@@ -97,6 +97,10 @@ var (
 		Code:    UnableToProcessError,
 		Message: "Only pending orders can be reserved",
 	}
+	ErrOrderNotReleased = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "Scheduled order is not released for pickup yet",
+	}
 	ErrAlreadyReserved = &DomainError{
 		Code:    UnableToProcessError,
 		Message: "Order has already been reserved",
@@ -125,6 +129,42 @@ var (
 		Code:    UnableToProcessError,
 		Message: "Only in picked or transit or arrived orders can be marked as delivery failed",
 	}
+	ErrInvalidDeliveryFailureAction = &DomainError{
+		Code:    InvalidInputError,
+		Message: "Invalid delivery failure action",
+	}
+	ErrDepotNotConfigured = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "No depot is configured to hold packages",
+	}
+	ErrReturnNotAllowed = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "Only orders returning to the origin, the sender or the depot can be marked as returned",
+	}
+	ErrRequeueNotAllowed = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "Only orders held at a depot can be re-queued",
+	}
+	ErrRouteUnchanged = &DomainError{
+		Code:    InvalidInputError,
+		Message: "A route change needs a new origin or destination",
+	}
+	ErrSameOriginAndDestination = &DomainError{
+		Code:    InvalidInputError,
+		Message: "Origin and destination must be different",
+	}
+	ErrRouteChangeNotAllowed = &DomainError{
+		Code:    UnableToUpdateError,
+		Message: "The route of a closed order cannot change",
+	}
+	ErrOriginChangeAfterPickup = &DomainError{
+		Code:    UnableToUpdateError,
+		Message: "The origin cannot change once the package is picked up",
+	}
+	ErrChangeRouteThroughChangeRoute = &DomainError{
+		Code:    UnableToUpdateError,
+		Message: "Origin and destination are changed through the route endpoint",
+	}
 	ErrDroneFailedNotAllowed = &DomainError{
 		Code:    UnableToProcessError,
 		Message: "Only in transit or pickup orders can be marked as drone failed",
@@ -134,6 +174,18 @@ var (
 		Code:    UnableToProcessError,
 		Message: "Only handoff orders can be reassigned",
 	}
+	ErrNoEligibleDrone = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "No eligible drone available for this order",
+	}
+	ErrDroneLocationUnknown = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "Drone location is unknown, send a heartbeat first",
+	}
+	ErrNoJobAvailable = &DomainError{
+		Code:    ResourceNotFoundError,
+		Message: "No order available to claim",
+	}
 	ErrDroneMustBeIdle = &DomainError{
 		Code:    UnableToProcessError,
 		Message: "Drone must be in idle status to reserve an order",
@@ -162,6 +214,14 @@ var (
 		Code:    UnableToProcessError,
 		Message: "Drone is broken",
 	}
+	ErrDroneIsLost = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "Drone is lost, no heartbeat received within the timeout",
+	}
+	ErrDroneNotLost = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "Only a lost drone can be restored",
+	}
 	ErrDroneUnderRepair = &DomainError{
 		Code:    UnableToProcessError,
 		Message: "Drone is under repair",
@@ -190,6 +250,10 @@ var (
 		Code:    UnableToProcessError,
 		Message: "broken can only transition to under_repair",
 	}
+	ErrLostTransition = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "lost can only transition to idle or broken",
+	}
 	ErrUnderRepairTransition = &DomainError{
 		Code:    UnableToProcessError,
 		Message: "under_repair can only transition to maintenanced",
@@ -198,6 +262,242 @@ var (
 		Code:    UnableToProcessError,
 		Message: "maintenanced can only transition to idle, returning",
 	}
+	ErrInvalidOrderStatus = &DomainError{
+		Code:    InvalidInputError,
+		Message: "Invalid order status",
+	}
+	ErrOrderScheduledTransition = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "scheduled can only transition to pending, cancelled",
+	}
+	ErrOrderPendingTransition = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "pending can only transition to reserved, cancelled",
+	}
+	ErrOrderReservedTransition = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "reserved can only transition to picked_up, handoff, pending, cancelled",
+	}
+	ErrOrderPickedUpTransition = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "picked_up can only transition to in_transit, failed, handoff, cancelled",
+	}
+	ErrOrderInTransitTransition = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "in_transit can only transition to arrived, failed, handoff, cancelled",
+	}
+	ErrOrderArrivedTransition = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "arrived can only transition to delivered, failed, handoff, cancelled",
+	}
+	ErrOrderDeliveredTransition = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "delivered is a final status",
+	}
+	ErrOrderFailedTransition = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "failed can only transition to handoff, returning_to_origin, returning_to_sender, delivering_to_depot, cancelled",
+	}
+	ErrOrderCancelledTransition = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "cancelled is a final status",
+	}
+	ErrOrderHandoffTransition = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "handoff can only transition to reassigned, cancelled",
+	}
+	ErrOrderReassignedTransition = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "reassigned can only transition to picked_up, handoff, cancelled",
+	}
+	ErrOrderReturningToOriginTransition = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "returning_to_origin can only transition to pending, handoff, cancelled",
+	}
+	ErrOrderReturningToSenderTransition = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "returning_to_sender can only transition to returned, handoff, cancelled",
+	}
+	ErrOrderReturnedTransition = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "returned is a final status",
+	}
+	ErrOrderDeliveringToDepotTransition = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "delivering_to_depot can only transition to held_at_depot, handoff, cancelled",
+	}
+	ErrOrderHeldAtDepotTransition = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "held_at_depot can only transition to handoff, cancelled",
+	}
+	ErrPackageTooHeavy = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "Package is heavier than the drone can carry",
+	}
+	ErrTripOutOfRange = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "Trip is longer than the drone remaining range",
+	}
+	ErrDroneCapabilityRequired = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "Drone is not fitted with the equipment the order requires",
+	}
+	ErrColdChainRequired = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "Order requires a cold chain drone",
+	}
+	ErrHeavyLiftRequired = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "Order requires a heavy lift drone",
+	}
+	ErrSecureBoxRequired = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "Order requires a drone with a secure box",
+	}
+	ErrCancelThroughCancelOrder = &DomainError{
+		Code:    UnableToUpdateError,
+		Message: "Orders are cancelled through the cancel endpoint",
+	}
+	ErrDeliverThroughConfirmDelivery = &DomainError{
+		Code:    UnableToUpdateError,
+		Message: "Orders are delivered through the confirm delivery endpoint",
+	}
+	ErrReservationReleasedOnExpiry = &DomainError{
+		Code:    UnableToUpdateError,
+		Message: "Reserved orders return to pending when their reservation expires",
+	}
+	ErrReleaseThroughConfirmReturn = &DomainError{
+		Code:    UnableToUpdateError,
+		Message: "Packages on their way back are released when the drone confirms the drop-off",
+	}
+	ErrBulkOrdersEmpty = &DomainError{
+		Code:    InvalidInputError,
+		Message: "Bulk request must contain at least one order",
+	}
+	ErrBulkOrdersTooLarge = &DomainError{
+		Code:    InvalidInputError,
+		Message: fmt.Sprintf("Bulk request cannot contain more than %d orders", MaxBulkOrders),
+	}
+	ErrInvalidDeliveryCode = &DomainError{
+		Code:    InvalidOtpError,
+		Message: "Invalid delivery code",
+	}
+	ErrDeliveryCodeLocked = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "Too many invalid delivery codes, contact support",
+	}
+	ErrDeliveryOutOfRange = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "Drone is too far from the destination to deliver",
+	}
+	ErrInvalidDeliveryPhoto = &DomainError{
+		Code:    InvalidInputError,
+		Message: "Delivery photo must be a JPEG or PNG image",
+	}
+	ErrDeliveryPhotoTooLarge = &DomainError{
+		Code:    InvalidInputError,
+		Message: "Delivery photo is too large",
+	}
+	ErrDeliveryProofNotFound = &DomainError{
+		Code:    ResourceNotFoundError,
+		Message: "Delivery proof not found",
+	}
+	ErrDeliveryPhotoNotFound = &DomainError{
+		Code:    ResourceNotFoundError,
+		Message: "Delivery photo not found",
+	}
+	ErrBlobNotFound = &DomainError{
+		Code:    ResourceNotFoundError,
+		Message: "Stored object not found",
+	}
+	ErrNoPricingRule = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "No tariff is configured for this priority",
+	}
+	ErrPricingRuleNotFound = &DomainError{
+		Code:    ResourceNotFoundError,
+		Message: "Pricing rule not found",
+	}
+	ErrPricingRuleConflict = &DomainError{
+		Code:    ResourceConflictError,
+		Message: "An active tariff already exists for this priority",
+	}
+	ErrGeofenceNotFound = &DomainError{
+		Code:    ResourceNotFoundError,
+		Message: "Geofence not found",
+	}
+	ErrGeofencePeriod = &DomainError{
+		Code:    InvalidInputError,
+		Message: "active_until must be after active_from",
+	}
+	ErrOriginInNoFlyZone = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "Origin is inside a no-fly zone",
+	}
+	ErrDestinationInNoFlyZone = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "Destination is inside a no-fly zone",
+	}
+	ErrServiceAreaNotFound = &DomainError{
+		Code:    ResourceNotFoundError,
+		Message: "Service area not found",
+	}
+	ErrOriginOutsideServiceArea = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "Origin is outside the service areas",
+	}
+	ErrDestinationOutsideServiceArea = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "Destination is outside the service area of the origin",
+	}
+	ErrLocationOutsideServiceArea = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "Location is outside the service areas",
+	}
+	ErrReceiverPhoneNotAllowed = &DomainError{
+		Code:    InvalidInputError,
+		Message: "Receiver phone number does not match the formats of the service area",
+	}
+	ErrInvalidQuote = &DomainError{
+		Code:    InvalidInputError,
+		Message: "Invalid quote",
+	}
+	ErrQuoteExpired = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "Quote has expired, request a new quote",
+	}
+	ErrQuoteMismatch = &DomainError{
+		Code:    UnableToProcessError,
+		Message: "Order does not match the quoted delivery",
+	}
+	ErrQuoteAlreadyUsed = &DomainError{
+		Code:    ConflictError,
+		Message: "Quote has already been used for another order",
+	}
+	ErrOrderVersionConflict = &DomainError{
+		Code:    ConflictError,
+		Message: "Order was modified by someone else, reload it and retry",
+	}
+	ErrDroneVersionConflict = &DomainError{
+		Code:    ConflictError,
+		Message: "Drone was modified by someone else, reload it and retry",
+	}
+	ErrInvalidIfMatch = &DomainError{
+		Code:    InvalidInputError,
+		Message: "If-Match must be a version ETag returned by a previous read",
+	}
+	ErrInvalidIdempotencyKey = &DomainError{
+		Code:    InvalidInputError,
+		Message: fmt.Sprintf("Idempotency-Key must be between 1 and %d characters", MaxIdempotencyKeyLength),
+	}
+	ErrIdempotencyKeyReused = &DomainError{
+		Code:    ConflictError,
+		Message: "Idempotency-Key was already used with a different request",
+	}
+	ErrIdempotencyKeyInProgress = &DomainError{
+		Code:    ConflictError,
+		Message: "A request with this Idempotency-Key is still being processed",
+	}
 )
 
 type DomainError struct {
@@ -0,0 +1,47 @@
+package domain
+
+import "drones/pkg/utils"
+
+// EtaTrackedStatuses are the statuses where a drone is working on the order and an ETA is kept
+var EtaTrackedStatuses = []OrderStatus{
+	OrderStatusReserved,
+	OrderStatusPickedUp,
+	OrderStatusInTransit,
+	OrderStatusArrived,
+	OrderStatusReassigned,
+}
+
+// IsEtaTracked reports whether the order is being worked on by a drone
+func (order *Order) IsEtaTracked() bool {
+	for _, status := range EtaTrackedStatuses {
+		if order.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPackageOnBoard reports whether the drone is carrying the package
+func (order *Order) IsPackageOnBoard() bool {
+	return order.Status == OrderStatusPickedUp || order.Status == OrderStatusInTransit || order.Status == OrderStatusArrived
+}
+
+// PickupPoint is where the package waits for a drone: the origin, or the last
+// known position of the order after a handoff
+func (order *Order) PickupPoint() (float64, float64) {
+	if order.CurrentLat != nil && order.CurrentLon != nil {
+		return *order.CurrentLat, *order.CurrentLon
+	}
+	return order.OriginLat, order.OriginLon
+}
+
+// RemainingDistanceKm is the great-circle distance a drone at lat/lon still has to fly,
+// through the pickup point when the package is not on board yet
+func (order *Order) RemainingDistanceKm(lat, lon float64) float64 {
+	if order.IsPackageOnBoard() {
+		return utils.HaversineKm(lat, lon, order.DestinationLat, order.DestinationLon)
+	}
+	pickupLat, pickupLon := order.PickupPoint()
+	return utils.HaversineKm(lat, lon, pickupLat, pickupLon) +
+		utils.HaversineKm(pickupLat, pickupLon, order.DestinationLat, order.DestinationLon)
+}
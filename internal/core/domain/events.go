@@ -8,10 +8,20 @@ type EventType string
 
 const (
 	EventTypeDroneLocationUpdated EventType = "drone_location_updated"
+	EventTypeDroneLost            EventType = "drone_lost"
+	EventTypeDroneRestored        EventType = "drone_restored"
+	EventTypeGeofenceViolation    EventType = "geofence_violation"
 
 	// Order Events
-	EventTypeOrderCreated EventType = "order_created"
-	EventTypeOrderUpdated EventType = "order_updated"
+	EventTypeOrderCreated   EventType = "order_created"
+	EventTypeOrderUpdated   EventType = "order_updated"
+	EventTypeOrderAssigned  EventType = "order_assigned"
+	EventTypeOrderReleased  EventType = "order_released"
+	EventTypeOrderCancelled EventType = "order_cancelled"
+
+	// Notification Events
+	EventTypeSendOTP             EventType = "send_otp"
+	EventTypeOrderDeliveryFailed EventType = "order_delivery_failed"
 )
 
 // DomainEvent represents a domain event
@@ -0,0 +1,155 @@
+package domain
+
+import (
+	"time"
+
+	"drones/pkg/utils"
+)
+
+type GeofenceShape string
+
+const (
+	GeofenceShapePolygon GeofenceShape = "polygon"
+	GeofenceShapeCircle  GeofenceShape = "circle"
+)
+
+// GeoPoint is a vertex of a geofence polygon
+type GeoPoint struct {
+	Lat float64 `json:"lat" validate:"gte=-90,lte=90"`
+	Lon float64 `json:"lon" validate:"gte=-180,lte=180"`
+}
+
+// Geofence is a no-fly zone drawn by admins, either a polygon or a circle. It is always active
+// unless restricted to a daily StartTime/EndTime window ("15:04", local time, a window ending
+// before it starts wraps midnight) and/or to the ActiveFrom/ActiveUntil period (RFC3339).
+type Geofence struct {
+	BaseModel
+	Name         string        `json:"name"`
+	Shape        GeofenceShape `json:"shape"`
+	Points       []GeoPoint    `json:"points,omitempty"`
+	CenterLat    *float64      `json:"center_lat,omitempty"`
+	CenterLon    *float64      `json:"center_lon,omitempty"`
+	RadiusMeters *float64      `json:"radius_m,omitempty"`
+	StartTime    *string       `json:"start_time,omitempty"`
+	EndTime      *string       `json:"end_time,omitempty"`
+	ActiveFrom   *string       `json:"active_from,omitempty"`
+	ActiveUntil  *string       `json:"active_until,omitempty"`
+}
+
+// GeofenceRequest creates or replaces a geofence
+type GeofenceRequest struct {
+	Name         string        `json:"name" validate:"required,min=1,max=100"`
+	Shape        GeofenceShape `json:"shape" validate:"required,oneof=polygon circle"`
+	Points       []GeoPoint    `json:"points,omitempty" validate:"required_if=Shape polygon,omitempty,min=3,max=200,dive"`
+	CenterLat    *float64      `json:"center_lat,omitempty" validate:"required_if=Shape circle,omitempty,gte=-90,lte=90"`
+	CenterLon    *float64      `json:"center_lon,omitempty" validate:"required_if=Shape circle,omitempty,gte=-180,lte=180"`
+	RadiusMeters *float64      `json:"radius_m,omitempty" validate:"required_if=Shape circle,omitempty,gt=0,lte=100000"`
+	StartTime    *string       `json:"start_time,omitempty" validate:"required_with=EndTime,omitempty,datetime=15:04"`
+	EndTime      *string       `json:"end_time,omitempty" validate:"required_with=StartTime,omitempty,datetime=15:04"`
+	ActiveFrom   *string       `json:"active_from,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	ActiveUntil  *string       `json:"active_until,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+// Normalize drops the fields of the other shape, so a zone is stored as exactly one shape
+func (request *GeofenceRequest) Normalize() {
+	switch request.Shape {
+	case GeofenceShapePolygon:
+		request.CenterLat, request.CenterLon, request.RadiusMeters = nil, nil, nil
+	case GeofenceShapeCircle:
+		request.Points = nil
+	}
+}
+
+// ActiveAt reports whether the zone applies at a time, local is the time zone of the daily window
+func (zone *Geofence) ActiveAt(at time.Time, local *time.Location) bool {
+	if !zone.Active {
+		return false
+	}
+	if zone.ActiveFrom != nil {
+		if from, err := time.Parse(time.RFC3339, *zone.ActiveFrom); err == nil && at.Before(from) {
+			return false
+		}
+	}
+	if zone.ActiveUntil != nil {
+		if until, err := time.Parse(time.RFC3339, *zone.ActiveUntil); err == nil && !at.Before(until) {
+			return false
+		}
+	}
+	if zone.StartTime == nil || zone.EndTime == nil {
+		return true
+	}
+
+	start, err := minuteOfDay(*zone.StartTime)
+	if err != nil {
+		return true
+	}
+	end, err := minuteOfDay(*zone.EndTime)
+	if err != nil {
+		return true
+	}
+
+	at = at.In(local)
+	minute := at.Hour()*60 + at.Minute()
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// Contains reports whether a position is inside the zone, its border included for circles
+func (zone *Geofence) Contains(lat, lon float64) bool {
+	switch zone.Shape {
+	case GeofenceShapeCircle:
+		if zone.CenterLat == nil || zone.CenterLon == nil || zone.RadiusMeters == nil {
+			return false
+		}
+		return utils.HaversineKm(*zone.CenterLat, *zone.CenterLon, lat, lon)*1000 <= *zone.RadiusMeters
+	case GeofenceShapePolygon:
+		return polygonContains(zone.Points, lat, lon)
+	}
+	return false
+}
+
+// polygonContains reports whether a position is inside a polygon, by ray casting. Lat/lon are
+// treated as planar, which is close enough at city scale.
+func polygonContains(points []GeoPoint, lat, lon float64) bool {
+	inside := false
+	for i, j := 0, len(points)-1; i < len(points); j, i = i, i+1 {
+		a, b := points[i], points[j]
+		if (a.Lat > lat) != (b.Lat > lat) &&
+			lon < (b.Lon-a.Lon)*(lat-a.Lat)/(b.Lat-a.Lat)+a.Lon {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// FindGeofence returns the first zone active at a time that contains a position, nil when there is none
+func FindGeofence(zones []*Geofence, lat, lon float64, at time.Time, local *time.Location) *Geofence {
+	for _, zone := range zones {
+		if zone.ActiveAt(at, local) && zone.Contains(lat, lon) {
+			return zone
+		}
+	}
+	return nil
+}
+
+// GeofenceViolation records a drone found inside an active no-fly zone
+type GeofenceViolation struct {
+	ID           string   `json:"id"`
+	GeofenceID   string   `json:"geofence_id"`
+	GeofenceName string   `json:"geofence_name"`
+	DroneID      string   `json:"drone_id"`
+	OrderID      *string  `json:"order_id,omitempty"`
+	Lat          float64  `json:"lat"`
+	Lon          float64  `json:"lon"`
+	Altitude     *float64 `json:"altitude,omitempty"`
+	CreatedAt    string   `json:"created_at"`
+}
+
+// GeofenceViolationFilter narrows the violations listed for admins
+type GeofenceViolationFilter struct {
+	GeofenceID *string `json:"geofence_id,omitempty"`
+	DroneID    *string `json:"drone_id,omitempty"`
+	Limit      int     `json:"limit,omitempty"`
+}
@@ -1,8 +1,116 @@
 package domain
 
 type HeartbeatRequest struct {
-	Latitude  float64 `json:"latitude" validate:"required,saudilat"`
-	Longitude float64 `json:"longitude" validate:"required,saudilon"`
+	Latitude  float64 `json:"latitude" validate:"required,latitude"`
+	Longitude float64 `json:"longitude" validate:"required,longitude"`
 	Altitude  float64 `json:"altitude" validate:"required,gte=0"`
 	Battery   int     `json:"battery" validate:"required,gte=0,lte=100"`
+	// Commands from the previous heartbeat response the drone carried out
+	AcknowledgedCommandIDs []string `json:"acknowledged_command_ids,omitempty" validate:"omitempty,max=50,dive,uuid"`
+}
+
+// DroneOrderStatuses are the statuses where an order is assigned to the drone working on it
+var DroneOrderStatuses = []OrderStatus{
+	OrderStatusReserved,
+	OrderStatusPickedUp,
+	OrderStatusInTransit,
+	OrderStatusArrived,
+	OrderStatusReassigned,
+	OrderStatusFailed,
+	OrderStatusReturningToOrigin,
+	OrderStatusReturningToSender,
+	OrderStatusDeliveringToDepot,
+}
+
+type HeartbeatWarningCode string
+
+const (
+	HeartbeatWarningLowBattery      HeartbeatWarningCode = "low_battery"
+	HeartbeatWarningCriticalBattery HeartbeatWarningCode = "critical_battery"
+	HeartbeatWarningGeofence        HeartbeatWarningCode = "geofence"
+)
+
+// HeartbeatWarning is a server-side check the drone failed on its last heartbeat
+type HeartbeatWarning struct {
+	Code    HeartbeatWarningCode `json:"code"`
+	Message string               `json:"message"`
+}
+
+// Heartbeat is the drone after a heartbeat together with what it has to know in flight
+type Heartbeat struct {
+	Drone    *Drone
+	Order    *Order
+	Commands []*DroneCommand
+	Warnings []HeartbeatWarning
+	// Where an order delivering_to_depot is dropped off
+	Depot *Depot
+}
+
+// HeartbeatOrder is the order the drone works on, reduced to what it needs in flight
+type HeartbeatOrder struct {
+	ID          string      `json:"id"`
+	OrderNumber string      `json:"order_number"`
+	Status      OrderStatus `json:"status"`
+	// Only set while the package still waits for the drone
+	PickupLat          *float64 `json:"pickup_lat,omitempty"`
+	PickupLon          *float64 `json:"pickup_lon,omitempty"`
+	DropoffAddress     string   `json:"dropoff_address"`
+	DropoffLat         float64  `json:"dropoff_lat"`
+	DropoffLon         float64  `json:"dropoff_lon"`
+	ReceiverName       *string  `json:"receiver_name,omitempty"`
+	DeliveryNote       *string  `json:"delivery_note,omitempty"`
+	EstimatedArrivalAt *string  `json:"estimated_arrival_at,omitempty"`
+}
+
+// HeartbeatResponse is the updated drone, unchanged for older clients, with its current
+// order, the commands waiting for it and the warnings raised by the heartbeat
+type HeartbeatResponse struct {
+	*DroneDTO
+	CurrentOrder *HeartbeatOrder    `json:"current_order"`
+	Commands     []*DroneCommand    `json:"commands"`
+	Warnings     []HeartbeatWarning `json:"warnings"`
+}
+
+func (h *Heartbeat) ToResponse() *HeartbeatResponse {
+	response := &HeartbeatResponse{
+		DroneDTO: h.Drone.ToDTO(),
+		Commands: h.Commands,
+		Warnings: h.Warnings,
+	}
+	if response.Commands == nil {
+		response.Commands = []*DroneCommand{}
+	}
+	if response.Warnings == nil {
+		response.Warnings = []HeartbeatWarning{}
+	}
+
+	if order := h.Order; order != nil {
+		current := &HeartbeatOrder{
+			ID:                 order.ID,
+			OrderNumber:        order.OrderNumber,
+			Status:             order.Status,
+			DropoffAddress:     order.DestinationAddress,
+			DropoffLat:         order.DestinationLat,
+			DropoffLon:         order.DestinationLon,
+			ReceiverName:       order.ReceiverName,
+			DeliveryNote:       order.DeliveryNote,
+			EstimatedArrivalAt: order.EstimatedArrivalAt,
+		}
+		if !order.Status.CarriesPackage() {
+			pickupLat, pickupLon := order.PickupPoint()
+			current.PickupLat, current.PickupLon = &pickupLat, &pickupLon
+		}
+		// A package going back is dropped at the origin
+		if order.Status == OrderStatusReturningToOrigin || order.Status == OrderStatusReturningToSender {
+			current.DropoffAddress = order.OriginAddress
+			current.DropoffLat, current.DropoffLon = order.OriginLat, order.OriginLon
+		}
+		if order.Status == OrderStatusDeliveringToDepot && h.Depot != nil {
+			current.DropoffAddress = h.Depot.Address
+			current.DropoffLat, current.DropoffLon = h.Depot.Lat, h.Depot.Lon
+		}
+		response.CurrentOrder = current
+	}
+
+	return response
 }
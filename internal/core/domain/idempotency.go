@@ -0,0 +1,22 @@
+package domain
+
+const (
+	// IdempotencyKeyHeader carries the client generated key of a retryable request
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotentReplayedHeader is set on responses replayed from a previous request
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// MaxIdempotencyKeyLength caps the length of an idempotency key
+	MaxIdempotencyKeyLength = 255
+)
+
+// IdempotencyRecord is what is remembered about a request sent with an idempotency key.
+// The record is created when the request starts and completed with its response.
+type IdempotencyRecord struct {
+	RequestHash string `json:"request_hash"`
+	Completed   bool   `json:"completed"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
@@ -0,0 +1,40 @@
+package domain
+
+const (
+	// DefaultJobsRadiusKm is the search radius used when a drone does not pass one
+	DefaultJobsRadiusKm = 10.0
+
+	// MaxJobsRadiusKm caps the search radius a drone can ask for
+	MaxJobsRadiusKm = 50.0
+
+	// JobAgeWeightKmPerMinute trades waiting time for distance when ranking jobs,
+	// an order waiting 10 minutes ranks like one 1 km closer
+	JobAgeWeightKmPerMinute = 0.1
+)
+
+// AvailableJob is a reservable order as seen from a drone position
+//
+// Pending orders are picked up at their origin, handoff orders are picked up
+// where the broken drone last reported its position.
+type AvailableJob struct {
+	Order          *OrderDTO `json:"order"`
+	IsHandoff      bool      `json:"is_handoff"`
+	PickupLat      float64   `json:"pickup_lat"`
+	PickupLon      float64   `json:"pickup_lon"`
+	DistanceKm     float64   `json:"distance_km"`
+	TripKm         float64   `json:"trip_km"`
+	WaitingMinutes float64   `json:"waiting_minutes"`
+}
+
+// AvailableJobsQuery describes where a drone is and what it can carry
+type AvailableJobsQuery struct {
+	Lat         float64
+	Lon         float64
+	RadiusKm    float64
+	MaxWeightKg float64
+	MaxRangeKm  float64
+	Limit       int
+
+	// Capabilities the drone is fitted with, orders requiring anything else are left out
+	Capabilities []DroneCapability
+}
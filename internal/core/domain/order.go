@@ -1,15 +1,26 @@
 package domain
 
+import "time"
+
 type OrderStatus string
 
+// DefaultScheduledOrdersWindow is how far ahead upcoming scheduled orders are listed by default
+const DefaultScheduledOrdersWindow = 24 * time.Hour
+
 // Based on the order status constants, here's the difference between `picked_up` and `in_transit`:
 // **`picked_up`**: The drone has successfully picked up the package from the origin location but hasn't started moving toward the destination yet.
 // **`in_transit`**: The drone is actively traveling with the package from the origin to the destination location.
+// Orders with a `scheduled_at` further away than the release lead time start as `scheduled`
+// and are released to `pending` by the scheduler.
 // The flow would typically be: `pending` → `reserved` → `picked_up` → `in_transit` → `arrived` → `delivered`
-// The flow would typically be: `handoff` → `reassigned` → `in_transit`→ `arrived` → `delivered`
+// The flow would typically be: `handoff` → `reassigned` → `picked_up` → `in_transit`→ `arrived` → `delivered`,
+// where `reassigned` is the rescue drone on its way to the pickup point left by the previous drone
+// After a `failed` delivery the failure policy re-queues the order with `returning_to_origin` → `pending`,
+// sends it back with `returning_to_sender` → `returned`, or drops it at a depot with `delivering_to_depot` → `held_at_depot`
 // So `picked_up` is the moment of collection, while `in_transit` indicates active delivery movement.
 
 const (
+	OrderStatusScheduled  OrderStatus = "scheduled"
 	OrderStatusPending    OrderStatus = "pending"
 	OrderStatusReserved   OrderStatus = "reserved"
 	OrderStatusPickedUp   OrderStatus = "picked_up"
@@ -20,80 +31,136 @@ const (
 	OrderStatusCancelled  OrderStatus = "cancelled"
 	OrderStatusHandoff    OrderStatus = "handoff"
 	OrderStatusReassigned OrderStatus = "reassigned"
+
+	OrderStatusReturningToOrigin OrderStatus = "returning_to_origin"
+	OrderStatusReturningToSender OrderStatus = "returning_to_sender"
+	OrderStatusReturned          OrderStatus = "returned"
+	OrderStatusDeliveringToDepot OrderStatus = "delivering_to_depot"
+	OrderStatusHeldAtDepot       OrderStatus = "held_at_depot"
 )
 
 type Order struct {
 	BaseModel
-	OrderNumber          string      `json:"order_number" gorm:"uniqueIndex"`
-	UserID               string      `json:"user_id"`
-	ReceiverName         *string     `json:"receiver_name,omitempty"`
-	ReceiverPhone        *string     `json:"receiver_phone,omitempty"`
-	DeliveryNote         *string     `json:"delivery_note,omitempty"`
-	PackageWeightKg      *float64    `json:"package_weight_kg,omitempty"`
-	OriginAddress        string      `json:"origin_address"`
-	OriginLat            float64     `json:"origin_lat"`
-	OriginLon            float64     `json:"origin_lon"`
-	DestinationAddress   string      `json:"destination_address"`
-	DestinationLat       float64     `json:"destination_lat"`
-	DestinationLon       float64     `json:"destination_lon"`
-	Status               OrderStatus `json:"status" gorm:"default:pending"`
-	ScheduledAt          *string     `json:"scheduled_at,omitempty"`
-	DeliveredAt          *string     `json:"delivered_at,omitempty"`
-	CancelledAt          *string     `json:"cancelled_at,omitempty"`
-	DroneID              *string     `json:"drone_id"`
-	DeliveredByDroneID   *string     `json:"delivered_by_drone_id,omitempty"`
-	WithdrawnAt          *string     `json:"withdrawn_at,omitempty"`
-	CurrentLat           *float64    `json:"current_lat,omitempty"`
-	CurrentLon           *float64    `json:"current_lon,omitempty"`
-	CurrentAltitude      *float64    `json:"current_altitude,omitempty"`
-	LastLocationUpdateAt *string     `json:"last_location_update_at,omitempty"`
-	EstimatedArrivalAt   *string     `json:"estimated_arrival_at,omitempty"`
+	OrderNumber          string            `json:"order_number" gorm:"uniqueIndex"`
+	UserID               string            `json:"user_id"`
+	ReceiverName         *string           `json:"receiver_name,omitempty"`
+	ReceiverPhone        *string           `json:"receiver_phone,omitempty"`
+	DeliveryNote         *string           `json:"delivery_note,omitempty"`
+	PackageWeightKg      *float64          `json:"package_weight_kg,omitempty"`
+	OriginAddress        string            `json:"origin_address"`
+	OriginLat            float64           `json:"origin_lat"`
+	OriginLon            float64           `json:"origin_lon"`
+	DestinationAddress   string            `json:"destination_address"`
+	DestinationLat       float64           `json:"destination_lat"`
+	DestinationLon       float64           `json:"destination_lon"`
+	Status               OrderStatus       `json:"status" gorm:"default:pending"`
+	ScheduledAt          *string           `json:"scheduled_at,omitempty"`
+	DeliveredAt          *string           `json:"delivered_at,omitempty"`
+	CancelledAt          *string           `json:"cancelled_at,omitempty"`
+	DroneID              *string           `json:"drone_id"`
+	DeliveredByDroneID   *string           `json:"delivered_by_drone_id,omitempty"`
+	WithdrawnAt          *string           `json:"withdrawn_at,omitempty"`
+	CurrentLat           *float64          `json:"current_lat,omitempty"`
+	CurrentLon           *float64          `json:"current_lon,omitempty"`
+	CurrentAltitude      *float64          `json:"current_altitude,omitempty"`
+	LastLocationUpdateAt *string           `json:"last_location_update_at,omitempty"`
+	EstimatedArrivalAt   *string           `json:"estimated_arrival_at,omitempty"`
+	CancellationReason   *string           `json:"cancellation_reason,omitempty"`
+	CancellationNote     *string           `json:"cancellation_note,omitempty"`
+	CancelledByID        *string           `json:"cancelled_by_id,omitempty"`
+	Price                *float64          `json:"price,omitempty"`
+	Currency             *string           `json:"currency,omitempty"`
+	QuoteID              *string           `json:"quote_id,omitempty"`
+	Priority             OrderPriority     `json:"priority"`
+	RequiredCapabilities []DroneCapability `json:"required_capabilities"`
+	Version              int               `json:"version"`
+	// Failed delivery attempts so far and what the failure policy did after the last one
+	DeliveryAttempts    int                    `json:"delivery_attempts"`
+	MaxDeliveryAttempts int                    `json:"max_delivery_attempts"`
+	FailureAction       *DeliveryFailureAction `json:"failure_action,omitempty"`
+	// Service area the order was placed in
+	ServiceAreaID *string `json:"service_area_id,omitempty"`
 }
 
 type OrderDTO struct {
-	ID                   string      `json:"id"`
-	Status               OrderStatus `json:"status"`
-	OrderNumber          string      `json:"order_number"`
-	UserID               string      `json:"user_id"`
-	ReceiverName         *string     `json:"receiver_name,omitempty"`
-	ReceiverPhone        *string     `json:"receiver_phone,omitempty"`
-	DeliveryNote         *string     `json:"delivery_note,omitempty"`
-	PackageWeightKg      *float64    `json:"package_weight_kg,omitempty"`
-	OriginAddress        string      `json:"origin_address"`
-	OriginLat            float64     `json:"origin_lat"`
-	OriginLon            float64     `json:"origin_lon"`
-	DestinationAddress   string      `json:"destination_address"`
-	DestinationLat       float64     `json:"destination_lat"`
-	DestinationLon       float64     `json:"destination_lon"`
-	ScheduledAt          *string     `json:"scheduled_at"`
-	DeliveredAt          *string     `json:"delivered_at"`
-	CancelledAt          *string     `json:"cancelled_at"`
-	DroneID              *string     `json:"drone_id"`
-	DeliveredByDroneID   *string     `json:"delivered_by_drone_id"`
-	CreatedAt            string      `json:"created_at"`
-	UpdatedAt            string      `json:"updated_at"`
-	Active               bool        `json:"active"`
-	CreatedByID          *string     `json:"created_by_id"`
-	UpdatedByID          *string     `json:"updated_by_id"`
-	WithdrawnAt          *string     `json:"withdrawn_at"`
-	CurrentLat           *float64    `json:"current_lat"`
-	CurrentLon           *float64    `json:"current_lon"`
-	CurrentAltitude      *float64    `json:"current_altitude"`
-	LastLocationUpdateAt *string     `json:"last_location_update_at"`
-	EstimatedArrivalAt   *string     `json:"estimated_arrival_at"`
+	ID                   string            `json:"id"`
+	Status               OrderStatus       `json:"status"`
+	OrderNumber          string            `json:"order_number"`
+	UserID               string            `json:"user_id"`
+	ReceiverName         *string           `json:"receiver_name,omitempty"`
+	ReceiverPhone        *string           `json:"receiver_phone,omitempty"`
+	DeliveryNote         *string           `json:"delivery_note,omitempty"`
+	PackageWeightKg      *float64          `json:"package_weight_kg,omitempty"`
+	OriginAddress        string            `json:"origin_address"`
+	OriginLat            float64           `json:"origin_lat"`
+	OriginLon            float64           `json:"origin_lon"`
+	DestinationAddress   string            `json:"destination_address"`
+	DestinationLat       float64           `json:"destination_lat"`
+	DestinationLon       float64           `json:"destination_lon"`
+	ScheduledAt          *string           `json:"scheduled_at"`
+	DeliveredAt          *string           `json:"delivered_at"`
+	CancelledAt          *string           `json:"cancelled_at"`
+	DroneID              *string           `json:"drone_id"`
+	DeliveredByDroneID   *string           `json:"delivered_by_drone_id"`
+	CreatedAt            string            `json:"created_at"`
+	UpdatedAt            string            `json:"updated_at"`
+	Active               bool              `json:"active"`
+	CreatedByID          *string           `json:"created_by_id"`
+	UpdatedByID          *string           `json:"updated_by_id"`
+	WithdrawnAt          *string           `json:"withdrawn_at"`
+	CurrentLat           *float64          `json:"current_lat"`
+	CurrentLon           *float64          `json:"current_lon"`
+	CurrentAltitude      *float64          `json:"current_altitude"`
+	LastLocationUpdateAt *string           `json:"last_location_update_at"`
+	EstimatedArrivalAt   *string           `json:"estimated_arrival_at"`
+	CancellationReason   *string           `json:"cancellation_reason"`
+	CancellationNote     *string           `json:"cancellation_note"`
+	CancelledByID        *string           `json:"cancelled_by_id"`
+	Price                *float64          `json:"price"`
+	Currency             *string           `json:"currency"`
+	QuoteID              *string           `json:"quote_id"`
+	Priority             OrderPriority     `json:"priority"`
+	RequiredCapabilities []DroneCapability `json:"required_capabilities"`
+	Version              int               `json:"version"`
+	// Failed delivery attempts so far and what the failure policy did after the last one
+	DeliveryAttempts    int                    `json:"delivery_attempts"`
+	MaxDeliveryAttempts int                    `json:"max_delivery_attempts"`
+	FailureAction       *DeliveryFailureAction `json:"failure_action,omitempty"`
+	// Service area the order was placed in
+	ServiceAreaID *string `json:"service_area_id,omitempty"`
 }
 type CreateOrderRequest struct {
-	ReceiverName       *string  `json:"receiver_name" validate:"omitempty,min=1"`
-	ReceiverPhone      *string  `json:"receiver_phone" validate:"saudiphonenumber,min=10"`
-	DeliveryNote       *string  `json:"delivery_note" validate:"omitempty,max=255"`
-	PackageWeightKg    *float64 `json:"package_weight_kg,omitempty" validate:"omitempty,gt=0,lte=100"`
-	OriginAddress      string   `json:"origin_address" validate:"required,min=1"`
-	OriginLat          float64  `json:"origin_lat" validate:"required,saudilat"`
-	OriginLon          float64  `json:"origin_lon" validate:"required,saudilon"`
-	DestinationAddress string   `json:"destination_address" validate:"required,min=1"`
-	DestinationLat     float64  `json:"destination_lat" validate:"required,saudilat,nefield=OriginLat"`
-	DestinationLon     float64  `json:"destination_lon" validate:"required,saudilon"`
-	ScheduledAt        *string  `json:"scheduled_at,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	ReceiverName         *string           `json:"receiver_name" validate:"omitempty,min=1"`
+	ReceiverPhone        *string           `json:"receiver_phone" validate:"phonenumber,min=10"`
+	DeliveryNote         *string           `json:"delivery_note" validate:"omitempty,max=255"`
+	PackageWeightKg      *float64          `json:"package_weight_kg,omitempty" validate:"omitempty,gt=0,lte=100"`
+	OriginAddress        string            `json:"origin_address" validate:"required,min=1"`
+	OriginLat            float64           `json:"origin_lat" validate:"required,latitude"`
+	OriginLon            float64           `json:"origin_lon" validate:"required,longitude"`
+	DestinationAddress   string            `json:"destination_address" validate:"required,min=1"`
+	DestinationLat       float64           `json:"destination_lat" validate:"required,latitude,nefield=OriginLat"`
+	DestinationLon       float64           `json:"destination_lon" validate:"required,longitude"`
+	ScheduledAt          *string           `json:"scheduled_at,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	QuoteID              *string           `json:"quote_id,omitempty" validate:"omitempty,min=1,max=2048"`
+	Priority             OrderPriority     `json:"priority,omitempty" validate:"omitempty,oneof=standard express critical_medical"`
+	RequiredCapabilities []DroneCapability `json:"required_capabilities,omitempty" validate:"omitempty,unique,dive,oneof=cold_chain heavy_lift secure_box"`
+
+	// Initial status, set by the service from ScheduledAt
+	Status OrderStatus `json:"-"`
+
+	// One-time code for the receiver, set by the service
+	DeliveryCode *DeliveryCode `json:"-"`
+
+	// Price locked in at creation and the quote it came from, set by the service
+	Price          *float64 `json:"-"`
+	Currency       *string  `json:"-"`
+	QuoteReference *string  `json:"-"`
+
+	// Delivery attempts allowed before the order is no longer re-queued, set by the service
+	MaxDeliveryAttempts int `json:"-"`
+
+	// Service area containing the origin and destination, set by the service
+	ServiceAreaID *string `json:"-"`
 }
 
 type UpdateOrderRequest struct {
@@ -120,7 +187,11 @@ type UpdateOrderRequest struct {
 	CurrentAltitude      *float64     `json:"current_altitude,omitempty"`
 	LastLocationUpdateAt *string      `json:"last_location_update_at,omitempty"`
 	EstimatedArrivalAt   *string      `json:"estimated_arrival_at,omitempty"`
+	StatusReason         *string      `json:"status_reason,omitempty" validate:"omitempty,max=255"`
 	UpdatedByID          *string      `json:"updated_by_id"`
+
+	// Version the caller last read (If-Match), the update fails when the order moved on
+	ExpectedVersion *int `json:"-"`
 }
 
 type UpdateStatusRequest struct {
@@ -131,31 +202,37 @@ type UpdateStatusRequest struct {
 	DeliveredAt *string `json:"delivered_at,omitempty"`
 	FailAt      *string `json:"fail_at,omitempty"`
 	WithdrawnAt *string `json:"withdrawn_at,omitempty"`
+	Reason      *string `json:"reason,omitempty"`
+
+	// Proof recorded with the delivered transition
+	Proof *DeliveryProofUpdate `json:"-"`
 }
 
 type UpdateOrderLocationRequest struct {
-	Lat      float64 `json:"lat" validate:"required,saudilat"`
-	Lng      float64 `json:"lng" validate:"required,saudilon"`
+	Lat      float64 `json:"lat" validate:"required,latitude"`
+	Lng      float64 `json:"lng" validate:"required,longitude"`
 	Alti     float64 `json:"alti" validate:"required,gte=0,lte=5000"`
 	SpeedKmh float64 `json:"speed_kmh" validate:"required,gte=0"`
 }
 
 type OrderFilter struct {
-	Status             *OrderStatus `json:"status,omitempty"`
-	UserID             *string      `json:"user_id,omitempty"`
-	Active             *bool        `json:"active,omitempty"`
-	DroneID            *string      `json:"drone_id,omitempty"`
-	DeliveredByDroneID *string      `json:"delivered_by_drone_id,omitempty"`
-	DestinationAddress *string      `json:"destination_address,omitempty"`
-	CreatedAtFrom      *string      `json:"created_at_from,omitempty"`
-	CreatedAtTo        *string      `json:"created_at_to,omitempty"`
-	ScheduledAtFrom    *string      `json:"scheduled_at_from,omitempty"`
-	ScheduledAtTo      *string      `json:"scheduled_at_to,omitempty"`
-	MinWeight          *float64     `json:"min_weight,omitempty"`
-	MaxWeight          *float64     `json:"max_weight,omitempty"`
-	ReceiverPhone      *string      `json:"receiver_phone,omitempty"`
-	ReceiverName       *string      `json:"receiver_name,omitempty"`
-	OriginAddress      *string      `json:"origin_address,omitempty"`
+	Status             *OrderStatus   `json:"status,omitempty"`
+	Statuses           []OrderStatus  `json:"statuses,omitempty"`
+	UserID             *string        `json:"user_id,omitempty"`
+	Active             *bool          `json:"active,omitempty"`
+	DroneID            *string        `json:"drone_id,omitempty"`
+	DeliveredByDroneID *string        `json:"delivered_by_drone_id,omitempty"`
+	DestinationAddress *string        `json:"destination_address,omitempty"`
+	CreatedAtFrom      *string        `json:"created_at_from,omitempty"`
+	CreatedAtTo        *string        `json:"created_at_to,omitempty"`
+	ScheduledAtFrom    *string        `json:"scheduled_at_from,omitempty"`
+	ScheduledAtTo      *string        `json:"scheduled_at_to,omitempty"`
+	MinWeight          *float64       `json:"min_weight,omitempty"`
+	MaxWeight          *float64       `json:"max_weight,omitempty"`
+	ReceiverPhone      *string        `json:"receiver_phone,omitempty"`
+	ReceiverName       *string        `json:"receiver_name,omitempty"`
+	OriginAddress      *string        `json:"origin_address,omitempty"`
+	Priority           *OrderPriority `json:"priority,omitempty"`
 }
 
 func (o *Order) ToDTO() *OrderDTO {
@@ -188,11 +265,25 @@ func (o *Order) ToDTO() *OrderDTO {
 		CurrentAltitude:      o.CurrentAltitude,
 		LastLocationUpdateAt: o.LastLocationUpdateAt,
 		EstimatedArrivalAt:   o.EstimatedArrivalAt,
+		CancellationReason:   o.CancellationReason,
+		CancellationNote:     o.CancellationNote,
+		CancelledByID:        o.CancelledByID,
+		Price:                o.Price,
+		Currency:             o.Currency,
+		QuoteID:              o.QuoteID,
+		Priority:             o.Priority,
+		RequiredCapabilities: o.RequiredCapabilities,
+		Version:              o.Version,
+		DeliveryAttempts:     o.DeliveryAttempts,
+		MaxDeliveryAttempts:  o.MaxDeliveryAttempts,
+		FailureAction:        o.FailureAction,
+		ServiceAreaID:        o.ServiceAreaID,
 	}
 }
 
 func (filter OrderFilter) IsEmpty() bool {
 	return filter.Status == nil &&
+		len(filter.Statuses) == 0 &&
 		filter.UserID == nil &&
 		filter.Active == nil &&
 		filter.DroneID == nil &&
@@ -206,9 +297,190 @@ func (filter OrderFilter) IsEmpty() bool {
 		filter.MaxWeight == nil &&
 		filter.ReceiverPhone == nil &&
 		filter.ReceiverName == nil &&
-		filter.OriginAddress == nil
+		filter.OriginAddress == nil &&
+		filter.Priority == nil
 }
 
 func (order *Order) IsReserved() bool {
 	return order.Status == OrderStatusReserved || order.Status == OrderStatusPickedUp || order.Status == OrderStatusInTransit || order.Status == OrderStatusArrived || order.Status == OrderStatusDelivered
 }
+
+// OrderStatuses lists every order status, in lifecycle order
+var OrderStatuses = []OrderStatus{
+	OrderStatusScheduled,
+	OrderStatusPending,
+	OrderStatusReserved,
+	OrderStatusPickedUp,
+	OrderStatusInTransit,
+	OrderStatusArrived,
+	OrderStatusDelivered,
+	OrderStatusFailed,
+	OrderStatusCancelled,
+	OrderStatusHandoff,
+	OrderStatusReassigned,
+	OrderStatusReturningToOrigin,
+	OrderStatusReturningToSender,
+	OrderStatusReturned,
+	OrderStatusDeliveringToDepot,
+	OrderStatusHeldAtDepot,
+}
+
+// Allowed transitions:
+//
+//	scheduled -> pending, cancelled
+//	pending -> reserved, cancelled
+//	reserved -> picked_up, handoff, pending (reservation expired)
+//	picked_up -> in_transit, failed, handoff
+//	in_transit -> arrived, failed, handoff
+//	arrived -> delivered, failed, handoff
+//	failed -> handoff, returning_to_origin, returning_to_sender, delivering_to_depot
+//	handoff -> reassigned
+//	reassigned -> picked_up, handoff
+//	returning_to_origin -> pending (return confirmed), handoff
+//	returning_to_sender -> returned, handoff
+//	delivering_to_depot -> held_at_depot (drop-off confirmed), handoff
+//	held_at_depot -> handoff
+//	delivered, cancelled, returned -> none (final)
+func (status OrderStatus) IsTransitionAllowed(from OrderStatus) bool {
+	// Any order that is still open can be cancelled
+	if status == OrderStatusCancelled {
+		return from.IsValid() && !from.IsFinal()
+	}
+
+	switch from {
+	case OrderStatusScheduled:
+		return status == OrderStatusPending
+	case OrderStatusPending:
+		return status == OrderStatusReserved
+	case OrderStatusReserved:
+		return status == OrderStatusPickedUp || status == OrderStatusHandoff || status == OrderStatusPending
+	case OrderStatusPickedUp:
+		return status == OrderStatusInTransit || status == OrderStatusFailed || status == OrderStatusHandoff
+	case OrderStatusInTransit:
+		return status == OrderStatusArrived || status == OrderStatusFailed || status == OrderStatusHandoff
+	case OrderStatusArrived:
+		return status == OrderStatusDelivered || status == OrderStatusFailed || status == OrderStatusHandoff
+	case OrderStatusFailed:
+		return status == OrderStatusHandoff || status == OrderStatusReturningToOrigin || status == OrderStatusReturningToSender || status == OrderStatusDeliveringToDepot
+	case OrderStatusHandoff:
+		return status == OrderStatusReassigned
+	case OrderStatusReassigned:
+		return status == OrderStatusPickedUp || status == OrderStatusHandoff
+	case OrderStatusReturningToOrigin:
+		return status == OrderStatusPending || status == OrderStatusHandoff
+	case OrderStatusReturningToSender:
+		return status == OrderStatusReturned || status == OrderStatusHandoff
+	case OrderStatusDeliveringToDepot:
+		return status == OrderStatusHeldAtDepot || status == OrderStatusHandoff
+	case OrderStatusHeldAtDepot:
+		return status == OrderStatusHandoff
+	default:
+		return false
+	}
+}
+
+func (status OrderStatus) TransitionErr() error {
+	switch status {
+	case OrderStatusScheduled:
+		return ErrOrderScheduledTransition
+	case OrderStatusPending:
+		return ErrOrderPendingTransition
+	case OrderStatusReserved:
+		return ErrOrderReservedTransition
+	case OrderStatusPickedUp:
+		return ErrOrderPickedUpTransition
+	case OrderStatusInTransit:
+		return ErrOrderInTransitTransition
+	case OrderStatusArrived:
+		return ErrOrderArrivedTransition
+	case OrderStatusDelivered:
+		return ErrOrderDeliveredTransition
+	case OrderStatusFailed:
+		return ErrOrderFailedTransition
+	case OrderStatusCancelled:
+		return ErrOrderCancelledTransition
+	case OrderStatusHandoff:
+		return ErrOrderHandoffTransition
+	case OrderStatusReassigned:
+		return ErrOrderReassignedTransition
+	case OrderStatusReturningToOrigin:
+		return ErrOrderReturningToOriginTransition
+	case OrderStatusReturningToSender:
+		return ErrOrderReturningToSenderTransition
+	case OrderStatusReturned:
+		return ErrOrderReturnedTransition
+	case OrderStatusDeliveringToDepot:
+		return ErrOrderDeliveringToDepotTransition
+	case OrderStatusHeldAtDepot:
+		return ErrOrderHeldAtDepotTransition
+	default:
+		return ErrInvalidOrderStatus
+	}
+}
+
+// CarryingStatuses are the statuses where the drone assigned to an order has the package on board
+var CarryingStatuses = []OrderStatus{
+	OrderStatusPickedUp,
+	OrderStatusInTransit,
+	OrderStatusArrived,
+	OrderStatusFailed,
+	OrderStatusReturningToOrigin,
+	OrderStatusReturningToSender,
+	OrderStatusDeliveringToDepot,
+}
+
+// CarriesPackage reports whether the drone assigned to an order in status has the package on board
+func (status OrderStatus) CarriesPackage() bool {
+	for _, s := range CarryingStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// ReturnConfirmedStatus is the status an order on its way back moves to once the
+// drone confirms the drop-off, and false when the order is not on its way back
+func (status OrderStatus) ReturnConfirmedStatus() (OrderStatus, bool) {
+	switch status {
+	case OrderStatusReturningToOrigin:
+		return OrderStatusPending, true
+	case OrderStatusReturningToSender:
+		return OrderStatusReturned, true
+	case OrderStatusDeliveringToDepot:
+		return OrderStatusHeldAtDepot, true
+	default:
+		return "", false
+	}
+}
+
+// IsFinal reports whether no transition can leave status
+func (status OrderStatus) IsFinal() bool {
+	return status == OrderStatusDelivered || status == OrderStatusCancelled || status == OrderStatusReturned
+}
+
+// IsBeforePickup reports whether the package still waits at the origin in status
+func (status OrderStatus) IsBeforePickup() bool {
+	return status == OrderStatusScheduled || status == OrderStatusPending || status == OrderStatusReserved
+}
+
+// IsValid reports whether status is one of the known order statuses
+func (status OrderStatus) IsValid() bool {
+	for _, s := range OrderStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedFrom returns the statuses an order can move to status from
+func (status OrderStatus) AllowedFrom() []OrderStatus {
+	var from []OrderStatus
+	for _, s := range OrderStatuses {
+		if status.IsTransitionAllowed(s) {
+			from = append(from, s)
+		}
+	}
+	return from
+}
@@ -0,0 +1,33 @@
+package domain
+
+const (
+	// MaxBulkOrders caps the number of rows accepted by a single bulk request
+	MaxBulkOrders = 1000
+
+	// BulkOrdersChunkSize is the number of orders inserted per transaction
+	BulkOrdersChunkSize = 100
+)
+
+// BulkOrderRow is a validated row of a bulk request, Row is 1-based
+type BulkOrderRow struct {
+	Row   int
+	Order *CreateOrderRequest
+}
+
+// BulkOrderResult reports the outcome of a single bulk row
+type BulkOrderResult struct {
+	Row         int              `json:"row"`
+	OrderID     *string          `json:"order_id,omitempty"`
+	OrderNumber *string          `json:"order_number,omitempty"`
+	Status      *OrderStatus     `json:"status,omitempty"`
+	Errors      ValidationErrors `json:"errors,omitempty"`
+	Error       *string          `json:"error,omitempty"`
+}
+
+// BulkCreateOrdersResponse is returned by the bulk create endpoint
+type BulkCreateOrdersResponse struct {
+	Total   int               `json:"total"`
+	Created int               `json:"created"`
+	Failed  int               `json:"failed"`
+	Results []BulkOrderResult `json:"results"`
+}
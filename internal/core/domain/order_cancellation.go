@@ -0,0 +1,35 @@
+package domain
+
+type CancellationReason string
+
+// Reason codes an admin picks when cancelling an order
+const (
+	CancellationReasonCustomerRequest  CancellationReason = "customer_request"
+	CancellationReasonInvalidAddress   CancellationReason = "invalid_address"
+	CancellationReasonPackageIssue     CancellationReason = "package_issue"
+	CancellationReasonDroneUnavailable CancellationReason = "drone_unavailable"
+	CancellationReasonWeather          CancellationReason = "weather"
+	CancellationReasonFraud            CancellationReason = "fraud"
+	CancellationReasonOther            CancellationReason = "other"
+)
+
+type CancelOrderRequest struct {
+	ReasonCode CancellationReason `json:"reason_code" validate:"required,oneof=customer_request invalid_address package_issue drone_unavailable weather fraud other"`
+	Note       *string            `json:"note,omitempty" validate:"omitempty,max=1000"`
+}
+
+// OrderCancellation is the outcome of a cancellation. DroneID is the drone that was released,
+// Command is the return-to-origin instruction queued when the package was already on board.
+type OrderCancellation struct {
+	Order          *Order
+	PreviousStatus OrderStatus
+	DroneID        *string
+	Command        *DroneCommand
+}
+
+// ExpiredReservation is a reserved order returned to the pool because its pickup was
+// not confirmed in time. DroneID is the drone that held the reservation.
+type ExpiredReservation struct {
+	Order   *Order
+	DroneID *string
+}
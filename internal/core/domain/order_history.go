@@ -0,0 +1,60 @@
+package domain
+
+// Reasons recorded with transitions triggered by the system rather than a request
+const (
+	OrderStatusReasonCreated      = "created"
+	OrderStatusReasonAutoDispatch = "auto_dispatch"
+	OrderStatusReasonClaimed      = "claimed"
+	OrderStatusReasonDroneBroken  = "drone_broken"
+	OrderStatusReasonDroneLost    = "drone_lost"
+	OrderStatusReasonWithdrawn    = "withdrawn"
+	OrderStatusReasonReleased     = "released"
+	OrderStatusReasonRescue       = "rescue"
+	// The drone did not confirm the pickup within the reservation TTL
+	OrderStatusReasonReservationExpired = "reservation_expired"
+)
+
+// OrderStatusHistory is one recorded transition of an order.
+// FromStatus is nil for the entry written when the order is created,
+// ActorID is nil when the system made the change.
+// Lat, Lon and Altitude hold the drone position at the time, or the package
+// position when no drone was involved.
+type OrderStatusHistory struct {
+	ID              string       `json:"id"`
+	OrderID         string       `json:"order_id"`
+	FromStatus      *OrderStatus `json:"from_status"`
+	ToStatus        OrderStatus  `json:"to_status"`
+	DroneID         *string      `json:"drone_id"`
+	DroneIdentifier *string      `json:"drone_identifier"`
+	ActorID         *string      `json:"actor_id"`
+	Reason          *string      `json:"reason,omitempty"`
+	Lat             *float64     `json:"lat"`
+	Lon             *float64     `json:"lon"`
+	Altitude        *float64     `json:"altitude"`
+	CreatedAt       string       `json:"created_at"`
+}
+
+// OrderTimeline is the ordered list of lifecycle steps of an order
+type OrderTimeline struct {
+	OrderID     string                `json:"order_id"`
+	OrderNumber string                `json:"order_number"`
+	Status      OrderStatus           `json:"status"`
+	Steps       []*OrderStatusHistory `json:"steps"`
+	Carriers    []*OrderCarrier       `json:"carriers"`
+}
+
+// OrderCarrier is one leg of an order carried by a drone. ReleasedAt is nil while
+// the drone still has the package, ReleaseStatus tells how the leg ended.
+type OrderCarrier struct {
+	ID              string       `json:"id"`
+	OrderID         string       `json:"order_id"`
+	DroneID         string       `json:"drone_id"`
+	DroneIdentifier *string      `json:"drone_identifier"`
+	PickupLat       *float64     `json:"pickup_lat"`
+	PickupLon       *float64     `json:"pickup_lon"`
+	PickedUpAt      string       `json:"picked_up_at"`
+	ReleaseLat      *float64     `json:"release_lat"`
+	ReleaseLon      *float64     `json:"release_lon"`
+	ReleasedAt      *string      `json:"released_at"`
+	ReleaseStatus   *OrderStatus `json:"release_status,omitempty"`
+}
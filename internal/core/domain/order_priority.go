@@ -0,0 +1,49 @@
+package domain
+
+type OrderPriority string
+
+// Priority tiers an order can be booked with, each tier has its own tariff
+const (
+	OrderPriorityStandard        OrderPriority = "standard"
+	OrderPriorityExpress         OrderPriority = "express"
+	OrderPriorityCriticalMedical OrderPriority = "critical_medical"
+)
+
+var OrderPriorities = []OrderPriority{
+	OrderPriorityStandard,
+	OrderPriorityExpress,
+	OrderPriorityCriticalMedical,
+}
+
+func (p OrderPriority) IsValid() bool {
+	for _, priority := range OrderPriorities {
+		if p == priority {
+			return true
+		}
+	}
+	return false
+}
+
+// OrDefault returns the standard tier for an empty priority
+func (p OrderPriority) OrDefault() OrderPriority {
+	if p == "" {
+		return OrderPriorityStandard
+	}
+	return p
+}
+
+// OrderPriorityHeadStartKm is the ranking head start of each tier, in the same
+// km unit as JobAgeWeightKmPerMinute. Waiting time keeps adding to every order,
+// so a standard order waiting long enough still overtakes fresh express or
+// medical orders and is never starved: at 0.1 km per minute, a standard order
+// catches up on a critical medical one after 5 hours.
+var OrderPriorityHeadStartKm = map[OrderPriority]float64{
+	OrderPriorityStandard:        0,
+	OrderPriorityExpress:         5,
+	OrderPriorityCriticalMedical: 30,
+}
+
+// HeadStartKm returns the ranking head start of the tier
+func (p OrderPriority) HeadStartKm() float64 {
+	return OrderPriorityHeadStartKm[p.OrDefault()]
+}
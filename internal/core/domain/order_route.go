@@ -0,0 +1,95 @@
+package domain
+
+import "drones/pkg/utils"
+
+// AuditActionChangeRoute is the audit log action recorded for a route change
+const AuditActionChangeRoute = "CHANGE_ROUTE"
+
+// ChangeRouteRequest moves the origin, the destination or both. Fields left out keep their value.
+type ChangeRouteRequest struct {
+	OriginAddress      *string  `json:"origin_address,omitempty" validate:"required_with=OriginLat OriginLon,omitempty,min=1"`
+	OriginLat          *float64 `json:"origin_lat,omitempty" validate:"required_with=OriginLon OriginAddress,omitempty,latitude"`
+	OriginLon          *float64 `json:"origin_lon,omitempty" validate:"required_with=OriginLat OriginAddress,omitempty,longitude"`
+	DestinationAddress *string  `json:"destination_address,omitempty" validate:"required_with=DestinationLat DestinationLon,omitempty,min=1"`
+	DestinationLat     *float64 `json:"destination_lat,omitempty" validate:"required_with=DestinationLon DestinationAddress,omitempty,latitude"`
+	DestinationLon     *float64 `json:"destination_lon,omitempty" validate:"required_with=DestinationLat DestinationAddress,omitempty,longitude"`
+	Reason             *string  `json:"reason,omitempty" validate:"omitempty,max=255"`
+
+	// Version the caller last read (If-Match), the change fails when the order moved on
+	ExpectedVersion *int `json:"-"`
+}
+
+// ChangesOrigin reports whether the request moves the pickup point, validation makes
+// the address and both coordinates come together
+func (request *ChangeRouteRequest) ChangesOrigin() bool {
+	return request.OriginAddress != nil && request.OriginLat != nil && request.OriginLon != nil
+}
+
+// ChangesDestination reports whether the request moves the drop-off point
+func (request *ChangeRouteRequest) ChangesDestination() bool {
+	return request.DestinationAddress != nil && request.DestinationLat != nil && request.DestinationLon != nil
+}
+
+// OrderRoute is the part of an order a route change touches, it is what the audit entry records
+type OrderRoute struct {
+	OriginAddress      string   `json:"origin_address"`
+	OriginLat          float64  `json:"origin_lat"`
+	OriginLon          float64  `json:"origin_lon"`
+	DestinationAddress string   `json:"destination_address"`
+	DestinationLat     float64  `json:"destination_lat"`
+	DestinationLon     float64  `json:"destination_lon"`
+	DistanceKm         float64  `json:"distance_km"`
+	Price              *float64 `json:"price,omitempty"`
+	EstimatedArrivalAt *string  `json:"estimated_arrival_at,omitempty"`
+}
+
+// Route returns the current route of the order
+func (order *Order) Route() OrderRoute {
+	return OrderRoute{
+		OriginAddress:      order.OriginAddress,
+		OriginLat:          order.OriginLat,
+		OriginLon:          order.OriginLon,
+		DestinationAddress: order.DestinationAddress,
+		DestinationLat:     order.DestinationLat,
+		DestinationLon:     order.DestinationLon,
+		DistanceKm:         utils.HaversineKm(order.OriginLat, order.OriginLon, order.DestinationLat, order.DestinationLon),
+		Price:              order.Price,
+		EstimatedArrivalAt: order.EstimatedArrivalAt,
+	}
+}
+
+// Apply returns the route with the changes of the request
+func (route OrderRoute) Apply(request *ChangeRouteRequest) OrderRoute {
+	if request.ChangesOrigin() {
+		route.OriginAddress = *request.OriginAddress
+		route.OriginLat = *request.OriginLat
+		route.OriginLon = *request.OriginLon
+	}
+	if request.ChangesDestination() {
+		route.DestinationAddress = *request.DestinationAddress
+		route.DestinationLat = *request.DestinationLat
+		route.DestinationLon = *request.DestinationLon
+	}
+	route.DistanceKm = utils.HaversineKm(route.OriginLat, route.OriginLon, route.DestinationLat, route.DestinationLon)
+	return route
+}
+
+// RouteChange is a priced route change ready to be written
+type RouteChange struct {
+	Route           OrderRoute
+	ChangedByID     string
+	UserType        UserType
+	Reason          *string
+	ExpectedVersion *int
+	// The origin only moves before pickup, the repository checks it again under the lock
+	ChangesOrigin      bool
+	ChangesDestination bool
+}
+
+// RouteChangeOutcome is the order on its new route, the route it left and the command
+// queued for the assigned drone
+type RouteChangeOutcome struct {
+	Order    *Order
+	Previous OrderRoute
+	Command  *DroneCommand
+}
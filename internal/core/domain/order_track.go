@@ -0,0 +1,145 @@
+package domain
+
+// GeoJSON object types used by the order track
+const (
+	GeoJSONTypeFeatureCollection = "FeatureCollection"
+	GeoJSONTypeFeature           = "Feature"
+	GeoJSONTypeLineString        = "LineString"
+	GeoJSONTypePoint             = "Point"
+)
+
+// Kinds of the features of an order track, in the kind property
+const (
+	TrackFeatureLeg          = "leg"
+	TrackFeatureStatusChange = "status_change"
+	TrackFeatureHandoff      = "handoff"
+)
+
+// GeoJSONGeometry is a GeoJSON geometry. Positions are [lon, lat, altitude], longitude first.
+type GeoJSONGeometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// GeoJSONFeature is a GeoJSON feature, its properties depend on the kind of track feature
+type GeoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   GeoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// OrderTrack is the flight path of an order as a GeoJSON FeatureCollection: one LineString per
+// drone that carried it, and Points where its status changed or a drone handed it off.
+// The order fields are foreign members, ignored by GeoJSON readers.
+type OrderTrack struct {
+	Type        string            `json:"type"`
+	OrderID     string            `json:"order_id"`
+	OrderNumber string            `json:"order_number"`
+	Status      OrderStatus       `json:"status"`
+	Features    []*GeoJSONFeature `json:"features"`
+}
+
+// NewOrderTrack builds the track of an order from the heartbeats recorded while a drone had it
+// on board, oldest first, its status history and its carriers. Status changes are marked from
+// the first pickup on, handoffs where the next drone picked the package up.
+func NewOrderTrack(order *Order, points []*DroneTelemetry, steps []*OrderStatusHistory, carriers []*OrderCarrier) *OrderTrack {
+	track := &OrderTrack{
+		Type:        GeoJSONTypeFeatureCollection,
+		OrderID:     order.ID,
+		OrderNumber: order.OrderNumber,
+		Status:      order.Status,
+		Features:    []*GeoJSONFeature{},
+	}
+
+	identifiers := make(map[string]*string, len(carriers))
+	for _, carrier := range carriers {
+		identifiers[carrier.DroneID] = carrier.DroneIdentifier
+	}
+
+	// A new leg starts whenever another drone reports the order
+	var leg *GeoJSONFeature
+	var coordinates [][]float64
+	closeLeg := func() {
+		if leg == nil {
+			return
+		}
+		leg.Geometry.Coordinates = coordinates
+		// A LineString needs two positions, a drone heard from once is a Point
+		if len(coordinates) == 1 {
+			leg.Geometry = GeoJSONGeometry{Type: GeoJSONTypePoint, Coordinates: coordinates[0]}
+		}
+		track.Features = append(track.Features, leg)
+	}
+	for _, point := range points {
+		if leg == nil || leg.Properties["drone_id"] != point.DroneID {
+			closeLeg()
+			leg = &GeoJSONFeature{
+				Type:     GeoJSONTypeFeature,
+				Geometry: GeoJSONGeometry{Type: GeoJSONTypeLineString},
+				Properties: map[string]interface{}{
+					"kind":             TrackFeatureLeg,
+					"drone_id":         point.DroneID,
+					"drone_identifier": identifiers[point.DroneID],
+					"started_at":       point.RecordedAt,
+				},
+			}
+			coordinates = nil
+		}
+		coordinates = append(coordinates, []float64{point.Lon, point.Lat, point.Altitude})
+		leg.Properties["ended_at"] = point.RecordedAt
+	}
+	closeLeg()
+
+	pickedUp := false
+	for _, step := range steps {
+		if step.ToStatus == OrderStatusPickedUp {
+			pickedUp = true
+		}
+		if !pickedUp || step.Lat == nil || step.Lon == nil {
+			continue
+		}
+		track.Features = append(track.Features, &GeoJSONFeature{
+			Type:     GeoJSONTypeFeature,
+			Geometry: GeoJSONGeometry{Type: GeoJSONTypePoint, Coordinates: geoJSONPosition(*step.Lat, *step.Lon, step.Altitude)},
+			Properties: map[string]interface{}{
+				"kind":             TrackFeatureStatusChange,
+				"from_status":      step.FromStatus,
+				"to_status":        step.ToStatus,
+				"drone_id":         step.DroneID,
+				"drone_identifier": step.DroneIdentifier,
+				"reason":           step.Reason,
+				"at":               step.CreatedAt,
+			},
+		})
+	}
+
+	for i := 1; i < len(carriers); i++ {
+		previous, next := carriers[i-1], carriers[i]
+		if next.PickupLat == nil || next.PickupLon == nil {
+			continue
+		}
+		track.Features = append(track.Features, &GeoJSONFeature{
+			Type:     GeoJSONTypeFeature,
+			Geometry: GeoJSONGeometry{Type: GeoJSONTypePoint, Coordinates: geoJSONPosition(*next.PickupLat, *next.PickupLon, nil)},
+			Properties: map[string]interface{}{
+				"kind":                  TrackFeatureHandoff,
+				"from_drone_id":         previous.DroneID,
+				"from_drone_identifier": previous.DroneIdentifier,
+				"to_drone_id":           next.DroneID,
+				"to_drone_identifier":   next.DroneIdentifier,
+				"released_at":           previous.ReleasedAt,
+				"at":                    next.PickedUpAt,
+			},
+		})
+	}
+
+	return track
+}
+
+// geoJSONPosition is a GeoJSON position, longitude first
+func geoJSONPosition(lat, lon float64, altitude *float64) []float64 {
+	if altitude == nil {
+		return []float64{lon, lat}
+	}
+	return []float64{lon, lat, *altitude}
+}
@@ -0,0 +1,210 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+type PricingRuleType string
+
+const (
+	// PricingRuleTariff is the fare of a priority tier, one active tariff per tier
+	PricingRuleTariff PricingRuleType = "tariff"
+	// PricingRuleTimeSurcharge adds a percentage to orders priced inside a time-of-day window
+	PricingRuleTimeSurcharge PricingRuleType = "time_surcharge"
+)
+
+// PricingRule is a row of the admin editable rule table. Tariffs use the fare fields,
+// time surcharges use SurchargePercent and the StartTime/EndTime window ("15:04", local time),
+// a window ending before it starts wraps midnight. A surcharge without priority applies to every tier.
+type PricingRule struct {
+	BaseModel
+	Name             string          `json:"name"`
+	RuleType         PricingRuleType `json:"rule_type"`
+	Priority         *OrderPriority  `json:"priority,omitempty"`
+	BaseFare         float64         `json:"base_fare"`
+	PerKm            float64         `json:"per_km"`
+	PerKg            float64         `json:"per_kg"`
+	MinFare          float64         `json:"min_fare"`
+	SurchargePercent float64         `json:"surcharge_percent"`
+	StartTime        *string         `json:"start_time,omitempty"`
+	EndTime          *string         `json:"end_time,omitempty"`
+}
+
+// PricingRuleRequest creates or replaces a pricing rule
+type PricingRuleRequest struct {
+	Name             string          `json:"name" validate:"required,min=1,max=100"`
+	RuleType         PricingRuleType `json:"rule_type" validate:"required,oneof=tariff time_surcharge"`
+	Priority         *OrderPriority  `json:"priority,omitempty" validate:"required_if=RuleType tariff,omitempty,oneof=standard express critical_medical"`
+	BaseFare         float64         `json:"base_fare" validate:"gte=0"`
+	PerKm            float64         `json:"per_km" validate:"gte=0"`
+	PerKg            float64         `json:"per_kg" validate:"gte=0"`
+	MinFare          float64         `json:"min_fare" validate:"gte=0"`
+	SurchargePercent float64         `json:"surcharge_percent" validate:"gte=0,lte=500"`
+	StartTime        *string         `json:"start_time,omitempty" validate:"required_if=RuleType time_surcharge,omitempty,datetime=15:04"`
+	EndTime          *string         `json:"end_time,omitempty" validate:"required_if=RuleType time_surcharge,omitempty,datetime=15:04"`
+}
+
+// QuoteRequest asks for the price of a delivery
+type QuoteRequest struct {
+	PackageWeightKg *float64      `json:"package_weight_kg,omitempty" validate:"omitempty,gt=0,lte=100"`
+	OriginLat       float64       `json:"origin_lat" validate:"required,latitude"`
+	OriginLon       float64       `json:"origin_lon" validate:"required,longitude"`
+	DestinationLat  float64       `json:"destination_lat" validate:"required,latitude"`
+	DestinationLon  float64       `json:"destination_lon" validate:"required,longitude"`
+	Priority        OrderPriority `json:"priority,omitempty" validate:"omitempty,oneof=standard express critical_medical"`
+	ScheduledAt     *string       `json:"scheduled_at,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+// PriceBreakdown details how a price was computed
+type PriceBreakdown struct {
+	DistanceKm       float64 `json:"distance_km"`
+	BaseFare         float64 `json:"base_fare"`
+	DistanceFare     float64 `json:"distance_fare"`
+	WeightFare       float64 `json:"weight_fare"`
+	SurchargePercent float64 `json:"surcharge_percent"`
+	Surcharge        float64 `json:"surcharge"`
+	MinFareApplied   bool    `json:"min_fare_applied"`
+	Total            float64 `json:"total"`
+}
+
+// Quote is a signed, time-limited price. ID is passed back as quote_id when creating the order.
+type Quote struct {
+	ID        string         `json:"id"`
+	Price     float64        `json:"price"`
+	Currency  string         `json:"currency"`
+	Priority  OrderPriority  `json:"priority"`
+	Breakdown PriceBreakdown `json:"breakdown"`
+	ExpiresAt time.Time      `json:"expires_at"`
+}
+
+// QuoteClaims is the signed content of a quote ID
+type QuoteClaims struct {
+	QuoteID         string        `json:"qid"`
+	UserID          string        `json:"uid"`
+	OriginLat       float64       `json:"olat"`
+	OriginLon       float64       `json:"olon"`
+	DestinationLat  float64       `json:"dlat"`
+	DestinationLon  float64       `json:"dlon"`
+	PackageWeightKg *float64      `json:"kg,omitempty"`
+	ScheduledAt     *string       `json:"at,omitempty"`
+	Priority        OrderPriority `json:"pri"`
+	Price           float64       `json:"price"`
+	Currency        string        `json:"cur"`
+	ExpiresAt       int64         `json:"exp"`
+}
+
+// coordinateTolerance absorbs float formatting differences between the quote and the order
+const coordinateTolerance = 1e-6
+
+// Matches reports whether an order is the delivery that was quoted
+func (c *QuoteClaims) Matches(order *CreateOrderRequest) bool {
+	if c.Priority != order.Priority.OrDefault() {
+		return false
+	}
+	if math.Abs(c.OriginLat-order.OriginLat) > coordinateTolerance ||
+		math.Abs(c.OriginLon-order.OriginLon) > coordinateTolerance ||
+		math.Abs(c.DestinationLat-order.DestinationLat) > coordinateTolerance ||
+		math.Abs(c.DestinationLon-order.DestinationLon) > coordinateTolerance {
+		return false
+	}
+	if (c.PackageWeightKg == nil) != (order.PackageWeightKg == nil) {
+		return false
+	}
+	if c.PackageWeightKg != nil && math.Abs(*c.PackageWeightKg-*order.PackageWeightKg) > coordinateTolerance {
+		return false
+	}
+	return sameTime(c.ScheduledAt, order.ScheduledAt)
+}
+
+// sameTime compares two optional RFC3339 times
+func sameTime(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	ta, errA := time.Parse(time.RFC3339, *a)
+	tb, errB := time.Parse(time.RFC3339, *b)
+	if errA != nil || errB != nil {
+		return *a == *b
+	}
+	return ta.Equal(tb)
+}
+
+// minuteOfDay parses a "15:04" time of day
+func minuteOfDay(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q: %w", value, err)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// InWindow reports whether at falls inside the surcharge window of the rule
+func (rule *PricingRule) InWindow(at time.Time) bool {
+	if rule.StartTime == nil || rule.EndTime == nil {
+		return false
+	}
+	start, err := minuteOfDay(*rule.StartTime)
+	if err != nil {
+		return false
+	}
+	end, err := minuteOfDay(*rule.EndTime)
+	if err != nil {
+		return false
+	}
+
+	minute := at.Hour()*60 + at.Minute()
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// CalculatePrice prices a delivery with the active rules, at is the local time the order is flown
+func CalculatePrice(rules []*PricingRule, priority OrderPriority, distanceKm float64, weightKg *float64, at time.Time) (*PriceBreakdown, error) {
+	var tariff *PricingRule
+	surchargePercent := 0.0
+	for _, rule := range rules {
+		if !rule.Active {
+			continue
+		}
+		switch rule.RuleType {
+		case PricingRuleTariff:
+			if rule.Priority != nil && *rule.Priority == priority {
+				tariff = rule
+			}
+		case PricingRuleTimeSurcharge:
+			if (rule.Priority == nil || *rule.Priority == priority) && rule.InWindow(at) {
+				surchargePercent += rule.SurchargePercent
+			}
+		}
+	}
+	if tariff == nil {
+		return nil, ErrNoPricingRule
+	}
+
+	breakdown := &PriceBreakdown{
+		DistanceKm:       roundMoney(distanceKm),
+		BaseFare:         roundMoney(tariff.BaseFare),
+		DistanceFare:     roundMoney(tariff.PerKm * distanceKm),
+		SurchargePercent: surchargePercent,
+	}
+	if weightKg != nil {
+		breakdown.WeightFare = roundMoney(tariff.PerKg * *weightKg)
+	}
+
+	subtotal := breakdown.BaseFare + breakdown.DistanceFare + breakdown.WeightFare
+	breakdown.Surcharge = roundMoney(subtotal * surchargePercent / 100)
+	breakdown.Total = roundMoney(subtotal + breakdown.Surcharge)
+	if breakdown.Total < tariff.MinFare {
+		breakdown.Total = roundMoney(tariff.MinFare)
+		breakdown.MinFareApplied = true
+	}
+
+	return breakdown, nil
+}
+
+func roundMoney(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,55 @@
+package domain
+
+import "regexp"
+
+// ServiceArea is a region the fleet operates in, drawn by admins as a polygon. Orders are picked
+// up and dropped off inside one area, and their receiver phone numbers must match one of its
+// PhoneFormats (regular expressions), any number when it has none.
+type ServiceArea struct {
+	BaseModel
+	Name         string     `json:"name"`
+	Points       []GeoPoint `json:"points"`
+	PhoneFormats []string   `json:"phone_formats"`
+}
+
+// ServiceAreaRequest creates or replaces a service area
+type ServiceAreaRequest struct {
+	Name         string     `json:"name" validate:"required,min=1,max=100"`
+	Points       []GeoPoint `json:"points" validate:"required,min=3,max=500,dive"`
+	PhoneFormats []string   `json:"phone_formats,omitempty" validate:"omitempty,max=20,dive,min=1,max=200"`
+}
+
+// Contains reports whether a position is inside the area
+func (area *ServiceArea) Contains(lat, lon float64) bool {
+	return polygonContains(area.Points, lat, lon)
+}
+
+// AllowsPhone reports whether a phone number matches one of the formats of the area
+func (area *ServiceArea) AllowsPhone(phone string) bool {
+	if len(area.PhoneFormats) == 0 {
+		return true
+	}
+	for _, format := range area.PhoneFormats {
+		if matched, _ := regexp.MatchString(format, phone); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// FindServiceArea returns the first area that contains every given point, nil when there is none
+func FindServiceArea(areas []*ServiceArea, points ...GeoPoint) *ServiceArea {
+	for _, area := range areas {
+		inside := true
+		for _, point := range points {
+			if !area.Contains(point.Lat, point.Lon) {
+				inside = false
+				break
+			}
+		}
+		if inside {
+			return area
+		}
+	}
+	return nil
+}
@@ -21,17 +21,16 @@ type ValidationErrors map[string][]ValidationError
 
 func NewValidator() *validator.Validate {
 	validate := validator.New(validator.WithRequiredStructEnabled())
-	validate.RegisterValidation("saudiphonenumber", validateSaudiPhoneNumber)
+	validate.RegisterValidation("phonenumber", validatePhoneNumber)
 	validate.RegisterValidation("serialnum", validateSerialNumber)
 	validate.RegisterValidation("uuid", validateUUID)
-	validate.RegisterValidation("saudilat", validateSaudiLat)
-	validate.RegisterValidation("saudilon", validateSaudiLon)
 	return validate
 }
 
-func validateSaudiPhoneNumber(fl validator.FieldLevel) bool {
-	// Saudi Arabia phone number validation regex
-	pattern := `^\+?966[5-9][0-9]{8}$`
+func validatePhoneNumber(fl validator.FieldLevel) bool {
+	// International phone number, country code first. The formats allowed in each service area
+	// are checked when the order is placed.
+	pattern := `^\+?[1-9][0-9]{7,14}$`
 	phone := fl.Field().String()
 	if phone == "" {
 		return false
@@ -61,16 +60,6 @@ func validateUUID(fl validator.FieldLevel) bool {
 	return matched
 }
 
-func validateSaudiLat(fl validator.FieldLevel) bool {
-	lat := fl.Field().Float()
-	return lat >= 16.0 && lat <= 32.0
-}
-
-func validateSaudiLon(fl validator.FieldLevel) bool {
-	lon := fl.Field().Float()
-	return lon >= 34.0 && lon <= 56.0
-}
-
 func GetValidationErrors(errs validator.ValidationErrors) ValidationErrors {
 	validationErrors := make(ValidationErrors)
 
@@ -241,16 +230,22 @@ func GetValidationError(e validator.FieldError) ValidationError {
 			Message: "This field must be a valid IP address",
 			Value:   value,
 		}
-	case "saudilat":
+	case "latitude":
+		return ValidationError{
+			Code:    "latitude",
+			Message: "This field must be a valid latitude (-90 to 90)",
+			Value:   value,
+		}
+	case "longitude":
 		return ValidationError{
-			Code:    "saudilat",
-			Message: "This field must be a valid latitude in Saudi Arabia (16.0 to 32.0)",
+			Code:    "longitude",
+			Message: "This field must be a valid longitude (-180 to 180)",
 			Value:   value,
 		}
-	case "saudilon":
+	case "phonenumber":
 		return ValidationError{
-			Code:    "saudilon",
-			Message: "This field must be a valid longitude in Saudi Arabia (34.0 to 56.0)",
+			Code:    "phonenumber",
+			Message: "Please enter a valid phone number with its country code",
 			Value:   value,
 		}
 	default:
@@ -0,0 +1,34 @@
+package events
+
+import "drones/internal/core/domain"
+
+// DroneLostEvent alerts operators that a flying drone stopped sending heartbeats
+type DroneLostEvent struct {
+	DroneID         string   `json:"drone_id"`
+	DroneIdentifier string   `json:"drone_identifier"`
+	LastHeartbeatAt *string  `json:"last_heartbeat_at,omitempty"`
+	LastLat         *float64 `json:"last_lat,omitempty"`
+	LastLon         *float64 `json:"last_lon,omitempty"`
+	HandedOffOrders []string `json:"handed_off_orders"`
+}
+
+// DroneRestoredEvent tells operators a lost drone is sending heartbeats again
+type DroneRestoredEvent struct {
+	DroneID         string             `json:"drone_id"`
+	DroneIdentifier string             `json:"drone_identifier"`
+	Status          domain.DroneStatus `json:"status"`
+	Lat             *float64           `json:"lat,omitempty"`
+	Lon             *float64           `json:"lon,omitempty"`
+}
+
+// GeofenceViolationEvent alerts operators that a drone entered an active no-fly zone
+type GeofenceViolationEvent struct {
+	ViolationID  string   `json:"violation_id"`
+	GeofenceID   string   `json:"geofence_id"`
+	GeofenceName string   `json:"geofence_name"`
+	DroneID      string   `json:"drone_id"`
+	OrderID      *string  `json:"order_id,omitempty"`
+	Lat          float64  `json:"lat"`
+	Lon          float64  `json:"lon"`
+	Altitude     *float64 `json:"altitude,omitempty"`
+}
@@ -3,24 +3,26 @@ package events
 import "drones/internal/core/domain"
 
 type OrderCreatedEvent struct {
-	OrderID            string  `json:"order_id"`
-	UserID             string  `json:"user_id"`
-	OriginAddress      string  `json:"origin_address"`
-	OriginLat          float64 `json:"origin_lat"`
-	OriginLon          float64 `json:"origin_lon"`
-	DestinationAddress string  `json:"destination_address"`
-	DestinationLat     float64 `json:"destination_lat"`
-	DestinationLon     float64 `json:"destination_lon"`
+	OrderID            string               `json:"order_id"`
+	UserID             string               `json:"user_id"`
+	OriginAddress      string               `json:"origin_address"`
+	OriginLat          float64              `json:"origin_lat"`
+	OriginLon          float64              `json:"origin_lon"`
+	DestinationAddress string               `json:"destination_address"`
+	DestinationLat     float64              `json:"destination_lat"`
+	DestinationLon     float64              `json:"destination_lon"`
+	Priority           domain.OrderPriority `json:"priority"`
 }
 
 type OrderUpdatedEvent struct {
-	OrderID         string             `json:"order_id"`
-	UserID          string             `json:"user_id"`
-	DroneID         string             `json:"drone_id,omitempty"`
-	Status          domain.OrderStatus `json:"status"`
-	CurrentLat      *float64           `json:"current_lat,omitempty"`
-	CurrentLon      *float64           `json:"current_lon,omitempty"`
-	CurrentAltitude *float64           `json:"current_altitude,omitempty"`
+	OrderID            string             `json:"order_id"`
+	UserID             string             `json:"user_id"`
+	DroneID            string             `json:"drone_id,omitempty"`
+	Status             domain.OrderStatus `json:"status"`
+	CurrentLat         *float64           `json:"current_lat,omitempty"`
+	CurrentLon         *float64           `json:"current_lon,omitempty"`
+	CurrentAltitude    *float64           `json:"current_altitude,omitempty"`
+	EstimatedArrivalAt *string            `json:"estimated_arrival_at,omitempty"`
 }
 
 type OrderWithdrawnEvent struct {
@@ -29,6 +31,52 @@ type OrderWithdrawnEvent struct {
 	Status  domain.OrderStatus `json:"status"`
 }
 
+type OrderAssignedEvent struct {
+	OrderID            string             `json:"order_id"`
+	UserID             string             `json:"user_id"`
+	DroneID            string             `json:"drone_id"`
+	Status             domain.OrderStatus `json:"status"`
+	Policy             string             `json:"policy"`
+	DistanceToOriginKm float64            `json:"distance_to_origin_km"`
+	TripKm             float64            `json:"trip_km"`
+}
+
+type OrderReleasedEvent struct {
+	OrderID     string             `json:"order_id"`
+	UserID      string             `json:"user_id"`
+	Status      domain.OrderStatus `json:"status"`
+	ScheduledAt *string            `json:"scheduled_at,omitempty"`
+}
+
+type OrderCancelledEvent struct {
+	OrderID        string                    `json:"order_id"`
+	UserID         string                    `json:"user_id"`
+	PreviousStatus domain.OrderStatus        `json:"previous_status"`
+	Status         domain.OrderStatus        `json:"status"`
+	ReasonCode     domain.CancellationReason `json:"reason_code"`
+	Note           *string                   `json:"note,omitempty"`
+	CancelledByID  string                    `json:"cancelled_by_id"`
+	CancelledAt    *string                   `json:"cancelled_at,omitempty"`
+	DroneID        *string                   `json:"drone_id,omitempty"`
+	ReturnToOrigin bool                      `json:"return_to_origin"`
+	CommandID      *string                   `json:"command_id,omitempty"`
+}
+
+// OrderDeliveryFailedEvent tells the enduser a delivery attempt failed and what happens next
+type OrderDeliveryFailedEvent struct {
+	OrderID             string                       `json:"order_id"`
+	OrderNumber         string                       `json:"order_number"`
+	UserID              string                       `json:"user_id"`
+	DroneID             string                       `json:"drone_id,omitempty"`
+	Status              domain.OrderStatus           `json:"status"`
+	ReasonCode          domain.DeliveryFailureReason `json:"reason_code,omitempty"`
+	Action              domain.DeliveryFailureAction `json:"action"`
+	DeliveryAttempts    int                          `json:"delivery_attempts"`
+	MaxDeliveryAttempts int                          `json:"max_delivery_attempts"`
+	DepotAddress        *string                      `json:"depot_address,omitempty"`
+	CommandID           *string                      `json:"command_id,omitempty"`
+}
+
 type OrderReservedEvent struct {
 	OrderID string             `json:"order_id"`
 	DroneID string             `json:"drone_id"`
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"drones/internal/core/domain"
+	"drones/internal/ports"
+)
+
+// NearestFirstPolicy prefers the drone closest to the order origin, remaining battery breaks ties
+type NearestFirstPolicy struct{}
+
+func (p *NearestFirstPolicy) Name() domain.DispatchPolicyName {
+	return domain.DispatchPolicyNearestFirst
+}
+
+func (p *NearestFirstPolicy) Score(order *domain.Order, candidate *domain.DispatchCandidate) float64 {
+	return -candidate.DistanceToOriginKm + batteryPercent(candidate.Drone)/1000
+}
+
+// BalancedUtilizationPolicy spreads work across the fleet.
+// Drones with more battery, fewer deliveries and fewer flight hours are preferred,
+// distance still counts so a far away drone does not win over a close one by a small margin.
+type BalancedUtilizationPolicy struct {
+	BatteryWeight    float64
+	DeliveriesWeight float64
+	FlightHourWeight float64
+	DistanceWeight   float64
+}
+
+func NewBalancedUtilizationPolicy() *BalancedUtilizationPolicy {
+	return &BalancedUtilizationPolicy{
+		BatteryWeight:    1,
+		DeliveriesWeight: 0.5,
+		FlightHourWeight: 0.2,
+		DistanceWeight:   2,
+	}
+}
+
+func (p *BalancedUtilizationPolicy) Name() domain.DispatchPolicyName {
+	return domain.DispatchPolicyBalancedUtilization
+}
+
+func (p *BalancedUtilizationPolicy) Score(order *domain.Order, candidate *domain.DispatchCandidate) float64 {
+	drone := candidate.Drone
+	return p.BatteryWeight*batteryPercent(drone) -
+		p.DeliveriesWeight*float64(drone.TotalDeliveries) -
+		p.FlightHourWeight*drone.TotalFlightHours -
+		p.DistanceWeight*candidate.DistanceToOriginKm
+}
+
+// NewDispatchPolicy returns the policy registered under name, defaults to nearest first
+func NewDispatchPolicy(name string) ports.DispatchPolicy {
+	switch domain.DispatchPolicyName(name) {
+	case domain.DispatchPolicyBalancedUtilization:
+		return NewBalancedUtilizationPolicy()
+	default:
+		return &NearestFirstPolicy{}
+	}
+}
+
+func batteryPercent(drone *domain.Drone) float64 {
+	if drone.BatteryLevelPercent == nil {
+		return 100
+	}
+	return *drone.BatteryLevelPercent
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	config "drones/configs"
+	"drones/internal/core/domain"
+	"drones/internal/core/events"
+	"drones/internal/ports"
+	"drones/pkg/utils"
+)
+
+type DispatchServiceImpl struct {
+	ordersRepo     ports.OrdersRepository
+	dronesService  ports.DronesService
+	policy         ports.DispatchPolicy
+	etaService     ports.EtaService
+	cacheService   ports.CacheService
+	eventPublisher ports.EventPublisher
+	config         config.DispatchConfig
+	logger         ports.Logger
+}
+
+func NewDispatchService(
+	ordersRepo ports.OrdersRepository,
+	dronesService ports.DronesService,
+	policy ports.DispatchPolicy,
+	etaService ports.EtaService,
+	cacheService ports.CacheService,
+	eventPublisher ports.EventPublisher,
+	config config.DispatchConfig,
+	logger ports.Logger,
+) ports.DispatchService {
+	return &DispatchServiceImpl{
+		ordersRepo:     ordersRepo,
+		dronesService:  dronesService,
+		policy:         policy,
+		etaService:     etaService,
+		cacheService:   cacheService,
+		eventPublisher: eventPublisher,
+		config:         config,
+		logger:         logger,
+	}
+}
+
+func (s *DispatchServiceImpl) DispatchOrder(ctx context.Context, orderID string) (*domain.Order, error) {
+	order, err := s.ordersRepo.GetOrderByID(ctx, orderID, domain.OrderFilter{})
+	if err != nil {
+		s.logger.Error("Failed to get order for dispatch", "orderID", orderID, "error", err)
+		return nil, err
+	}
+
+	return s.dispatch(ctx, order)
+}
+
+func (s *DispatchServiceImpl) Sweep(ctx context.Context) error {
+	orders, err := s.ordersRepo.ListPendingOrders(ctx, s.config.SweepBatchSize)
+	if err != nil {
+		s.logger.Error("Failed to list pending orders for dispatch sweep", "error", err)
+		return err
+	}
+
+	assigned := 0
+	for _, order := range orders {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if _, err := s.dispatch(ctx, order); err != nil {
+			if err != domain.ErrNoEligibleDrone && err != domain.ErrAlreadyReserved {
+				s.logger.Error("Failed to dispatch order", "orderID", order.ID, "error", err)
+			}
+			continue
+		}
+		assigned++
+	}
+
+	if len(orders) > 0 {
+		s.logger.Info("Dispatch sweep finished", "pending", len(orders), "assigned", assigned, "policy", string(s.policy.Name()))
+	}
+	return nil
+}
+
+// dispatch tries eligible drones best score first until one is atomically reserved
+func (s *DispatchServiceImpl) dispatch(ctx context.Context, order *domain.Order) (*domain.Order, error) {
+	if order.DroneID != nil {
+		return nil, domain.ErrAlreadyReserved
+	}
+	if order.Status != domain.OrderStatusPending {
+		return nil, domain.ErrReserveNotAllowed
+	}
+
+	candidates, err := s.candidates(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		s.logger.Info("No eligible drone for order", "orderID", order.ID)
+		return nil, domain.ErrNoEligibleDrone
+	}
+
+	for _, candidate := range candidates {
+		assigned, err := s.ordersRepo.AssignOrder(ctx, order.ID, candidate.Drone.ID)
+		if err != nil {
+			if err == domain.ErrDroneMustBeIdle {
+				// Drone was taken by someone else in the meantime, try the next one
+				continue
+			}
+			return nil, err
+		}
+
+		s.invalidateCache(ctx, assigned.ID, candidate.Drone.ID)
+
+		if updated, err := s.etaService.RefreshOrder(ctx, assigned); err != nil {
+			s.logger.Error("Failed to refresh order ETA", "orderID", assigned.ID, "error", err)
+		} else {
+			assigned = updated
+		}
+
+		// Publish event
+		if err := s.eventPublisher.PublishOrderAssigned(ctx, events.OrderAssignedEvent{
+			OrderID:            assigned.ID,
+			UserID:             assigned.UserID,
+			DroneID:            candidate.Drone.ID,
+			Status:             assigned.Status,
+			Policy:             string(s.policy.Name()),
+			DistanceToOriginKm: candidate.DistanceToOriginKm,
+			TripKm:             candidate.TripKm,
+		}); err != nil {
+			s.logger.Error("Failed to publish order assigned event", "orderID", assigned.ID, "error", err)
+		}
+
+		s.logger.Info("Order dispatched",
+			"orderID", assigned.ID,
+			"droneID", candidate.Drone.ID,
+			"policy", string(s.policy.Name()),
+			"score", candidate.Score)
+
+		return assigned, nil
+	}
+
+	return nil, domain.ErrNoEligibleDrone
+}
+
+// candidates returns the idle drones able to serve the order sorted by policy score
+func (s *DispatchServiceImpl) candidates(ctx context.Context, order *domain.Order) ([]*domain.DispatchCandidate, error) {
+	drones, err := s.dronesService.NearbyDrones(ctx, order.OriginLat, order.OriginLon, s.config.SearchRadiusKm)
+	if err != nil {
+		s.logger.Error("Failed to find nearby drones", "orderID", order.ID, "error", err)
+		return nil, err
+	}
+
+	tripKm := utils.HaversineKm(order.OriginLat, order.OriginLon, order.DestinationLat, order.DestinationLon)
+
+	var candidates []*domain.DispatchCandidate
+	for _, drone := range drones {
+		if drone.Status != domain.DroneStatusIdle || drone.CurrentLat == nil || drone.CurrentLon == nil {
+			continue
+		}
+		if drone.BatteryLevelPercent != nil && *drone.BatteryLevelPercent < s.config.MinBatteryPercent {
+			continue
+		}
+		if !drone.CanCarry(order.PackageWeightKg) {
+			continue
+		}
+		if len(drone.MissingCapabilities(order.RequiredCapabilities)) > 0 {
+			continue
+		}
+
+		candidate := &domain.DispatchCandidate{
+			Drone:              drone,
+			DistanceToOriginKm: utils.HaversineKm(*drone.CurrentLat, *drone.CurrentLon, order.OriginLat, order.OriginLon),
+			TripKm:             tripKm,
+		}
+		if drone.AvailableRangeKm() < candidate.RequiredRangeKm() {
+			continue
+		}
+
+		candidate.Score = s.policy.Score(order, candidate)
+		candidates = append(candidates, candidate)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	return candidates, nil
+}
+
+func (s *DispatchServiceImpl) invalidateCache(ctx context.Context, orderID, droneID string) {
+	orderKey := fmt.Sprintf("orders:%s:", orderID)
+	if err := s.cacheService.Delete(ctx, orderKey); err != nil {
+		s.logger.Error("Failed to invalidate order cache", "key", orderKey, "error", err)
+	}
+	droneKey := "drones:" + droneID
+	if err := s.cacheService.Delete(ctx, droneKey); err != nil {
+		s.logger.Error("Failed to invalidate drone cache", "key", droneKey, "error", err)
+	}
+}
@@ -2,24 +2,44 @@ package services
 
 import (
 	"context"
+	config "drones/configs"
 	"drones/internal/core/domain"
+	"drones/internal/core/events"
 	"drones/internal/ports"
+	"fmt"
+	"time"
 )
 
 type DronesService struct {
-	repo           ports.DronesRepository
-	cacheService   ports.CacheService
-	eventPublisher ports.EventPublisher
-	logger         ports.Logger
+	repo               ports.DronesRepository
+	ordersRepo         ports.OrdersRepository
+	commandsRepo       ports.DroneCommandsRepository
+	telemetryRepo      ports.DroneTelemetryRepository
+	geofenceService    ports.GeofenceService
+	serviceAreaService ports.ServiceAreaService
+	etaService         ports.EtaService
+	cacheService       ports.CacheService
+	eventPublisher     ports.EventPublisher
+	heartbeatConfig    config.HeartbeatConfig
+	deliveryConfig     config.DeliveryConfig
+	logger             ports.Logger
 }
 
 func NewDronesService(
 	repo ports.DronesRepository,
+	ordersRepo ports.OrdersRepository,
+	commandsRepo ports.DroneCommandsRepository,
+	telemetryRepo ports.DroneTelemetryRepository,
+	geofenceService ports.GeofenceService,
+	serviceAreaService ports.ServiceAreaService,
+	etaService ports.EtaService,
 	cacheService ports.CacheService,
 	eventPublisher ports.EventPublisher,
+	heartbeatConfig config.HeartbeatConfig,
+	deliveryConfig config.DeliveryConfig,
 	logger ports.Logger,
 ) ports.DronesService {
-	return &DronesService{repo: repo, cacheService: cacheService, eventPublisher: eventPublisher, logger: logger}
+	return &DronesService{repo: repo, ordersRepo: ordersRepo, commandsRepo: commandsRepo, telemetryRepo: telemetryRepo, geofenceService: geofenceService, serviceAreaService: serviceAreaService, etaService: etaService, cacheService: cacheService, eventPublisher: eventPublisher, heartbeatConfig: heartbeatConfig, deliveryConfig: deliveryConfig, logger: logger}
 }
 
 func (s *DronesService) CreateDrone(ctx context.Context, drone *domain.CreateDroneRequest) (*domain.Drone, error) {
@@ -91,7 +111,7 @@ func (s *DronesService) UpdateDroneStatus(ctx context.Context, userID, droneID s
 		return nil, drone.Status.TransitionErr()
 	}
 
-	updatedDrone, err := s.repo.UpdateStatusBroken(ctx, userID, droneID, status)
+	updatedDrone, handedOff, err := s.repo.UpdateStatusBroken(ctx, userID, droneID, status)
 	if err != nil {
 		s.logger.Error("Failed to mark drone as broken", "droneID", droneID, "error", err)
 		return nil, err
@@ -102,27 +122,261 @@ func (s *DronesService) UpdateDroneStatus(ctx context.Context, userID, droneID s
 	if err != nil {
 		s.logger.Error("Failed to update cache for broken drone", "droneID", droneID, "error", err)
 	}
+	// The handed off orders moved on, drop their cached copies
+	for _, order := range handedOff {
+		if err := s.cacheService.Delete(ctx, fmt.Sprintf("orders:%s:", order.OrderID)); err != nil {
+			s.logger.Error("Failed to invalidate order cache", "orderID", order.OrderID, "error", err)
+		}
+	}
 	return updatedDrone, nil
 }
 
-func (s *DronesService) ProcessHeartbeat(ctx context.Context, droneID string, userId string, req domain.HeartbeatRequest) (*domain.Drone, error) {
+func (s *DronesService) ProcessHeartbeat(ctx context.Context, droneID string, userId string, req domain.HeartbeatRequest) (*domain.Heartbeat, error) {
 	// Validate drone exists
 	drone, err := s.GetDroneByID(ctx, droneID)
 	if err != nil {
 		s.logger.Error("Drone not found for heartbeat", "droneID", droneID, "error", err)
 		return nil, err
 	}
-	// Process heartbeat
-	updatedDrone, err := s.repo.ProcessHeartbeat(ctx, drone.ID, userId, req)
+	// Drones only report from where the fleet operates
+	area, err := s.serviceAreaService.FindServiceArea(ctx, domain.GeoPoint{Lat: req.Latitude, Lon: req.Longitude})
+	if err != nil {
+		s.logger.Error("Failed to check service areas on heartbeat", "droneID", droneID, "error", err)
+		return nil, err
+	}
+	if area == nil {
+		s.logger.Warn("Heartbeat outside the service areas", "droneID", droneID, "lat", req.Latitude, "lon", req.Longitude)
+		return nil, domain.ErrLocationOutsideServiceArea
+	}
+	// Process heartbeat, a drone silent for longer than the watchdog allows did not fly in between
+	updatedDrone, err := s.repo.ProcessHeartbeat(ctx, drone.ID, userId, req, s.heartbeatConfig.Timeout)
 	if err != nil {
 		s.logger.Error("Failed to process heartbeat", "droneID", droneID, "error", err)
 		return nil, err
 	}
+	// Heartbeats resumed, its orders were handed off so the drone is available again
+	if updatedDrone.Status == domain.DroneStatusLost {
+		updatedDrone, err = s.restoreLostDrone(ctx, userId, updatedDrone)
+		if err != nil {
+			return nil, err
+		}
+	}
 	// Update cache with the updated drone
 	cacheKey := "drones:" + droneID
 	err = s.cacheService.Set(ctx, cacheKey, *updatedDrone, 0)
 	if err != nil {
 		s.logger.Error("Failed to update cache for drone heartbeat", "droneID", droneID, "error", err)
 	}
-	return updatedDrone, nil
+
+	// Keep the ETA of the order the drone is working on current
+	if err := s.etaService.OnHeartbeat(ctx, drone, updatedDrone); err != nil {
+		s.logger.Error("Failed to refresh ETA on heartbeat", "droneID", droneID, "error", err)
+	}
+
+	// Commands the drone carried out since the last heartbeat are no longer sent
+	if len(req.AcknowledgedCommandIDs) > 0 {
+		acknowledged, err := s.commandsRepo.AcknowledgeCommands(ctx, droneID, userId, req.AcknowledgedCommandIDs)
+		if err != nil {
+			s.logger.Error("Failed to acknowledge drone commands", "droneID", droneID, "error", err)
+			return nil, err
+		}
+		s.logger.Info("Drone acknowledged commands", "droneID", droneID, "count", len(acknowledged))
+	}
+
+	heartbeat := &domain.Heartbeat{
+		Drone:    updatedDrone,
+		Warnings: s.heartbeatWarnings(req),
+	}
+
+	heartbeat.Order, err = s.ordersRepo.GetOrderByFilter(ctx, domain.OrderFilter{
+		DroneID:  &droneID,
+		Statuses: domain.DroneOrderStatuses,
+	})
+	if err != nil && err != domain.ErrOrderNotFound {
+		s.logger.Error("Failed to get current order for heartbeat", "droneID", droneID, "error", err)
+		return nil, err
+	}
+	if heartbeat.Order != nil && heartbeat.Order.Status == domain.OrderStatusDeliveringToDepot {
+		heartbeat.Depot = &domain.Depot{
+			Address: s.deliveryConfig.DepotAddress,
+			Lat:     s.deliveryConfig.DepotLat,
+			Lon:     s.deliveryConfig.DepotLon,
+		}
+	}
+
+	// Checked before listing the commands, so the one queued on entering a zone is sent right away
+	if warning := s.checkGeofences(ctx, drone, heartbeat.Order, req); warning != nil {
+		heartbeat.Warnings = append(heartbeat.Warnings, *warning)
+	}
+
+	heartbeat.Commands, err = s.commandsRepo.ListPendingCommands(ctx, droneID)
+	if err != nil {
+		s.logger.Error("Failed to list pending drone commands", "droneID", droneID, "error", err)
+		return nil, err
+	}
+
+	return heartbeat, nil
+}
+
+// checkGeofences warns a drone reporting from inside an active no-fly zone. When it just entered
+// the zone, the violation is recorded and the drone is told to fly back to its previous position.
+// A failed check is logged only, it must not cost the drone its heartbeat.
+func (s *DronesService) checkGeofences(ctx context.Context, previous *domain.Drone, order *domain.Order, req domain.HeartbeatRequest) *domain.HeartbeatWarning {
+	zone, err := s.geofenceService.FindGeofence(ctx, req.Latitude, req.Longitude, time.Now())
+	if err != nil {
+		s.logger.Error("Failed to check geofences on heartbeat", "droneID", previous.ID, "error", err)
+		return nil
+	}
+	if zone == nil {
+		return nil
+	}
+
+	warning := &domain.HeartbeatWarning{
+		Code:    domain.HeartbeatWarningGeofence,
+		Message: fmt.Sprintf("Inside the no-fly zone %q, leave it", zone.Name),
+	}
+
+	wasInside := previous.CurrentLat != nil && previous.CurrentLon != nil && zone.Contains(*previous.CurrentLat, *previous.CurrentLon)
+	if wasInside {
+		return warning
+	}
+
+	var orderID *string
+	if order != nil {
+		orderID = &order.ID
+	}
+	altitude := req.Altitude
+	if _, err := s.geofenceService.RecordViolation(ctx, domain.GeofenceViolation{
+		GeofenceID: zone.ID,
+		DroneID:    previous.ID,
+		OrderID:    orderID,
+		Lat:        req.Latitude,
+		Lon:        req.Longitude,
+		Altitude:   &altitude,
+	}); err != nil {
+		s.logger.Error("Failed to record geofence violation", "droneID", previous.ID, "geofenceID", zone.ID, "error", err)
+	}
+
+	note := fmt.Sprintf("Entered the no-fly zone %q", zone.Name)
+	if _, err := s.commandsRepo.QueueCommand(ctx, domain.DroneCommand{
+		DroneID: previous.ID,
+		OrderID: orderID,
+		Command: domain.DroneCommandExitGeofence,
+		Lat:     previous.CurrentLat,
+		Lon:     previous.CurrentLon,
+		Note:    &note,
+	}); err != nil {
+		s.logger.Error("Failed to queue exit geofence command", "droneID", previous.ID, "geofenceID", zone.ID, "error", err)
+	}
+
+	return warning
+}
+
+// restoreLostDrone moves a lost drone that reported again back to idle
+func (s *DronesService) restoreLostDrone(ctx context.Context, userID string, drone *domain.Drone) (*domain.Drone, error) {
+	restored, err := s.repo.RestoreLostDrone(ctx, userID, drone.ID)
+	if err == domain.ErrDroneNotLost {
+		// Moved on since the heartbeat, keep the status it has now
+		s.logger.Warn("Lost drone changed status before it was restored", "droneID", drone.ID)
+		return s.repo.GetDroneByID(ctx, drone.ID)
+	}
+	if err != nil {
+		s.logger.Error("Failed to restore lost drone", "droneID", drone.ID, "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Lost drone restored on heartbeat", "droneID", drone.ID)
+
+	if err := s.eventPublisher.PublishDroneRestored(ctx, events.DroneRestoredEvent{
+		DroneID:         restored.ID,
+		DroneIdentifier: restored.DroneIdentifier,
+		Status:          restored.Status,
+		Lat:             restored.CurrentLat,
+		Lon:             restored.CurrentLon,
+	}); err != nil {
+		s.logger.Error("Failed to publish drone restored event", "droneID", drone.ID, "error", err)
+	}
+
+	return restored, nil
+}
+
+// heartbeatWarnings runs the server-side checks on a heartbeat
+func (s *DronesService) heartbeatWarnings(req domain.HeartbeatRequest) []domain.HeartbeatWarning {
+	var warnings []domain.HeartbeatWarning
+	switch {
+	case req.Battery <= s.heartbeatConfig.CriticalBatteryPercent:
+		warnings = append(warnings, domain.HeartbeatWarning{
+			Code:    domain.HeartbeatWarningCriticalBattery,
+			Message: fmt.Sprintf("Battery at %d%%, land as soon as possible", req.Battery),
+		})
+	case req.Battery <= s.heartbeatConfig.LowBatteryPercent:
+		warnings = append(warnings, domain.HeartbeatWarning{
+			Code:    domain.HeartbeatWarningLowBattery,
+			Message: fmt.Sprintf("Battery at %d%%, return to base after the current job", req.Battery),
+		})
+	}
+	return warnings
+}
+
+// QueueCommand queues a command for a drone, it is sent with every heartbeat response until acknowledged
+func (s *DronesService) QueueCommand(ctx context.Context, droneID string, userID string, request *domain.QueueDroneCommandRequest) (*domain.DroneCommand, error) {
+	if _, err := s.GetDroneByID(ctx, droneID); err != nil {
+		s.logger.Error("Drone not found for command", "droneID", droneID, "error", err)
+		return nil, err
+	}
+
+	command, err := s.commandsRepo.QueueCommand(ctx, domain.DroneCommand{
+		DroneID:     droneID,
+		OrderID:     request.OrderID,
+		Command:     request.Command,
+		Lat:         request.Lat,
+		Lon:         request.Lon,
+		Note:        request.Note,
+		CreatedByID: &userID,
+	})
+	if err != nil {
+		s.logger.Error("Failed to queue drone command", "droneID", droneID, "command", request.Command, "error", err)
+		return nil, err
+	}
+
+	return command, nil
+}
+
+func (s *DronesService) ListCommands(ctx context.Context, droneID string, filter domain.DroneCommandFilter) ([]*domain.DroneCommand, error) {
+	if _, err := s.GetDroneByID(ctx, droneID); err != nil {
+		return nil, err
+	}
+	return s.commandsRepo.ListCommands(ctx, droneID, filter)
+}
+
+// GetTelemetry returns the flight history of a drone over a time range, downsampled to one point
+// per query interval, with the flight time over the range
+func (s *DronesService) GetTelemetry(ctx context.Context, droneID string, query domain.DroneTelemetryQuery) (*domain.DroneTelemetryHistory, error) {
+	if _, err := s.GetDroneByID(ctx, droneID); err != nil {
+		return nil, err
+	}
+
+	points, err := s.telemetryRepo.ListTelemetry(ctx, droneID, query)
+	if err != nil {
+		s.logger.Error("Failed to list drone telemetry", "droneID", droneID, "error", err)
+		return nil, err
+	}
+	if points == nil {
+		points = []*domain.DroneTelemetry{}
+	}
+
+	flightSeconds, err := s.telemetryRepo.SumFlightSeconds(ctx, droneID, query.From, query.To)
+	if err != nil {
+		s.logger.Error("Failed to sum drone flight time", "droneID", droneID, "error", err)
+		return nil, err
+	}
+
+	return &domain.DroneTelemetryHistory{
+		DroneID:         droneID,
+		From:            query.From.UTC().Format(time.RFC3339),
+		To:              query.To.UTC().Format(time.RFC3339),
+		IntervalSeconds: int(query.Interval / time.Second),
+		FlightHours:     flightSeconds / 3600,
+		Points:          points,
+	}, nil
 }
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	config "drones/configs"
+	"drones/internal/core/domain"
+)
+
+// go test ./internal/core/services/ -v
+
+func TestBrokenDroneInvalidatesHandedOffOrders(t *testing.T) {
+	drone := testDrone()
+	drone.Status = domain.DroneStatusDelivering
+	cache := newFakeCache()
+	ctx := context.Background()
+	cache.Set(ctx, "drones:"+drone.ID, drone, 0)
+	cache.Set(ctx, "orders:order-1:", domain.Order{Status: domain.OrderStatusInTransit, Version: 4}, 0)
+	repo := &fakeDronesRepo{handedOff: []domain.HandedOffOrder{
+		{OrderID: "order-1", FromStatus: domain.OrderStatusInTransit},
+		{OrderID: "order-2", FromStatus: domain.OrderStatusReserved},
+	}}
+
+	service := NewDronesService(repo, nil, nil, nil, nil, nil, nil, cache, &fakePublisher{}, config.HeartbeatConfig{}, config.DeliveryConfig{}, nopLogger{})
+	if _, err := service.UpdateDroneStatus(ctx, drone.UserID, drone.ID, domain.DroneStatusBroken); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	for _, key := range []string{"orders:order-1:", "orders:order-2:"} {
+		if !cache.wasDeleted(key) {
+			t.Errorf("Expected cache key %s to be invalidated", key)
+		}
+	}
+}
+
+func TestRestoreLostDroneOnlyFromLost(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       domain.DroneStatus
+		want         domain.DroneStatus
+		wantRestored int
+	}{
+		{"lost drone reports again", domain.DroneStatusLost, domain.DroneStatusIdle, 1},
+		{"admin grounded it first", domain.DroneStatusBroken, domain.DroneStatusBroken, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drone := testDrone()
+			drone.Status = tt.status
+			repo := &fakeDronesRepo{drone: drone}
+			publisher := &fakePublisher{}
+			service := NewDronesService(repo, nil, nil, nil, nil, nil, nil, newFakeCache(), publisher, config.HeartbeatConfig{}, config.DeliveryConfig{}, nopLogger{}).(*DronesService)
+
+			// The heartbeat read the drone while it was still lost
+			reported := *drone
+			reported.Status = domain.DroneStatusLost
+			restored, err := service.restoreLostDrone(context.Background(), drone.UserID, &reported)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if restored.Status != tt.want || repo.drone.Status != tt.want {
+				t.Errorf("Expected %s, got %s (stored %s)", tt.want, restored.Status, repo.drone.Status)
+			}
+			if len(publisher.restored) != tt.wantRestored {
+				t.Errorf("Expected %d drone restored events, got %d", tt.wantRestored, len(publisher.restored))
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	config "drones/configs"
+	"drones/internal/core/domain"
+	"drones/internal/core/events"
+	"drones/internal/ports"
+	"drones/pkg/utils"
+)
+
+// etaSpeedSmoothing weights the newest speed sample against the previous average
+const etaSpeedSmoothing = 0.5
+
+type EtaServiceImpl struct {
+	ordersRepo     ports.OrdersRepository
+	dronesRepo     ports.DronesRepository
+	cacheService   ports.CacheService
+	eventPublisher ports.EventPublisher
+	config         config.EtaConfig
+	logger         ports.Logger
+}
+
+func NewEtaService(
+	ordersRepo ports.OrdersRepository,
+	dronesRepo ports.DronesRepository,
+	cacheService ports.CacheService,
+	eventPublisher ports.EventPublisher,
+	config config.EtaConfig,
+	logger ports.Logger,
+) ports.EtaService {
+	return &EtaServiceImpl{
+		ordersRepo:     ordersRepo,
+		dronesRepo:     dronesRepo,
+		cacheService:   cacheService,
+		eventPublisher: eventPublisher,
+		config:         config,
+		logger:         logger,
+	}
+}
+
+func speedCacheKey(droneID string) string {
+	return fmt.Sprintf("drones:%s:speed", droneID)
+}
+
+func (s *EtaServiceImpl) OnHeartbeat(ctx context.Context, previous *domain.Drone, current *domain.Drone) error {
+	s.recordSpeed(ctx, previous, current)
+
+	// Only drones working on an order have an ETA to refresh
+	if current.Status != domain.DroneStatusLoading && current.Status != domain.DroneStatusDelivering {
+		return nil
+	}
+
+	order, err := s.ordersRepo.GetOrderByFilter(ctx, domain.OrderFilter{
+		DroneID:  &current.ID,
+		Statuses: domain.EtaTrackedStatuses,
+	})
+	if err != nil {
+		if err == domain.ErrOrderNotFound {
+			return nil
+		}
+		return err
+	}
+
+	_, err = s.update(ctx, order, current)
+	return err
+}
+
+func (s *EtaServiceImpl) RefreshOrder(ctx context.Context, order *domain.Order) (*domain.Order, error) {
+	if !order.IsEtaTracked() || order.DroneID == nil {
+		return order, nil
+	}
+
+	drone, err := s.dronesRepo.GetDroneByID(ctx, *order.DroneID)
+	if err != nil {
+		return order, err
+	}
+
+	return s.update(ctx, order, drone)
+}
+
+// update writes the new ETA and publishes it when it moved more than the threshold
+func (s *EtaServiceImpl) update(ctx context.Context, order *domain.Order, drone *domain.Drone) (*domain.Order, error) {
+	eta, ok := s.estimate(ctx, order, drone)
+	if !ok {
+		return order, nil
+	}
+
+	shifted := true
+	if order.EstimatedArrivalAt != nil {
+		if previous, err := time.Parse(time.RFC3339Nano, *order.EstimatedArrivalAt); err == nil {
+			shifted = math.Abs(eta.Sub(previous).Seconds()) > s.config.ShiftThreshold.Seconds()
+		}
+	}
+
+	estimatedArrivalAt := eta.Format(time.RFC3339)
+	updated, err := s.ordersRepo.UpdateOrder(ctx, order.ID, &domain.UpdateOrderRequest{
+		EstimatedArrivalAt: &estimatedArrivalAt,
+	})
+	if err != nil {
+		s.logger.Error("Failed to update order ETA", "orderID", order.ID, "error", err)
+		return order, err
+	}
+
+	if err := s.cacheService.Delete(ctx, fmt.Sprintf("orders:%s:", order.ID)); err != nil {
+		s.logger.Error("Failed to invalidate order cache", "orderID", order.ID, "error", err)
+	}
+
+	if shifted {
+		if err := s.eventPublisher.PublishOrderUpdated(ctx, events.OrderUpdatedEvent{
+			OrderID:            updated.ID,
+			UserID:             updated.UserID,
+			DroneID:            drone.ID,
+			Status:             updated.Status,
+			EstimatedArrivalAt: &estimatedArrivalAt,
+		}); err != nil {
+			s.logger.Error("Failed to publish order ETA event", "orderID", updated.ID, "error", err)
+		}
+	}
+
+	return updated, nil
+}
+
+// estimate returns the arrival time of the order, false when it cannot be computed
+func (s *EtaServiceImpl) estimate(ctx context.Context, order *domain.Order, drone *domain.Drone) (time.Time, bool) {
+	if drone.CurrentLat == nil || drone.CurrentLon == nil {
+		return time.Time{}, false
+	}
+
+	speedKmh := s.speedKmh(ctx, drone)
+	if speedKmh <= 0 {
+		return time.Time{}, false
+	}
+
+	distanceKm := order.RemainingDistanceKm(*drone.CurrentLat, *drone.CurrentLon)
+	return time.Now().UTC().Add(utils.TravelDuration(distanceKm, speedKmh)), true
+}
+
+// speedKmh uses the recent observed speed when the drone is moving, otherwise a share of its max speed
+func (s *EtaServiceImpl) speedKmh(ctx context.Context, drone *domain.Drone) float64 {
+	var observed float64
+	if err := s.cacheService.Get(ctx, speedCacheKey(drone.ID), &observed); err == nil && observed >= s.config.MinObservedSpeedKmh {
+		return math.Min(observed, drone.MaxSpeedKmh)
+	}
+	return drone.MaxSpeedKmh * s.config.CruiseSpeedFactor
+}
+
+// recordSpeed keeps a smoothed speed from consecutive heartbeats, it expires after the speed window
+func (s *EtaServiceImpl) recordSpeed(ctx context.Context, previous *domain.Drone, current *domain.Drone) {
+	if previous == nil || previous.CurrentLat == nil || previous.CurrentLon == nil || previous.LastLocationUpdateAt == nil ||
+		current.CurrentLat == nil || current.CurrentLon == nil || current.LastLocationUpdateAt == nil {
+		return
+	}
+
+	from, err := time.Parse(time.RFC3339Nano, *previous.LastLocationUpdateAt)
+	if err != nil {
+		return
+	}
+	to, err := time.Parse(time.RFC3339Nano, *current.LastLocationUpdateAt)
+	if err != nil {
+		return
+	}
+	elapsed := to.Sub(from)
+	if elapsed <= 0 || elapsed > s.config.SpeedWindow {
+		return
+	}
+
+	distanceKm := utils.HaversineKm(*previous.CurrentLat, *previous.CurrentLon, *current.CurrentLat, *current.CurrentLon)
+	// Clamp GPS jumps to what the drone can physically do
+	sample := math.Min(distanceKm/elapsed.Hours(), current.MaxSpeedKmh)
+
+	key := speedCacheKey(current.ID)
+	var average float64
+	if err := s.cacheService.Get(ctx, key, &average); err == nil {
+		sample = etaSpeedSmoothing*sample + (1-etaSpeedSmoothing)*average
+	}
+
+	if err := s.cacheService.Set(ctx, key, sample, int(s.config.SpeedWindow.Seconds())); err != nil {
+		s.logger.Error("Failed to cache drone speed", "droneID", current.ID, "error", err)
+	}
+}
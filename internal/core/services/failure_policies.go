@@ -0,0 +1,43 @@
+package services
+
+import (
+	config "drones/configs"
+	"drones/internal/core/domain"
+	"drones/internal/ports"
+)
+
+// AttemptsFailurePolicy re-queues a failed order until it used up its delivery attempts, then
+// applies the exhausted action. Failures another attempt cannot fix (unreachable address,
+// damaged package) skip the retries.
+type AttemptsFailurePolicy struct {
+	ExhaustedAction domain.DeliveryFailureAction
+	// Holding is only possible with a depot
+	HasDepot bool
+}
+
+// NewDeliveryFailurePolicy returns the failure policy for the delivery configuration.
+// An unknown exhausted action, or holding without a depot, falls back to return to sender.
+func NewDeliveryFailurePolicy(deliveryConfig config.DeliveryConfig) ports.DeliveryFailurePolicy {
+	policy := &AttemptsFailurePolicy{
+		ExhaustedAction: domain.DeliveryFailureAction(deliveryConfig.ExhaustedAction),
+		HasDepot:        deliveryConfig.DepotLat != 0 && deliveryConfig.DepotLon != 0,
+	}
+	if policy.ExhaustedAction == domain.DeliveryFailureActionRequeue || !policy.ExhaustedAction.IsValid() {
+		policy.ExhaustedAction = domain.DeliveryFailureActionReturnToSender
+	}
+	return policy
+}
+
+func (p *AttemptsFailurePolicy) Decide(order *domain.Order, request *domain.DeliveryFailedRequest) domain.DeliveryFailureAction {
+	retryable := request == nil || request.ReasonCode == "" || request.ReasonCode.IsRetryable()
+	if retryable && order.DeliveryAttempts < order.MaxDeliveryAttempts {
+		return domain.DeliveryFailureActionRequeue
+	}
+
+	// A damaged package goes back to the sender, it is not stored
+	if p.ExhaustedAction == domain.DeliveryFailureActionHoldAtDepot && p.HasDepot &&
+		(request == nil || request.ReasonCode != domain.DeliveryFailureReasonPackageDamaged) {
+		return domain.DeliveryFailureActionHoldAtDepot
+	}
+	return domain.DeliveryFailureActionReturnToSender
+}
@@ -0,0 +1,350 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	config "drones/configs"
+	"drones/internal/core/domain"
+	"drones/internal/core/events"
+	"drones/internal/ports"
+)
+
+// Test doubles for the ports. Each embeds its interface, so a test only implements the
+// methods the code under test calls and any other call panics.
+
+var errCacheMiss = errors.New("cache miss")
+
+// fakeCache stores JSON like the redis adapter, so cached values are copies
+type fakeCache struct {
+	ports.CacheService
+	mu      sync.Mutex
+	values  map[string][]byte
+	deleted []string
+}
+
+func newFakeCache() *fakeCache {
+	return &fakeCache{values: map[string][]byte{}}
+}
+
+func (c *fakeCache) Set(ctx context.Context, key string, value interface{}, ttl int) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key] = data
+	return nil
+}
+
+func (c *fakeCache) Get(ctx context.Context, key string, dest interface{}) error {
+	c.mu.Lock()
+	data, ok := c.values[key]
+	c.mu.Unlock()
+	if !ok {
+		return errCacheMiss
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (c *fakeCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.values, key)
+	c.deleted = append(c.deleted, key)
+	return nil
+}
+
+func (c *fakeCache) wasDeleted(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, deleted := range c.deleted {
+		if deleted == key {
+			return true
+		}
+	}
+	return false
+}
+
+// fakePublisher records the events published
+type fakePublisher struct {
+	ports.EventPublisher
+	mu       sync.Mutex
+	updated  []events.OrderUpdatedEvent
+	failed   []events.OrderDeliveryFailedEvent
+	restored []events.DroneRestoredEvent
+}
+
+func (p *fakePublisher) PublishOrderUpdated(ctx context.Context, event events.OrderUpdatedEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.updated = append(p.updated, event)
+	return nil
+}
+
+func (p *fakePublisher) PublishOrderDeliveryFailed(ctx context.Context, event events.OrderDeliveryFailedEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.failed = append(p.failed, event)
+	return nil
+}
+
+func (p *fakePublisher) PublishDroneRestored(ctx context.Context, event events.DroneRestoredEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.restored = append(p.restored, event)
+	return nil
+}
+
+func (p *fakePublisher) PublishSendOTP(ctx context.Context, event events.SendOTPEvent) error {
+	return nil
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Info(msg string, fields ...interface{})  {}
+func (nopLogger) Error(msg string, fields ...interface{}) {}
+func (nopLogger) Debug(msg string, fields ...interface{}) {}
+func (nopLogger) Warn(msg string, fields ...interface{})  {}
+
+// fakeOrdersRepo keeps orders in memory and applies status changes through the state machine,
+// as the postgres repository does under its row lock
+type fakeOrdersRepo struct {
+	ports.OrdersRepository
+	mu     sync.Mutex
+	orders map[string]*domain.Order
+	proofs map[string]*domain.DeliveryProof
+	// Optional overrides of the repository calls
+	expireReservations func(reservedBefore time.Time, limit int) ([]*domain.ExpiredReservation, error)
+}
+
+func newFakeOrdersRepo(orders ...*domain.Order) *fakeOrdersRepo {
+	repo := &fakeOrdersRepo{orders: map[string]*domain.Order{}, proofs: map[string]*domain.DeliveryProof{}}
+	for _, order := range orders {
+		repo.orders[order.ID] = order
+	}
+	return repo
+}
+
+func (r *fakeOrdersRepo) order(orderID string) *domain.Order {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	order, ok := r.orders[orderID]
+	if !ok {
+		return nil
+	}
+	copied := *order
+	return &copied
+}
+
+func (r *fakeOrdersRepo) GetOrderByID(ctx context.Context, orderID string, options domain.OrderFilter) (*domain.Order, error) {
+	order := r.order(orderID)
+	if order == nil {
+		return nil, domain.ErrOrderNotFound
+	}
+	return order, nil
+}
+
+func (r *fakeOrdersRepo) UpdateOrderStatus(ctx context.Context, orderID string, options domain.UpdateStatusRequest) (*domain.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	order, ok := r.orders[orderID]
+	if !ok {
+		return nil, domain.ErrOrderNotFound
+	}
+	if !options.Status.IsTransitionAllowed(order.Status) {
+		return nil, order.Status.TransitionErr()
+	}
+	order.Status = options.Status
+	order.Version++
+	switch {
+	case options.Status == domain.OrderStatusDelivered || options.Status == domain.OrderStatusReturned ||
+		options.Status == domain.OrderStatusHeldAtDepot:
+		order.DroneID = nil
+	case options.Status == domain.OrderStatusPending:
+		// Back at the origin, waiting for the next drone
+		order.DroneID = nil
+		lat, lon := order.OriginLat, order.OriginLon
+		order.CurrentLat, order.CurrentLon = &lat, &lon
+	case options.DroneID != "":
+		order.DroneID = &options.DroneID
+	}
+	if options.Status == domain.OrderStatusFailed {
+		order.DeliveryAttempts++
+	}
+	copied := *order
+	return &copied, nil
+}
+
+func (r *fakeOrdersRepo) UpdateOrder(ctx context.Context, orderID string, update *domain.UpdateOrderRequest) (*domain.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	order, ok := r.orders[orderID]
+	if !ok {
+		return nil, domain.ErrOrderNotFound
+	}
+	if update.ExpectedVersion != nil && *update.ExpectedVersion != order.Version {
+		return nil, domain.ErrOrderVersionConflict
+	}
+	if update.Status != nil && *update.Status != order.Status {
+		if !update.Status.IsTransitionAllowed(order.Status) {
+			return nil, order.Status.TransitionErr()
+		}
+		order.Status = *update.Status
+	}
+	if update.DeliveryNote != nil {
+		order.DeliveryNote = update.DeliveryNote
+	}
+	order.Version++
+	copied := *order
+	return &copied, nil
+}
+
+func (r *fakeOrdersRepo) ResolveDeliveryFailure(ctx context.Context, orderID string, resolution domain.DeliveryFailureResolution) (*domain.DeliveryFailureOutcome, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	order, ok := r.orders[orderID]
+	if !ok {
+		return nil, domain.ErrOrderNotFound
+	}
+	if order.Status != domain.OrderStatusFailed && !domain.OrderStatusFailed.IsTransitionAllowed(order.Status) {
+		return nil, order.Status.TransitionErr()
+	}
+	if resolution.ExpectedVersion != nil && *resolution.ExpectedVersion != order.Version {
+		return nil, domain.ErrOrderVersionConflict
+	}
+	to := resolution.Action.Status()
+	if !to.IsTransitionAllowed(domain.OrderStatusFailed) {
+		return nil, domain.OrderStatusFailed.TransitionErr()
+	}
+	// Failing and resolving commit together, nothing is written on an error
+	if order.Status != domain.OrderStatusFailed {
+		order.DeliveryAttempts++
+		order.DroneID = &resolution.DroneID
+	}
+	command := &domain.DroneCommand{
+		ID:      "command-" + orderID,
+		DroneID: resolution.DroneID,
+		OrderID: &orderID,
+		Command: domain.DroneCommandReturnToOrigin,
+	}
+	if resolution.Action == domain.DeliveryFailureActionHoldAtDepot {
+		command.Command = domain.DroneCommandDeliverToDepot
+	}
+	action := resolution.Action
+	order.Status = to
+	order.FailureAction = &action
+	order.Version++
+	copied := *order
+	return &domain.DeliveryFailureOutcome{Order: &copied, Action: action, Command: command}, nil
+}
+
+func (r *fakeOrdersRepo) GetDeliveryProof(ctx context.Context, orderID string) (*domain.DeliveryProof, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	proof, ok := r.proofs[orderID]
+	if !ok {
+		return nil, domain.ErrDeliveryProofNotFound
+	}
+	copied := *proof
+	return &copied, nil
+}
+
+// CheckDeliveryCode compares and counts under the lock, like the single UPDATE of the postgres repository
+func (r *fakeOrdersRepo) CheckDeliveryCode(ctx context.Context, orderID string, droneID string, codeHash string, maxAttempts int) (bool, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	proof, ok := r.proofs[orderID]
+	if !ok || proof.CodeHash == nil || proof.FailedAttempts >= maxAttempts {
+		return false, 0, domain.ErrDeliveryCodeLocked
+	}
+	matched := *proof.CodeHash == codeHash
+	if !matched {
+		proof.FailedAttempts++
+	}
+	return matched, proof.FailedAttempts, nil
+}
+
+func (r *fakeOrdersRepo) ExpireReservations(ctx context.Context, reservedBefore time.Time, limit int) ([]*domain.ExpiredReservation, error) {
+	return r.expireReservations(reservedBefore, limit)
+}
+
+// fakeDronesRepo hands off the configured orders when a drone breaks and keeps the status of drone
+type fakeDronesRepo struct {
+	ports.DronesRepository
+	handedOff []domain.HandedOffOrder
+	drone     *domain.Drone
+}
+
+func (r *fakeDronesRepo) UpdateStatusBroken(ctx context.Context, userID, droneID string, status domain.DroneStatus) (*domain.Drone, []domain.HandedOffOrder, error) {
+	drone := &domain.Drone{BaseModel: domain.BaseModel{ID: droneID}, Status: status}
+	if status != domain.DroneStatusBroken {
+		return drone, nil, nil
+	}
+	return drone, r.handedOff, nil
+}
+
+func (r *fakeDronesRepo) GetDroneByID(ctx context.Context, droneID string) (*domain.Drone, error) {
+	if r.drone == nil || r.drone.ID != droneID {
+		return nil, domain.ErrDroneNotFound
+	}
+	copied := *r.drone
+	return &copied, nil
+}
+
+// RestoreLostDrone only restores a drone that is still lost, like the guarded postgres update
+func (r *fakeDronesRepo) RestoreLostDrone(ctx context.Context, userID, droneID string) (*domain.Drone, error) {
+	if r.drone == nil || r.drone.ID != droneID || r.drone.Status != domain.DroneStatusLost {
+		return nil, domain.ErrDroneNotLost
+	}
+	r.drone.Status = domain.DroneStatusIdle
+	copied := *r.drone
+	return &copied, nil
+}
+
+// fakeDronesService resolves the drone of the calling user
+type fakeDronesService struct {
+	ports.DronesService
+	drone *domain.Drone
+}
+
+func (s *fakeDronesService) GetDroneByFilter(ctx context.Context, filter domain.DroneFilter) (*domain.Drone, error) {
+	if s.drone == nil {
+		return nil, domain.ErrDroneNotFound
+	}
+	return s.drone, nil
+}
+
+// fakeEtaService leaves ETAs alone
+type fakeEtaService struct {
+	ports.EtaService
+}
+
+func (fakeEtaService) RefreshOrder(ctx context.Context, order *domain.Order) (*domain.Order, error) {
+	return order, nil
+}
+
+// newTestOrdersService builds an orders service on the fakes, drone is the drone of the caller
+func newTestOrdersService(repo *fakeOrdersRepo, cache *fakeCache, publisher *fakePublisher, drone *domain.Drone) *OrdersServiceImpl {
+	deliveryConfig := config.DeliveryConfig{CodeLength: 6, MaxCodeAttempts: 3, MaxAttempts: 3, ExhaustedAction: string(domain.DeliveryFailureActionReturnToSender)}
+	return NewOrdersService(
+		repo,
+		nil,
+		&fakeDronesService{drone: drone},
+		fakeEtaService{},
+		nil,
+		nil,
+		nil,
+		cache,
+		nil,
+		publisher,
+		NewDeliveryFailurePolicy(deliveryConfig),
+		config.ScheduleConfig{},
+		deliveryConfig,
+		nopLogger{},
+	).(*OrdersServiceImpl)
+}
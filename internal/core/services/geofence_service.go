@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	config "drones/configs"
+	"drones/internal/core/domain"
+	"drones/internal/core/events"
+	"drones/internal/ports"
+)
+
+// geofencesCacheKey caches the geofences, every geofence change invalidates it
+const geofencesCacheKey = "geofences:all"
+
+type GeofenceServiceImpl struct {
+	repo           ports.GeofencesRepository
+	cacheService   ports.CacheService
+	eventPublisher ports.EventPublisher
+	location       *time.Location
+	logger         ports.Logger
+}
+
+func NewGeofenceService(
+	repo ports.GeofencesRepository,
+	cacheService ports.CacheService,
+	eventPublisher ports.EventPublisher,
+	config config.GeofenceConfig,
+	logger ports.Logger,
+) ports.GeofenceService {
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		logger.Warn("Unknown geofence time zone, using UTC", "timezone", config.Timezone, "error", err)
+		location = time.UTC
+	}
+
+	return &GeofenceServiceImpl{
+		repo:           repo,
+		cacheService:   cacheService,
+		eventPublisher: eventPublisher,
+		location:       location,
+		logger:         logger,
+	}
+}
+
+// ListGeofences returns the geofences not deleted, whatever their time window
+func (s *GeofenceServiceImpl) ListGeofences(ctx context.Context) ([]*domain.Geofence, error) {
+	var zones []*domain.Geofence
+	if err := s.cacheService.Get(ctx, geofencesCacheKey, &zones); err == nil && zones != nil {
+		return zones, nil
+	}
+
+	zones, err := s.repo.ListGeofences(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if zones == nil {
+		zones = []*domain.Geofence{}
+	}
+
+	if err := s.cacheService.Set(ctx, geofencesCacheKey, zones, 0); err != nil {
+		s.logger.Error("Failed to cache geofences", "key", geofencesCacheKey, "error", err)
+	}
+
+	return zones, nil
+}
+
+func (s *GeofenceServiceImpl) CreateGeofence(ctx context.Context, userID string, request *domain.GeofenceRequest) (*domain.Geofence, error) {
+	if err := validateGeofencePeriod(request); err != nil {
+		return nil, err
+	}
+	request.Normalize()
+
+	zone, err := s.repo.CreateGeofence(ctx, userID, request)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateGeofences(ctx)
+	return zone, nil
+}
+
+func (s *GeofenceServiceImpl) UpdateGeofence(ctx context.Context, geofenceID string, userID string, request *domain.GeofenceRequest) (*domain.Geofence, error) {
+	if err := validateGeofencePeriod(request); err != nil {
+		return nil, err
+	}
+	request.Normalize()
+
+	zone, err := s.repo.UpdateGeofence(ctx, geofenceID, userID, request)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateGeofences(ctx)
+	return zone, nil
+}
+
+func (s *GeofenceServiceImpl) DeleteGeofence(ctx context.Context, geofenceID string, userID string) error {
+	if err := s.repo.DeleteGeofence(ctx, geofenceID, userID); err != nil {
+		return err
+	}
+
+	s.invalidateGeofences(ctx)
+	return nil
+}
+
+// FindGeofence returns the zone active at a time that contains a position, nil when there is none
+func (s *GeofenceServiceImpl) FindGeofence(ctx context.Context, lat, lon float64, at time.Time) (*domain.Geofence, error) {
+	zones, err := s.ListGeofences(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return domain.FindGeofence(zones, lat, lon, at, s.location), nil
+}
+
+// RecordViolation records a drone entering a zone and alerts operators
+func (s *GeofenceServiceImpl) RecordViolation(ctx context.Context, violation domain.GeofenceViolation) (*domain.GeofenceViolation, error) {
+	recorded, err := s.repo.RecordViolation(ctx, violation)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Warn("Drone entered a no-fly zone", "droneID", recorded.DroneID, "geofenceID", recorded.GeofenceID, "geofence", recorded.GeofenceName)
+
+	if err := s.eventPublisher.PublishGeofenceViolation(ctx, events.GeofenceViolationEvent{
+		ViolationID:  recorded.ID,
+		GeofenceID:   recorded.GeofenceID,
+		GeofenceName: recorded.GeofenceName,
+		DroneID:      recorded.DroneID,
+		OrderID:      recorded.OrderID,
+		Lat:          recorded.Lat,
+		Lon:          recorded.Lon,
+		Altitude:     recorded.Altitude,
+	}); err != nil {
+		s.logger.Error("Failed to publish geofence violation event", "droneID", recorded.DroneID, "error", err)
+	}
+
+	return recorded, nil
+}
+
+func (s *GeofenceServiceImpl) ListViolations(ctx context.Context, filter domain.GeofenceViolationFilter) ([]*domain.GeofenceViolation, error) {
+	return s.repo.ListViolations(ctx, filter)
+}
+
+func (s *GeofenceServiceImpl) invalidateGeofences(ctx context.Context) {
+	if err := s.cacheService.Delete(ctx, geofencesCacheKey); err != nil {
+		s.logger.Error("Failed to invalidate geofences cache", "key", geofencesCacheKey, "error", err)
+	}
+}
+
+// validateGeofencePeriod checks the active period of a zone ends after it starts
+func validateGeofencePeriod(request *domain.GeofenceRequest) error {
+	if request.ActiveFrom == nil || request.ActiveUntil == nil {
+		return nil
+	}
+	from, err := time.Parse(time.RFC3339, *request.ActiveFrom)
+	if err != nil {
+		return domain.NewDomainError(domain.InvalidInputError, "Invalid active_from format", err)
+	}
+	until, err := time.Parse(time.RFC3339, *request.ActiveUntil)
+	if err != nil {
+		return domain.NewDomainError(domain.InvalidInputError, "Invalid active_until format", err)
+	}
+	if !until.After(from) {
+		return domain.ErrGeofencePeriod
+	}
+	return nil
+}
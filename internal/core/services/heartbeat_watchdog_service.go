@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	config "drones/configs"
+	"drones/internal/core/domain"
+	"drones/internal/core/events"
+	"drones/internal/ports"
+)
+
+type HeartbeatWatchdogServiceImpl struct {
+	dronesRepo     ports.DronesRepository
+	cacheService   ports.CacheService
+	eventPublisher ports.EventPublisher
+	config         config.HeartbeatConfig
+	logger         ports.Logger
+}
+
+func NewHeartbeatWatchdogService(
+	dronesRepo ports.DronesRepository,
+	cacheService ports.CacheService,
+	eventPublisher ports.EventPublisher,
+	config config.HeartbeatConfig,
+	logger ports.Logger,
+) ports.HeartbeatWatchdogService {
+	return &HeartbeatWatchdogServiceImpl{
+		dronesRepo:     dronesRepo,
+		cacheService:   cacheService,
+		eventPublisher: eventPublisher,
+		config:         config,
+		logger:         logger,
+	}
+}
+
+// DetectLostDrones marks loading, delivering and returning drones without a heartbeat within
+// the timeout as lost. Their orders are handed off as for a broken drone so a rescue drone can
+// pick them up, instead of staying in transit forever.
+func (s *HeartbeatWatchdogServiceImpl) DetectLostDrones(ctx context.Context) error {
+	lost, err := s.dronesRepo.MarkLostDrones(ctx, time.Now().Add(-s.config.Timeout), s.config.WatchdogBatchSize)
+	if err != nil {
+		s.logger.Error("Failed to mark lost drones", "error", err)
+		return err
+	}
+
+	for _, lostDrone := range lost {
+		drone := lostDrone.Drone
+
+		// The cached copy would still show the drone flying
+		if err := s.cacheService.Delete(ctx, "drones:"+drone.ID); err != nil {
+			s.logger.Error("Failed to invalidate drone cache", "droneID", drone.ID, "error", err)
+		}
+
+		orderIDs := make([]string, 0, len(lostDrone.Orders))
+		for _, order := range lostDrone.Orders {
+			orderIDs = append(orderIDs, order.OrderID)
+
+			if err := s.cacheService.Delete(ctx, fmt.Sprintf("orders:%s:", order.OrderID)); err != nil {
+				s.logger.Error("Failed to invalidate order cache", "orderID", order.OrderID, "error", err)
+			}
+
+			// Publish event, the order waits for a rescue drone
+			if err := s.eventPublisher.PublishOrderUpdated(ctx, events.OrderUpdatedEvent{
+				OrderID:    order.OrderID,
+				UserID:     order.UserID,
+				DroneID:    drone.ID,
+				Status:     domain.OrderStatusHandoff,
+				CurrentLat: drone.CurrentLat,
+				CurrentLon: drone.CurrentLon,
+			}); err != nil {
+				s.logger.Error("Failed to publish order updated event", "orderID", order.OrderID, "error", err)
+			}
+		}
+
+		s.logger.Warn("Drone lost, no heartbeat within timeout", "droneID", drone.ID, "lastHeartbeatAt", drone.LastLocationUpdateAt, "handedOffOrders", len(orderIDs))
+
+		if err := s.eventPublisher.PublishDroneLost(ctx, events.DroneLostEvent{
+			DroneID:         drone.ID,
+			DroneIdentifier: drone.DroneIdentifier,
+			LastHeartbeatAt: drone.LastLocationUpdateAt,
+			LastLat:         drone.CurrentLat,
+			LastLon:         drone.CurrentLon,
+			HandedOffOrders: orderIDs,
+		}); err != nil {
+			s.logger.Error("Failed to publish drone lost event", "droneID", drone.ID, "error", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	config "drones/configs"
+	"drones/internal/core/domain"
+	"drones/internal/ports"
+)
+
+type IdempotencyServiceImpl struct {
+	cacheService ports.CacheService
+	config       config.IdempotencyConfig
+	logger       ports.Logger
+}
+
+func NewIdempotencyService(
+	cacheService ports.CacheService,
+	config config.IdempotencyConfig,
+	logger ports.Logger,
+) ports.IdempotencyService {
+	return &IdempotencyServiceImpl{
+		cacheService: cacheService,
+		config:       config,
+		logger:       logger,
+	}
+}
+
+// Begin locks the key with an in-progress record. When the key is already taken the
+// stored record is checked against the request: a different request is a conflict,
+// an unfinished one is still in progress, a finished one is returned for replay.
+// The cache being unavailable never blocks a request, it is processed without replay.
+func (s *IdempotencyServiceImpl) Begin(ctx context.Context, userID string, key string, requestHash string) (*domain.IdempotencyRecord, error) {
+	cacheKey := idempotencyCacheKey(userID, key)
+
+	locked, err := s.cacheService.SetIfNotExists(ctx, cacheKey, domain.IdempotencyRecord{
+		RequestHash: requestHash,
+	}, int(s.config.LockTTL.Seconds()))
+	if err != nil {
+		s.logger.Error("Failed to lock idempotency key", "key", cacheKey, "error", err)
+		return nil, nil
+	}
+	if locked {
+		return nil, nil
+	}
+
+	var record domain.IdempotencyRecord
+	if err := s.cacheService.Get(ctx, cacheKey, &record); err != nil {
+		// The lock expired between the two calls
+		s.logger.Warn("Failed to read idempotency record", "key", cacheKey, "error", err)
+		return nil, nil
+	}
+
+	if record.RequestHash != requestHash {
+		return nil, domain.ErrIdempotencyKeyReused
+	}
+	if !record.Completed {
+		return nil, domain.ErrIdempotencyKeyInProgress
+	}
+
+	return &record, nil
+}
+
+func (s *IdempotencyServiceImpl) Complete(ctx context.Context, userID string, key string, record *domain.IdempotencyRecord) error {
+	cacheKey := idempotencyCacheKey(userID, key)
+
+	record.Completed = true
+	if err := s.cacheService.Set(ctx, cacheKey, record, int(s.config.Window.Seconds())); err != nil {
+		s.logger.Error("Failed to store idempotency record", "key", cacheKey, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (s *IdempotencyServiceImpl) Release(ctx context.Context, userID string, key string) error {
+	cacheKey := idempotencyCacheKey(userID, key)
+
+	if err := s.cacheService.Delete(ctx, cacheKey); err != nil {
+		s.logger.Error("Failed to release idempotency key", "key", cacheKey, "error", err)
+		return err
+	}
+	return nil
+}
+
+// idempotencyCacheKey scopes keys per user so clients cannot collide with each other
+func idempotencyCacheKey(userID string, key string) string {
+	return fmt.Sprintf("idempotency:%s:%s", userID, key)
+}
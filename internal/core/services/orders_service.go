@@ -2,37 +2,256 @@ package services
 
 import (
 	"context"
+	config "drones/configs"
 	"drones/internal/core/domain"
 	"drones/internal/core/events"
 	"drones/internal/ports"
+	"drones/pkg/utils"
 	"fmt"
 	"time"
 )
 
 type OrdersServiceImpl struct {
-	repo           ports.OrdersRepository
-	dronesService  ports.DronesService
-	cacheService   ports.CacheService
-	eventPublisher ports.EventPublisher
-	logger         ports.Logger
+	repo               ports.OrdersRepository
+	telemetryRepo      ports.DroneTelemetryRepository
+	dronesService      ports.DronesService
+	etaService         ports.EtaService
+	pricingService     ports.PricingService
+	geofenceService    ports.GeofenceService
+	serviceAreaService ports.ServiceAreaService
+	cacheService       ports.CacheService
+	blobStorage        ports.BlobStorage
+	eventPublisher     ports.EventPublisher
+	failurePolicy      ports.DeliveryFailurePolicy
+	scheduleConfig     config.ScheduleConfig
+	deliveryConfig     config.DeliveryConfig
+	logger             ports.Logger
 }
 
 func NewOrdersService(
 	repo ports.OrdersRepository,
+	telemetryRepo ports.DroneTelemetryRepository,
 	dronesService ports.DronesService,
+	etaService ports.EtaService,
+	pricingService ports.PricingService,
+	geofenceService ports.GeofenceService,
+	serviceAreaService ports.ServiceAreaService,
 	cacheService ports.CacheService,
+	blobStorage ports.BlobStorage,
 	eventPublisher ports.EventPublisher,
+	failurePolicy ports.DeliveryFailurePolicy,
+	scheduleConfig config.ScheduleConfig,
+	deliveryConfig config.DeliveryConfig,
 	logger ports.Logger,
 ) ports.OrdersService {
-	return &OrdersServiceImpl{repo: repo, dronesService: dronesService, cacheService: cacheService, eventPublisher: eventPublisher, logger: logger}
+	return &OrdersServiceImpl{repo: repo, telemetryRepo: telemetryRepo, dronesService: dronesService, etaService: etaService, pricingService: pricingService, geofenceService: geofenceService, serviceAreaService: serviceAreaService, cacheService: cacheService, blobStorage: blobStorage, eventPublisher: eventPublisher, failurePolicy: failurePolicy, scheduleConfig: scheduleConfig, deliveryConfig: deliveryConfig, logger: logger}
 }
 
 func (s *OrdersServiceImpl) CreateOrder(ctx context.Context, userID string, order *domain.CreateOrderRequest) (*domain.Order, error) {
+	if err := s.prepareCreateOrder(order); err != nil {
+		return nil, err
+	}
+	if err := s.assignServiceArea(ctx, order); err != nil {
+		return nil, err
+	}
+	if err := s.checkOrderGeofences(ctx, order); err != nil {
+		return nil, err
+	}
+	if err := s.pricingService.PriceOrder(ctx, userID, order); err != nil {
+		return nil, err
+	}
+
 	newOrder, err := s.repo.CreateOrder(ctx, userID, order)
 	if err != nil {
 		return nil, err
 	}
 
+	s.onOrderCreated(ctx, userID, newOrder, order.DeliveryCode)
+
+	return newOrder, nil
+}
+
+// BulkCreateOrders inserts already validated rows in chunks, a failing chunk only fails its own rows
+func (s *OrdersServiceImpl) BulkCreateOrders(ctx context.Context, userID string, rows []domain.BulkOrderRow) ([]domain.BulkOrderResult, error) {
+	if len(rows) == 0 {
+		return nil, domain.ErrBulkOrdersEmpty
+	}
+	if len(rows) > domain.MaxBulkOrders {
+		return nil, domain.ErrBulkOrdersTooLarge
+	}
+
+	results := make([]domain.BulkOrderResult, 0, len(rows))
+	pending := make([]domain.BulkOrderRow, 0, len(rows))
+	for _, row := range rows {
+		if err := s.prepareCreateOrder(row.Order); err != nil {
+			results = append(results, bulkOrderFailure(row.Row, err))
+			continue
+		}
+		if err := s.assignServiceArea(ctx, row.Order); err != nil {
+			results = append(results, bulkOrderFailure(row.Row, err))
+			continue
+		}
+		if err := s.checkOrderGeofences(ctx, row.Order); err != nil {
+			results = append(results, bulkOrderFailure(row.Row, err))
+			continue
+		}
+		if err := s.pricingService.PriceOrder(ctx, userID, row.Order); err != nil {
+			results = append(results, bulkOrderFailure(row.Row, err))
+			continue
+		}
+		pending = append(pending, row)
+	}
+
+	for start := 0; start < len(pending); start += domain.BulkOrdersChunkSize {
+		end := start + domain.BulkOrdersChunkSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		chunk := pending[start:end]
+
+		requests := make([]*domain.CreateOrderRequest, len(chunk))
+		for i, row := range chunk {
+			requests[i] = row.Order
+		}
+
+		created, err := s.repo.CreateOrders(ctx, userID, requests)
+		if err != nil {
+			s.logger.Error("Failed to create bulk orders chunk", "from", chunk[0].Row, "to", chunk[len(chunk)-1].Row, "error", err)
+			for _, row := range chunk {
+				results = append(results, bulkOrderFailure(row.Row, domain.NewDomainError(domain.UnableToCreateError, "Unable to create order", err)))
+			}
+			continue
+		}
+
+		for i, order := range created {
+			s.onOrderCreated(ctx, userID, order, chunk[i].Order.DeliveryCode)
+
+			status := order.Status
+			results = append(results, domain.BulkOrderResult{
+				Row:         chunk[i].Row,
+				OrderID:     &order.ID,
+				OrderNumber: &order.OrderNumber,
+				Status:      &status,
+			})
+		}
+	}
+
+	return results, nil
+}
+
+// prepareCreateOrder sets the initial status and the delivery code, orders scheduled beyond the
+// lead time wait for the scheduler to release them
+func (s *OrdersServiceImpl) prepareCreateOrder(order *domain.CreateOrderRequest) error {
+	code := utils.GenerateOTP(s.deliveryConfig.CodeLength)
+	salt := utils.GenerateSalt(16)
+	order.DeliveryCode = &domain.DeliveryCode{Code: code, Hash: utils.HashCode(code, salt), Salt: salt}
+
+	order.Priority = order.Priority.OrDefault()
+	order.MaxDeliveryAttempts = s.deliveryConfig.MaxAttempts
+	order.Status = domain.OrderStatusPending
+	if order.ScheduledAt != nil {
+		scheduledAt, err := time.Parse(time.RFC3339, *order.ScheduledAt)
+		if err != nil {
+			return domain.NewDomainError(domain.InvalidInputError, "Invalid scheduled_at format", err)
+		}
+		// scheduled_at has no time zone in the database, keep it in UTC
+		utc := scheduledAt.UTC().Format(time.RFC3339)
+		order.ScheduledAt = &utc
+		if time.Until(scheduledAt) > s.scheduleConfig.LeadTime {
+			order.Status = domain.OrderStatusScheduled
+		}
+	}
+	return nil
+}
+
+// assignServiceArea places a new order in the first service area containing both its origin and
+// destination. The receiver phone number must match the formats of that area.
+func (s *OrdersServiceImpl) assignServiceArea(ctx context.Context, order *domain.CreateOrderRequest) error {
+	origin := domain.GeoPoint{Lat: order.OriginLat, Lon: order.OriginLon}
+	destination := domain.GeoPoint{Lat: order.DestinationLat, Lon: order.DestinationLon}
+
+	areas, err := s.serviceAreaService.ListServiceAreas(ctx)
+	if err != nil {
+		s.logger.Error("Failed to check service areas", "error", err)
+		return err
+	}
+	area := domain.FindServiceArea(areas, origin, destination)
+	if area == nil {
+		if domain.FindServiceArea(areas, origin) == nil {
+			return domain.ErrOriginOutsideServiceArea
+		}
+		return domain.ErrDestinationOutsideServiceArea
+	}
+
+	if order.ReceiverPhone != nil && !area.AllowsPhone(*order.ReceiverPhone) {
+		return domain.ErrReceiverPhoneNotAllowed
+	}
+
+	order.ServiceAreaID = &area.ID
+	return nil
+}
+
+// checkRouteServiceArea keeps a changed route inside the service area the order was placed in,
+// or inside any one area when that area was deleted since
+func (s *OrdersServiceImpl) checkRouteServiceArea(ctx context.Context, order *domain.Order, route domain.OrderRoute) error {
+	origin := domain.GeoPoint{Lat: route.OriginLat, Lon: route.OriginLon}
+	destination := domain.GeoPoint{Lat: route.DestinationLat, Lon: route.DestinationLon}
+
+	areas, err := s.serviceAreaService.ListServiceAreas(ctx)
+	if err != nil {
+		s.logger.Error("Failed to check service areas", "orderID", order.ID, "error", err)
+		return err
+	}
+	if order.ServiceAreaID != nil {
+		for _, area := range areas {
+			if area.ID == *order.ServiceAreaID {
+				areas = []*domain.ServiceArea{area}
+				break
+			}
+		}
+	}
+
+	if domain.FindServiceArea(areas, origin, destination) == nil {
+		if domain.FindServiceArea(areas, origin) == nil {
+			return domain.ErrOriginOutsideServiceArea
+		}
+		return domain.ErrDestinationOutsideServiceArea
+	}
+	return nil
+}
+
+// checkOrderGeofences rejects a new order picked up or dropped off inside a no-fly zone
+func (s *OrdersServiceImpl) checkOrderGeofences(ctx context.Context, order *domain.CreateOrderRequest) error {
+	if err := s.checkNoFlyZone(ctx, order.OriginLat, order.OriginLon, order.ScheduledAt, domain.ErrOriginInNoFlyZone); err != nil {
+		return err
+	}
+	return s.checkNoFlyZone(ctx, order.DestinationLat, order.DestinationLon, order.ScheduledAt, domain.ErrDestinationInNoFlyZone)
+}
+
+// checkNoFlyZone returns errInZone when a position is inside a no-fly zone active at the time the
+// order is flown, now unless it is scheduled later
+func (s *OrdersServiceImpl) checkNoFlyZone(ctx context.Context, lat, lon float64, scheduledAt *string, errInZone error) error {
+	at := time.Now()
+	if scheduledAt != nil {
+		if parsed, err := time.Parse(time.RFC3339, *scheduledAt); err == nil && parsed.After(at) {
+			at = parsed
+		}
+	}
+
+	zone, err := s.geofenceService.FindGeofence(ctx, lat, lon, at)
+	if err != nil {
+		s.logger.Error("Failed to check no-fly zones", "error", err)
+		return err
+	}
+	if zone != nil {
+		s.logger.Info("Order position inside a no-fly zone", "geofenceID", zone.ID, "geofence", zone.Name)
+		return errInZone
+	}
+	return nil
+}
+
+// onOrderCreated caches the new order, publishes the created event and sends the delivery code to the receiver
+func (s *OrdersServiceImpl) onOrderCreated(ctx context.Context, userID string, newOrder *domain.Order, code *domain.DeliveryCode) {
 	// Cache the new order
 	cacheKey := fmt.Sprintf("orders:%s:", newOrder.ID)
 
@@ -50,11 +269,33 @@ func (s *OrdersServiceImpl) CreateOrder(ctx context.Context, userID string, orde
 		DestinationAddress: newOrder.DestinationAddress,
 		DestinationLat:     newOrder.DestinationLat,
 		DestinationLon:     newOrder.DestinationLon,
+		Priority:           newOrder.Priority,
 	}); err != nil {
 		s.logger.Error("Failed to publish order created event", "orderID", newOrder.ID, "error", err)
 	}
 
-	return newOrder, nil
+	if code != nil && newOrder.ReceiverPhone != nil {
+		if err := s.eventPublisher.PublishSendOTP(ctx, events.SendOTPEvent{
+			ID:         newOrder.ID,
+			Recipient:  *newOrder.ReceiverPhone,
+			UserID:     userID,
+			TemplateID: domain.DeliveryCodeTemplateID,
+			Params: map[string]string{
+				"code":         code.Code,
+				"order_number": newOrder.OrderNumber,
+			},
+		}); err != nil {
+			s.logger.Error("Failed to publish delivery code", "orderID", newOrder.ID, "error", err)
+		}
+	}
+}
+
+func bulkOrderFailure(row int, err error) domain.BulkOrderResult {
+	message := err.Error()
+	if domainErr, ok := err.(*domain.DomainError); ok {
+		message = domainErr.Message
+	}
+	return domain.BulkOrderResult{Row: row, Error: &message}
 }
 
 func (s *OrdersServiceImpl) GetOrderByID(ctx context.Context, orderID string, options domain.OrderFilter) (*domain.Order, error) {
@@ -76,17 +317,66 @@ func (s *OrdersServiceImpl) ListOrders(ctx context.Context, options domain.Pagin
 }
 
 func (s *OrdersServiceImpl) UpdateOrder(ctx context.Context, orderID string, update *domain.UpdateOrderRequest, options domain.OrderFilter) (*domain.Order, error) {
-	if _, err := s.repo.GetOrderByID(ctx, orderID, options); err != nil {
+	order, err := s.repo.GetOrderByID(ctx, orderID, options)
+	if err != nil {
 		return nil, err
 	}
-	return s.repo.UpdateOrder(ctx, orderID, update)
+
+	// Route changes are repriced and pushed to the drone, they go through ChangeRoute
+	if update.OriginAddress != nil || update.OriginLat != nil || update.OriginLon != nil ||
+		update.DestinationAddress != nil || update.DestinationLat != nil || update.DestinationLon != nil {
+		return nil, domain.ErrChangeRouteThroughChangeRoute
+	}
+
+	// Status changes must follow the order state machine
+	if update.Status != nil && *update.Status != order.Status {
+		if !update.Status.IsValid() {
+			return nil, domain.ErrInvalidOrderStatus
+		}
+		// Cancelling has to release the drone, it goes through CancelOrder
+		if *update.Status == domain.OrderStatusCancelled {
+			return nil, domain.ErrCancelThroughCancelOrder
+		}
+		// Delivering needs the receiver code and the proof, it goes through ConfirmDelivery
+		if *update.Status == domain.OrderStatusDelivered {
+			return nil, domain.ErrDeliverThroughConfirmDelivery
+		}
+		// Releasing a reservation frees the drone, the reservation expiry does it
+		if order.Status == domain.OrderStatusReserved && *update.Status == domain.OrderStatusPending {
+			return nil, domain.ErrReservationReleasedOnExpiry
+		}
+		// Releasing a package on its way back frees the drone, it confirms the drop-off first
+		if released, ok := order.Status.ReturnConfirmedStatus(); ok && *update.Status == released {
+			return nil, domain.ErrReleaseThroughConfirmReturn
+		}
+		if !update.Status.IsTransitionAllowed(order.Status) {
+			return nil, order.Status.TransitionErr()
+		}
+	}
+
+	order, err = s.repo.UpdateOrder(ctx, orderID, update)
+	if err != nil {
+		return nil, err
+	}
+
+	order = s.refreshEta(ctx, order)
+
+	// Drop the cached copy so reads see the update
+	s.invalidateOrderCache(ctx, orderID, nil)
+
+	return order, nil
 }
 
 func (s *OrdersServiceImpl) DeleteOrder(ctx context.Context, orderID string, options domain.OrderFilter) error {
 	if _, err := s.repo.GetOrderByID(ctx, orderID, options); err != nil {
 		return err
 	}
-	return s.repo.DeleteOrder(ctx, orderID)
+	if err := s.repo.DeleteOrder(ctx, orderID); err != nil {
+		return err
+	}
+
+	s.invalidateOrderCache(ctx, orderID, nil)
+	return nil
 }
 
 func (s *OrdersServiceImpl) Withdraw(ctx context.Context, orderID string, userID string, options domain.OrderFilter) (*domain.Order, error) {
@@ -96,20 +386,23 @@ func (s *OrdersServiceImpl) Withdraw(ctx context.Context, orderID string, userID
 		return nil, err
 	}
 
-	if order.Status != domain.OrderStatusPending {
-		return nil, domain.ErrWithdrawNotAllowed
-	}
-
 	if order.Status == domain.OrderStatusCancelled {
 		return nil, domain.ErrAlreadyWithdrawed
 	}
 
+	// Customers can only withdraw before a drone takes the order
+	if order.Status != domain.OrderStatusPending && order.Status != domain.OrderStatusScheduled {
+		return nil, domain.ErrWithdrawNotAllowed
+	}
+
 	status := domain.OrderStatusCancelled
 	cancelledAt := time.Now().Format(time.RFC3339)
+	reason := domain.OrderStatusReasonWithdrawn
 	order, err = s.repo.UpdateOrder(ctx, orderID, &domain.UpdateOrderRequest{
-		Status:      &status,
-		UpdatedByID: &userID,
-		CancelledAt: &cancelledAt,
+		Status:       &status,
+		UpdatedByID:  &userID,
+		CancelledAt:  &cancelledAt,
+		StatusReason: &reason,
 	})
 
 	if err != nil {
@@ -117,6 +410,8 @@ func (s *OrdersServiceImpl) Withdraw(ctx context.Context, orderID string, userID
 		return nil, err
 	}
 
+	s.invalidateOrderCache(ctx, order.ID, nil)
+
 	// Publish event
 	if err := s.eventPublisher.PublishOrderUpdated(ctx, events.OrderUpdatedEvent{
 		OrderID: orderID,
@@ -138,7 +433,16 @@ func (s *OrdersServiceImpl) Reserve(ctx context.Context, orderID string, userID
 		return nil, domain.ErrAlreadyReserved
 	}
 
-	if order.Status != domain.OrderStatusPending {
+	if order.Status == domain.OrderStatusScheduled {
+		return nil, domain.ErrOrderNotReleased
+	}
+
+	// Handoff jobs are reserved by a rescue drone
+	if order.Status == domain.OrderStatusHandoff {
+		return s.Reassign(ctx, orderID, userID, options)
+	}
+
+	if !domain.OrderStatusReserved.IsTransitionAllowed(order.Status) {
 		return nil, domain.ErrReserveNotAllowed
 	}
 
@@ -157,6 +461,10 @@ func (s *OrdersServiceImpl) Reserve(ctx context.Context, orderID string, userID
 		return nil, drone.Status.GetErr()
 	}
 
+	if err := drone.CheckCanPerform(order); err != nil {
+		return nil, err
+	}
+
 	status := domain.OrderStatusReserved
 	order, err = s.repo.UpdateOrderStatus(ctx, orderID, domain.UpdateStatusRequest{
 		DroneID:     drone.ID,
@@ -167,6 +475,10 @@ func (s *OrdersServiceImpl) Reserve(ctx context.Context, orderID string, userID
 		return nil, err
 	}
 
+	s.invalidateOrderCache(ctx, order.ID, &drone.ID)
+
+	order = s.refreshEta(ctx, order)
+
 	// Publish event
 	if err := s.eventPublisher.PublishOrderUpdated(ctx, events.OrderUpdatedEvent{
 		OrderID: orderID,
@@ -187,7 +499,7 @@ func (s *OrdersServiceImpl) ConfirmPickup(ctx context.Context, orderID string, u
 		return nil, err
 	}
 
-	if order.Status != domain.OrderStatusReserved {
+	if !domain.OrderStatusPickedUp.IsTransitionAllowed(order.Status) {
 		return nil, domain.ErrConfirmNotAllowed
 	}
 
@@ -204,15 +516,15 @@ func (s *OrdersServiceImpl) ConfirmPickup(ctx context.Context, orderID string, u
 		UpdatedByID: userID,
 		Status:      status,
 	})
-	if err != nil {
-		return nil, err
-	}
-
 	if err != nil {
 		s.logger.Error("Failed to confirm pickup for order", "orderID", orderID, "error", err)
 		return nil, err
 	}
 
+	s.invalidateOrderCache(ctx, order.ID, &drone.ID)
+
+	order = s.refreshEta(ctx, order)
+
 	// Publish event
 	if err := s.eventPublisher.PublishOrderUpdated(ctx, events.OrderUpdatedEvent{
 		OrderID: orderID,
@@ -232,7 +544,7 @@ func (s *OrdersServiceImpl) StartTransit(ctx context.Context, orderID string, us
 		return nil, err
 	}
 
-	if order.Status != domain.OrderStatusPickedUp && order.Status != domain.OrderStatusReassigned {
+	if !domain.OrderStatusInTransit.IsTransitionAllowed(order.Status) {
 		return nil, domain.ErrTransitNotAllowed
 	}
 	drone, err := s.dronesService.GetDroneByFilter(ctx, domain.DroneFilter{
@@ -251,6 +563,10 @@ func (s *OrdersServiceImpl) StartTransit(ctx context.Context, orderID string, us
 		return nil, err
 	}
 
+	s.invalidateOrderCache(ctx, order.ID, &drone.ID)
+
+	order = s.refreshEta(ctx, order)
+
 	// Publish event
 	if err := s.eventPublisher.PublishOrderUpdated(ctx, events.OrderUpdatedEvent{
 		OrderID: orderID,
@@ -270,7 +586,7 @@ func (s *OrdersServiceImpl) ConfirmArrived(ctx context.Context, orderID string,
 		return nil, err
 	}
 
-	if order.Status != domain.OrderStatusInTransit {
+	if !domain.OrderStatusArrived.IsTransitionAllowed(order.Status) {
 		return nil, domain.ErrArriveNotAllowed
 	}
 
@@ -290,6 +606,10 @@ func (s *OrdersServiceImpl) ConfirmArrived(ctx context.Context, orderID string,
 		return nil, err
 	}
 
+	s.invalidateOrderCache(ctx, order.ID, &drone.ID)
+
+	order = s.refreshEta(ctx, order)
+
 	// Publish event
 	if err := s.eventPublisher.PublishOrderUpdated(ctx, events.OrderUpdatedEvent{
 		OrderID: orderID,
@@ -303,13 +623,14 @@ func (s *OrdersServiceImpl) ConfirmArrived(ctx context.Context, orderID string,
 	return order, nil
 }
 
-func (s *OrdersServiceImpl) ConfirmDelivery(ctx context.Context, orderID string, userID string, options domain.OrderFilter) (*domain.Order, error) {
+// ConfirmDelivery delivers an order once the drone proved it is at the destination with the receiver code
+func (s *OrdersServiceImpl) ConfirmDelivery(ctx context.Context, orderID string, userID string, request *domain.ConfirmDeliveryRequest, options domain.OrderFilter) (*domain.Order, error) {
 	order, err := s.repo.GetOrderByID(ctx, orderID, options)
 	if err != nil {
 		return nil, err
 	}
 
-	if order.Status != domain.OrderStatusArrived {
+	if !domain.OrderStatusDelivered.IsTransitionAllowed(order.Status) {
 		return nil, domain.ErrDeliverNotAllowed
 	}
 	drone, err := s.dronesService.GetDroneByFilter(ctx, domain.DroneFilter{
@@ -318,6 +639,30 @@ func (s *OrdersServiceImpl) ConfirmDelivery(ctx context.Context, orderID string,
 	if err != nil {
 		return nil, err
 	}
+
+	// The GPS fix must be close enough to the destination
+	distanceM := utils.HaversineKm(request.Lat, request.Lon, order.DestinationLat, order.DestinationLon) * 1000
+	if distanceM > s.deliveryConfig.MaxDistanceMeters {
+		s.logger.Warn("Delivery rejected, drone too far from destination", "orderID", orderID, "droneID", drone.ID, "distanceM", distanceM)
+		return nil, domain.ErrDeliveryOutOfRange
+	}
+
+	if err := s.verifyDeliveryCode(ctx, orderID, drone.ID, request.DeliveryCode); err != nil {
+		return nil, err
+	}
+
+	proof := &domain.DeliveryProofUpdate{
+		DroneID:   drone.ID,
+		Lat:       request.Lat,
+		Lon:       request.Lon,
+		DistanceM: distanceM,
+	}
+	if len(request.Photo) > 0 {
+		if err := s.storeDeliveryPhoto(ctx, orderID, request, proof); err != nil {
+			return nil, err
+		}
+	}
+
 	status := domain.OrderStatusDelivered
 	deliveredAt := time.Now().Format(time.RFC3339)
 	order, err = s.repo.UpdateOrderStatus(ctx, orderID, domain.UpdateStatusRequest{
@@ -325,11 +670,20 @@ func (s *OrdersServiceImpl) ConfirmDelivery(ctx context.Context, orderID string,
 		UpdatedByID: userID,
 		Status:      status,
 		DeliveredAt: &deliveredAt,
+		Proof:       proof,
 	})
 	if err != nil {
+		// The photo is only kept with a proof
+		if proof.PhotoKey != nil {
+			if err := s.blobStorage.Delete(ctx, *proof.PhotoKey); err != nil {
+				s.logger.Error("Failed to delete delivery photo", "orderID", orderID, "key", *proof.PhotoKey, "error", err)
+			}
+		}
 		return nil, err
 	}
 
+	s.invalidateOrderCache(ctx, order.ID, &drone.ID)
+
 	// Publish event
 	if err := s.eventPublisher.PublishOrderUpdated(ctx, events.OrderUpdatedEvent{
 		OrderID: orderID,
@@ -343,78 +697,202 @@ func (s *OrdersServiceImpl) ConfirmDelivery(ctx context.Context, orderID string,
 	return order, nil
 }
 
-func (s *OrdersServiceImpl) Handoff(ctx context.Context, orderID string, droneID string, options domain.OrderFilter) (*domain.Order, error) {
+// verifyDeliveryCode checks the receiver code, the order is locked after too many wrong codes.
+// Orders created before delivery codes were issued have a proof row without a code and nothing
+// to check; an order without a proof row cannot be verified and is never delivered.
+func (s *OrdersServiceImpl) verifyDeliveryCode(ctx context.Context, orderID string, droneID string, code string) error {
+	proof, err := s.repo.GetDeliveryProof(ctx, orderID)
+	if err != nil {
+		if err == domain.ErrDeliveryProofNotFound {
+			s.logger.Error("Delivery rejected, order has no delivery proof", "orderID", orderID, "droneID", droneID)
+		}
+		return err
+	}
+	if proof.CodeHash == nil || proof.CodeSalt == nil {
+		return nil
+	}
+
+	// The comparison and the attempt count are one statement, the salt never changes
+	matched, attempts, err := s.repo.CheckDeliveryCode(ctx, orderID, droneID, utils.HashCode(code, *proof.CodeSalt), s.deliveryConfig.MaxCodeAttempts)
+	if err != nil {
+		return err
+	}
+	if !matched {
+		s.logger.Warn("Invalid delivery code", "orderID", orderID, "droneID", droneID, "attempts", attempts)
+		if attempts >= s.deliveryConfig.MaxCodeAttempts {
+			return domain.ErrDeliveryCodeLocked
+		}
+		return domain.ErrInvalidDeliveryCode
+	}
+
+	return nil
+}
+
+// storeDeliveryPhoto checks the photo and stores it, the key is set on the proof
+func (s *OrdersServiceImpl) storeDeliveryPhoto(ctx context.Context, orderID string, request *domain.ConfirmDeliveryRequest, proof *domain.DeliveryProofUpdate) error {
+	if int64(len(request.Photo)) > s.deliveryConfig.MaxPhotoBytes {
+		return domain.ErrDeliveryPhotoTooLarge
+	}
+	extension, ok := domain.DeliveryPhotoContentTypes[request.PhotoContentType]
+	if !ok {
+		return domain.ErrInvalidDeliveryPhoto
+	}
+
+	key := fmt.Sprintf("orders/%s/delivery-%d%s", orderID, time.Now().UnixNano(), extension)
+	if err := s.blobStorage.Put(ctx, key, request.Photo); err != nil {
+		s.logger.Error("Failed to store delivery photo", "orderID", orderID, "error", err)
+		return domain.NewDomainError(domain.UnableToProcessError, "Unable to store delivery photo", err)
+	}
+
+	contentType := request.PhotoContentType
+	proof.PhotoKey = &key
+	proof.PhotoContentType = &contentType
+	return nil
+}
+
+// GetDeliveryProof returns the delivery proof of an order the caller may see
+func (s *OrdersServiceImpl) GetDeliveryProof(ctx context.Context, orderID string, options domain.OrderFilter) (*domain.DeliveryProof, error) {
+	// Ownership is checked through the order filter
+	if _, err := s.repo.GetOrderByID(ctx, orderID, options); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetDeliveryProof(ctx, orderID)
+}
+
+// GetDeliveryPhoto returns the photo of the delivery proof of an order the caller may see
+func (s *OrdersServiceImpl) GetDeliveryPhoto(ctx context.Context, orderID string, options domain.OrderFilter) (*domain.DeliveryPhoto, error) {
+	proof, err := s.GetDeliveryProof(ctx, orderID, options)
+	if err != nil {
+		return nil, err
+	}
+	if proof.PhotoKey == nil {
+		return nil, domain.ErrDeliveryPhotoNotFound
+	}
+
+	data, err := s.blobStorage.Get(ctx, *proof.PhotoKey)
+	if err != nil {
+		if err == domain.ErrBlobNotFound {
+			s.logger.Warn("Delivery photo missing from storage", "orderID", orderID, "key", *proof.PhotoKey)
+			return nil, domain.ErrDeliveryPhotoNotFound
+		}
+		s.logger.Error("Failed to read delivery photo", "orderID", orderID, "error", err)
+		return nil, err
+	}
+
+	contentType := "application/octet-stream"
+	if proof.PhotoContentType != nil {
+		contentType = *proof.PhotoContentType
+	}
+	return &domain.DeliveryPhoto{ContentType: contentType, Data: data}, nil
+}
+
+func (s *OrdersServiceImpl) Handoff(ctx context.Context, orderID string, userID string, options domain.OrderFilter) (*domain.Order, error) {
 	order, err := s.repo.GetOrderByID(ctx, orderID, options)
 	if err != nil {
 		return nil, err
 	}
 
+	// Drones may only hand off an order after reporting the failure,
+	// the other handoff transitions are made when a drone breaks
 	if order.Status != domain.OrderStatusFailed {
 		return nil, domain.ErrHandoffNotAllowed
 	}
 
-	status := domain.OrderStatusHandoff
-	order, err = s.repo.UpdateOrder(ctx, orderID, &domain.UpdateOrderRequest{
-		Status:      &status,
-		UpdatedByID: &droneID,
-	})
+	droneID := order.DroneID
+	order, err = s.repo.HandoffOrder(ctx, orderID, userID, "")
 	if err != nil {
 		return nil, err
 	}
 
+	s.invalidateOrderCache(ctx, order.ID, droneID)
+
 	// Publish event
-	if err := s.eventPublisher.PublishOrderUpdated(ctx, events.OrderUpdatedEvent{
-		OrderID: orderID,
-		UserID:  order.UserID,
-		Status:  order.Status,
-		DroneID: droneID,
-	}); err != nil {
-		s.logger.Error("Failed to publish order withdrawn event", "orderID", orderID, "error", err)
+	event := events.OrderUpdatedEvent{
+		OrderID:    orderID,
+		UserID:     order.UserID,
+		Status:     order.Status,
+		CurrentLat: order.CurrentLat,
+		CurrentLon: order.CurrentLon,
+	}
+	if droneID != nil {
+		event.DroneID = *droneID
+	}
+	if err := s.eventPublisher.PublishOrderUpdated(ctx, event); err != nil {
+		s.logger.Error("Failed to publish order handoff event", "orderID", orderID, "error", err)
 	}
 
 	return order, nil
 }
 
-func (s *OrdersServiceImpl) Reassign(ctx context.Context, orderID string, droneID string, options domain.OrderFilter) (*domain.Order, error) {
+// Reassign gives a handoff job to a rescue drone, which then flies to the pickup point
+// left by the previous drone and confirms the pickup there
+func (s *OrdersServiceImpl) Reassign(ctx context.Context, orderID string, userID string, options domain.OrderFilter) (*domain.Order, error) {
 	order, err := s.repo.GetOrderByID(ctx, orderID, options)
 	if err != nil {
 		return nil, err
 	}
 
-	if order.Status != domain.OrderStatusHandoff {
+	if !domain.OrderStatusReassigned.IsTransitionAllowed(order.Status) {
 		return nil, domain.ErrReassignNotAllowed
 	}
 
-	status := domain.OrderStatusReassigned
-	order, err = s.repo.UpdateOrder(ctx, orderID, &domain.UpdateOrderRequest{
-		Status:             &status,
-		UpdatedByID:        &droneID,
-		DeliveredByDroneID: &droneID,
+	drone, err := s.dronesService.GetDroneByFilter(ctx, domain.DroneFilter{
+		UserID: &userID,
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	if drone.Status != domain.DroneStatusIdle {
+		return nil, drone.Status.GetErr()
+	}
+
+	if err := drone.CheckCanPerform(order); err != nil {
+		return nil, err
+	}
+
+	reason := domain.OrderStatusReasonRescue
+	order, err = s.repo.UpdateOrderStatus(ctx, orderID, domain.UpdateStatusRequest{
+		DroneID:     drone.ID,
+		UpdatedByID: userID,
+		Status:      domain.OrderStatusReassigned,
+		Reason:      &reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	order = s.refreshEta(ctx, order)
+
+	s.invalidateOrderCache(ctx, order.ID, &drone.ID)
+
 	// Publish event
 	if err := s.eventPublisher.PublishOrderUpdated(ctx, events.OrderUpdatedEvent{
-		OrderID: orderID,
-		UserID:  order.UserID,
-		Status:  order.Status,
-		DroneID: droneID,
+		OrderID:            orderID,
+		UserID:             order.UserID,
+		Status:             order.Status,
+		DroneID:            drone.ID,
+		CurrentLat:         order.CurrentLat,
+		CurrentLon:         order.CurrentLon,
+		EstimatedArrivalAt: order.EstimatedArrivalAt,
 	}); err != nil {
-		s.logger.Error("Failed to publish order withdrawn event", "orderID", orderID, "error", err)
+		s.logger.Error("Failed to publish order reassigned event", "orderID", orderID, "error", err)
 	}
 
 	return order, nil
 }
 
-func (s *OrdersServiceImpl) DeliveryFailed(ctx context.Context, orderID string, userID string, options domain.OrderFilter) (*domain.Order, error) {
+// DeliveryFailed records a failed attempt and lets the failure policy re-queue the order,
+// send it back to the sender or hold it at the depot, in one step. An order left failed is
+// moved on again. The drone gets the matching command and the enduser is notified.
+func (s *OrdersServiceImpl) DeliveryFailed(ctx context.Context, orderID string, userID string, request *domain.DeliveryFailedRequest, options domain.OrderFilter) (*domain.Order, error) {
 	order, err := s.repo.GetOrderByID(ctx, orderID, options)
 	if err != nil {
 		return nil, err
 	}
 
-	if order.Status != domain.OrderStatusPickedUp && order.Status != domain.OrderStatusArrived && order.Status != domain.OrderStatusInTransit {
+	if order.Status != domain.OrderStatusFailed && !domain.OrderStatusFailed.IsTransitionAllowed(order.Status) {
 		return nil, domain.ErrDeliverFailedNotAllowed
 	}
 	drone, err := s.dronesService.GetDroneByFilter(ctx, domain.DroneFilter{
@@ -423,17 +901,238 @@ func (s *OrdersServiceImpl) DeliveryFailed(ctx context.Context, orderID string,
 	if err != nil {
 		return nil, err
 	}
-	status := domain.OrderStatusFailed
-	failedAt := time.Now().Format(time.RFC3339)
+
+	var reason *string
+	if request != nil && request.ReasonCode != "" {
+		code := string(request.ReasonCode)
+		reason = &code
+	}
+	// The policy decides on the order with this attempt counted
+	failed := *order
+	if failed.Status != domain.OrderStatusFailed {
+		failed.Status = domain.OrderStatusFailed
+		failed.DeliveryAttempts++
+	}
+	resolution := domain.DeliveryFailureResolution{
+		Action:          s.failurePolicy.Decide(&failed, request),
+		DroneID:         drone.ID,
+		UpdatedByID:     userID,
+		Reason:          reason,
+		ExpectedVersion: &order.Version,
+	}
+	if resolution.Action == domain.DeliveryFailureActionHoldAtDepot {
+		resolution.Depot = &domain.Depot{
+			Address: s.deliveryConfig.DepotAddress,
+			Lat:     s.deliveryConfig.DepotLat,
+			Lon:     s.deliveryConfig.DepotLon,
+		}
+	}
+	outcome, err := s.repo.ResolveDeliveryFailure(ctx, orderID, resolution)
+	if err != nil {
+		s.logger.Error("Failed to apply delivery failure policy", "orderID", orderID, "action", string(resolution.Action), "error", err)
+		return nil, err
+	}
+	order = outcome.Order
+
+	s.invalidateOrderCache(ctx, order.ID, &drone.ID)
+
+	// Publish events
+	if err := s.eventPublisher.PublishOrderUpdated(ctx, events.OrderUpdatedEvent{
+		OrderID:    orderID,
+		UserID:     order.UserID,
+		Status:     order.Status,
+		DroneID:    drone.ID,
+		CurrentLat: order.CurrentLat,
+		CurrentLon: order.CurrentLon,
+	}); err != nil {
+		s.logger.Error("Failed to publish order updated event", "orderID", orderID, "error", err)
+	}
+
+	event := events.OrderDeliveryFailedEvent{
+		OrderID:             order.ID,
+		OrderNumber:         order.OrderNumber,
+		UserID:              order.UserID,
+		DroneID:             drone.ID,
+		Status:              order.Status,
+		Action:              outcome.Action,
+		DeliveryAttempts:    order.DeliveryAttempts,
+		MaxDeliveryAttempts: order.MaxDeliveryAttempts,
+	}
+	if request != nil {
+		event.ReasonCode = request.ReasonCode
+	}
+	if resolution.Depot != nil {
+		event.DepotAddress = &resolution.Depot.Address
+	}
+	if outcome.Command != nil {
+		event.CommandID = &outcome.Command.ID
+	}
+	if err := s.eventPublisher.PublishOrderDeliveryFailed(ctx, event); err != nil {
+		s.logger.Error("Failed to publish order delivery failed event", "orderID", orderID, "error", err)
+	}
+
+	s.logger.Info("Delivery failed",
+		"orderID", order.ID,
+		"droneID", drone.ID,
+		"action", string(outcome.Action),
+		"attempts", order.DeliveryAttempts,
+		"maxAttempts", order.MaxDeliveryAttempts)
+
+	return order, nil
+}
+
+// ConfirmReturned closes an order the drone brought back to the sender, re-queues
+// an order the drone brought back to the origin for another attempt, or holds an
+// order the drone dropped off at the depot
+func (s *OrdersServiceImpl) ConfirmReturned(ctx context.Context, orderID string, userID string, options domain.OrderFilter) (*domain.Order, error) {
+	order, err := s.repo.GetOrderByID(ctx, orderID, options)
+	if err != nil {
+		return nil, err
+	}
+
+	status, ok := order.Status.ReturnConfirmedStatus()
+	if !ok {
+		return nil, domain.ErrReturnNotAllowed
+	}
+	drone, err := s.dronesService.GetDroneByFilter(ctx, domain.DroneFilter{
+		UserID: &userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	order, err = s.repo.UpdateOrderStatus(ctx, orderID, domain.UpdateStatusRequest{
 		DroneID:     drone.ID,
 		UpdatedByID: userID,
 		Status:      status,
-		FailAt:      &failedAt,
 	})
 	if err != nil {
 		return nil, err
 	}
+
+	s.invalidateOrderCache(ctx, order.ID, &drone.ID)
+
+	// Publish event
+	if err := s.eventPublisher.PublishOrderUpdated(ctx, events.OrderUpdatedEvent{
+		OrderID: orderID,
+		UserID:  order.UserID,
+		Status:  order.Status,
+		DroneID: drone.ID,
+	}); err != nil {
+		s.logger.Error("Failed to publish order returned event", "orderID", orderID, "error", err)
+	}
+
+	return order, nil
+}
+
+// RequeueHeldOrder puts an order held at the depot up for a drone, the depot becomes its pickup point
+func (s *OrdersServiceImpl) RequeueHeldOrder(ctx context.Context, orderID string, userID string) (*domain.Order, error) {
+	order, err := s.repo.GetOrderByID(ctx, orderID, domain.OrderFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status != domain.OrderStatusHeldAtDepot {
+		return nil, domain.ErrRequeueNotAllowed
+	}
+
+	order, err = s.repo.HandoffOrder(ctx, orderID, userID, string(domain.DeliveryFailureActionRequeue))
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateOrderCache(ctx, order.ID, nil)
+
+	// Publish event
+	if err := s.eventPublisher.PublishOrderUpdated(ctx, events.OrderUpdatedEvent{
+		OrderID:    orderID,
+		UserID:     order.UserID,
+		Status:     order.Status,
+		CurrentLat: order.CurrentLat,
+		CurrentLon: order.CurrentLon,
+	}); err != nil {
+		s.logger.Error("Failed to publish order requeued event", "orderID", orderID, "error", err)
+	}
+
+	return order, nil
+}
+
+// ChangeRoute moves the origin or destination of an order. The new route is repriced, the
+// assigned drone picks it up on its next heartbeat and the audit log keeps the old and new route.
+func (s *OrdersServiceImpl) ChangeRoute(ctx context.Context, orderID string, userID string, request *domain.ChangeRouteRequest) (*domain.Order, error) {
+	if !request.ChangesOrigin() && !request.ChangesDestination() {
+		return nil, domain.ErrRouteUnchanged
+	}
+
+	order, err := s.repo.GetOrderByID(ctx, orderID, domain.OrderFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status.IsFinal() {
+		return nil, domain.ErrRouteChangeNotAllowed
+	}
+	if request.ChangesOrigin() && !order.Status.IsBeforePickup() {
+		return nil, domain.ErrOriginChangeAfterPickup
+	}
+
+	route := order.Route().Apply(request)
+	if route.OriginLat == route.DestinationLat && route.OriginLon == route.DestinationLon {
+		return nil, domain.ErrSameOriginAndDestination
+	}
+	if err := s.checkRouteServiceArea(ctx, order, route); err != nil {
+		return nil, err
+	}
+	if request.ChangesOrigin() {
+		if err := s.checkNoFlyZone(ctx, route.OriginLat, route.OriginLon, order.ScheduledAt, domain.ErrOriginInNoFlyZone); err != nil {
+			return nil, err
+		}
+	}
+	if request.ChangesDestination() {
+		if err := s.checkNoFlyZone(ctx, route.DestinationLat, route.DestinationLon, order.ScheduledAt, domain.ErrDestinationInNoFlyZone); err != nil {
+			return nil, err
+		}
+	}
+
+	breakdown, err := s.pricingService.PriceRoute(ctx, order, route)
+	if err != nil {
+		s.logger.Error("Failed to price new route", "orderID", orderID, "error", err)
+		return nil, err
+	}
+	route.Price = &breakdown.Total
+
+	outcome, err := s.repo.ChangeOrderRoute(ctx, orderID, domain.RouteChange{
+		Route:              route,
+		ChangedByID:        userID,
+		UserType:           domain.UserTypeAdmin,
+		Reason:             request.Reason,
+		ExpectedVersion:    request.ExpectedVersion,
+		ChangesOrigin:      request.ChangesOrigin(),
+		ChangesDestination: request.ChangesDestination(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	order = s.refreshEta(ctx, outcome.Order)
+
+	s.invalidateOrderCache(ctx, order.ID, order.DroneID)
+
+	if outcome.Command != nil {
+		s.logger.Info("Queued new destination for drone", "orderID", orderID, "droneID", outcome.Command.DroneID, "commandID", outcome.Command.ID)
+	}
+
+	// Publish event
+	if err := s.eventPublisher.PublishOrderUpdated(ctx, events.OrderUpdatedEvent{
+		OrderID:    orderID,
+		UserID:     order.UserID,
+		Status:     order.Status,
+		CurrentLat: order.CurrentLat,
+		CurrentLon: order.CurrentLon,
+	}); err != nil {
+		s.logger.Error("Failed to publish order route changed event", "orderID", orderID, "error", err)
+	}
+
 	return order, nil
 }
 
@@ -443,6 +1142,15 @@ func (s *OrdersServiceImpl) UpadateOrderLocation(ctx context.Context, userID, or
 		return nil, err
 	}
 
+	area, err := s.serviceAreaService.FindServiceArea(ctx, domain.GeoPoint{Lat: currentLat, Lon: currentLon})
+	if err != nil {
+		s.logger.Error("Failed to check service areas", "orderID", orderID, "error", err)
+		return nil, err
+	}
+	if area == nil {
+		return nil, domain.ErrLocationOutsideServiceArea
+	}
+
 	order, err := s.repo.UpdateOrder(ctx, orderID, &domain.UpdateOrderRequest{
 		UpdatedByID:     &userID,
 		CurrentLat:      &currentLat,
@@ -453,6 +1161,8 @@ func (s *OrdersServiceImpl) UpadateOrderLocation(ctx context.Context, userID, or
 		return nil, err
 	}
 
+	order = s.refreshEta(ctx, order)
+
 	// Publish event
 	if err := s.eventPublisher.PublishOrderUpdated(ctx, events.OrderUpdatedEvent{
 		OrderID:         orderID,
@@ -471,3 +1181,201 @@ func (s *OrdersServiceImpl) UpadateOrderLocation(ctx context.Context, userID, or
 func (s *OrdersServiceImpl) GetOrderByFilter(ctx context.Context, options domain.OrderFilter) (*domain.Order, error) {
 	return s.repo.GetOrderByFilter(ctx, options)
 }
+
+func (s *OrdersServiceImpl) ListAvailableJobs(ctx context.Context, userID string, radiusKm float64, limit int) ([]*domain.AvailableJob, error) {
+	drone, err := s.dronesService.GetDroneByFilter(ctx, domain.DroneFilter{
+		UserID: &userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if drone.CurrentLat == nil || drone.CurrentLon == nil {
+		return nil, domain.ErrDroneLocationUnknown
+	}
+
+	jobs, err := s.repo.ListAvailableOrders(ctx, domain.AvailableJobsQuery{
+		Lat:          *drone.CurrentLat,
+		Lon:          *drone.CurrentLon,
+		RadiusKm:     radiusKm,
+		MaxWeightKg:  drone.MaxWeightKg,
+		MaxRangeKm:   drone.AvailableRangeKm(),
+		Limit:        limit,
+		Capabilities: drone.Capabilities,
+	})
+	if err != nil {
+		s.logger.Error("Failed to list available jobs", "droneID", drone.ID, "error", err)
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+func (s *OrdersServiceImpl) ClaimNextOrder(ctx context.Context, userID string, radiusKm float64) (*domain.Order, error) {
+	drone, err := s.dronesService.GetDroneByFilter(ctx, domain.DroneFilter{
+		UserID: &userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if drone.CurrentLat == nil || drone.CurrentLon == nil {
+		return nil, domain.ErrDroneLocationUnknown
+	}
+
+	order, err := s.repo.ClaimNextOrder(ctx, drone.ID, userID, domain.AvailableJobsQuery{
+		Lat:          *drone.CurrentLat,
+		Lon:          *drone.CurrentLon,
+		RadiusKm:     radiusKm,
+		MaxWeightKg:  drone.MaxWeightKg,
+		MaxRangeKm:   drone.AvailableRangeKm(),
+		Capabilities: drone.Capabilities,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	order = s.refreshEta(ctx, order)
+
+	// Drone moved to loading inside the claim, drop stale cache entries
+	if err := s.cacheService.Delete(ctx, "drones:"+drone.ID); err != nil {
+		s.logger.Error("Failed to invalidate drone cache", "droneID", drone.ID, "error", err)
+	}
+	if err := s.cacheService.Delete(ctx, fmt.Sprintf("orders:%s:", order.ID)); err != nil {
+		s.logger.Error("Failed to invalidate order cache", "orderID", order.ID, "error", err)
+	}
+
+	// Publish event
+	if err := s.eventPublisher.PublishOrderUpdated(ctx, events.OrderUpdatedEvent{
+		OrderID: order.ID,
+		UserID:  order.UserID,
+		Status:  order.Status,
+		DroneID: drone.ID,
+	}); err != nil {
+		s.logger.Error("Failed to publish order claimed event", "orderID", order.ID, "error", err)
+	}
+
+	return order, nil
+}
+
+func (s *OrdersServiceImpl) GetOrderTimeline(ctx context.Context, orderID string, options domain.OrderFilter) (*domain.OrderTimeline, error) {
+	// Ownership is checked through the order filter
+	order, err := s.repo.GetOrderByID(ctx, orderID, options)
+	if err != nil {
+		return nil, err
+	}
+
+	steps, err := s.repo.ListOrderStatusHistory(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if steps == nil {
+		steps = []*domain.OrderStatusHistory{}
+	}
+
+	carriers, err := s.repo.ListOrderCarriers(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if carriers == nil {
+		carriers = []*domain.OrderCarrier{}
+	}
+
+	return &domain.OrderTimeline{
+		OrderID:     order.ID,
+		OrderNumber: order.OrderNumber,
+		Status:      order.Status,
+		Steps:       steps,
+		Carriers:    carriers,
+	}, nil
+}
+
+// GetOrderTrack returns the flight path of an order, built from the heartbeats of the drones that
+// carried it. Heartbeats older than the telemetry retention are no longer part of it.
+func (s *OrdersServiceImpl) GetOrderTrack(ctx context.Context, orderID string, options domain.OrderFilter) (*domain.OrderTrack, error) {
+	// Ownership is checked through the order filter
+	order, err := s.repo.GetOrderByID(ctx, orderID, options)
+	if err != nil {
+		return nil, err
+	}
+
+	points, err := s.telemetryRepo.ListOrderTelemetry(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	steps, err := s.repo.ListOrderStatusHistory(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	carriers, err := s.repo.ListOrderCarriers(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewOrderTrack(order, points, steps, carriers), nil
+}
+
+// CancelOrder lets an admin cancel an order from any open status, releasing its drone
+func (s *OrdersServiceImpl) CancelOrder(ctx context.Context, orderID string, userID string, request *domain.CancelOrderRequest) (*domain.Order, error) {
+	cancellation, err := s.repo.CancelOrder(ctx, orderID, userID, request)
+	if err != nil {
+		s.logger.Error("Failed to cancel order", "orderID", orderID, "error", err)
+		return nil, err
+	}
+	order := cancellation.Order
+
+	s.invalidateOrderCache(ctx, order.ID, cancellation.DroneID)
+
+	event := events.OrderCancelledEvent{
+		OrderID:        order.ID,
+		UserID:         order.UserID,
+		PreviousStatus: cancellation.PreviousStatus,
+		Status:         order.Status,
+		ReasonCode:     request.ReasonCode,
+		Note:           request.Note,
+		CancelledByID:  userID,
+		CancelledAt:    order.CancelledAt,
+		DroneID:        cancellation.DroneID,
+	}
+	if cancellation.Command != nil {
+		event.ReturnToOrigin = true
+		event.CommandID = &cancellation.Command.ID
+	}
+
+	// Publish event
+	if err := s.eventPublisher.PublishOrderCancelled(ctx, event); err != nil {
+		s.logger.Error("Failed to publish order cancelled event", "orderID", order.ID, "error", err)
+	}
+
+	s.logger.Info("Order cancelled",
+		"orderID", order.ID,
+		"previousStatus", string(cancellation.PreviousStatus),
+		"reason", string(request.ReasonCode),
+		"returnToOrigin", event.ReturnToOrigin)
+
+	return order, nil
+}
+
+// invalidateOrderCache drops the cached order and, when given, the cached drone
+func (s *OrdersServiceImpl) invalidateOrderCache(ctx context.Context, orderID string, droneID *string) {
+	if err := s.cacheService.Delete(ctx, fmt.Sprintf("orders:%s:", orderID)); err != nil {
+		s.logger.Error("Failed to invalidate order cache", "orderID", orderID, "error", err)
+	}
+	if droneID != nil {
+		if err := s.cacheService.Delete(ctx, "drones:"+*droneID); err != nil {
+			s.logger.Error("Failed to invalidate drone cache", "droneID", *droneID, "error", err)
+		}
+	}
+}
+
+// refreshEta recalculates the ETA after a transition, failures keep the order as it is
+func (s *OrdersServiceImpl) refreshEta(ctx context.Context, order *domain.Order) *domain.Order {
+	updated, err := s.etaService.RefreshOrder(ctx, order)
+	if err != nil {
+		s.logger.Error("Failed to refresh order ETA", "orderID", order.ID, "error", err)
+		return order
+	}
+	return updated
+}
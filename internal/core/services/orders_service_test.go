@@ -0,0 +1,426 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"drones/internal/core/domain"
+	"drones/pkg/utils"
+)
+
+// go test ./internal/core/services/ -v
+
+func testDrone() *domain.Drone {
+	return &domain.Drone{BaseModel: domain.BaseModel{ID: "drone-1"}, UserID: "drone-user-1"}
+}
+
+func TestDeliveryFailureTransitions(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    domain.OrderStatus
+		to      domain.OrderStatus
+		allowed bool
+	}{
+		{"requeue returns to the origin first", domain.OrderStatusFailed, domain.OrderStatusReturningToOrigin, true},
+		{"requeue cannot skip the return", domain.OrderStatusFailed, domain.OrderStatusPending, false},
+		{"confirmed return re-queues", domain.OrderStatusReturningToOrigin, domain.OrderStatusPending, true},
+		{"broken drone hands off", domain.OrderStatusReturningToOrigin, domain.OrderStatusHandoff, true},
+		{"admin cancels", domain.OrderStatusReturningToOrigin, domain.OrderStatusCancelled, true},
+		{"not returned to sender", domain.OrderStatusReturningToOrigin, domain.OrderStatusReturned, false},
+		{"hold delivers to the depot first", domain.OrderStatusFailed, domain.OrderStatusDeliveringToDepot, true},
+		{"hold cannot skip the drop-off", domain.OrderStatusFailed, domain.OrderStatusHeldAtDepot, false},
+		{"confirmed drop-off holds", domain.OrderStatusDeliveringToDepot, domain.OrderStatusHeldAtDepot, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.to.IsTransitionAllowed(tt.from); got != tt.allowed {
+				t.Errorf("%s -> %s allowed = %v, want %v", tt.from, tt.to, got, tt.allowed)
+			}
+		})
+	}
+
+	if !domain.OrderStatusReturningToOrigin.CarriesPackage() {
+		t.Error("Expected the drone returning to the origin to carry the package")
+	}
+	if !domain.OrderStatusDeliveringToDepot.CarriesPackage() {
+		t.Error("Expected the drone delivering to the depot to carry the package")
+	}
+}
+
+func TestDeliveryFailedRequeueHoldsOrderUntilReturn(t *testing.T) {
+	drone := testDrone()
+	repo := newFakeOrdersRepo(&domain.Order{
+		BaseModel:           domain.BaseModel{ID: "order-1"},
+		UserID:              "user-1",
+		Status:              domain.OrderStatusArrived,
+		DroneID:             &drone.ID,
+		OriginLat:           24.7,
+		OriginLon:           46.6,
+		MaxDeliveryAttempts: 3,
+	})
+	cache := newFakeCache()
+	publisher := &fakePublisher{}
+	service := newTestOrdersService(repo, cache, publisher, drone)
+	ctx := context.Background()
+
+	order, err := service.DeliveryFailed(ctx, "order-1", drone.UserID, &domain.DeliveryFailedRequest{
+		ReasonCode: domain.DeliveryFailureReasonReceiverUnavailable,
+	}, domain.OrderFilter{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if order.Status != domain.OrderStatusReturningToOrigin {
+		t.Fatalf("Expected returning_to_origin, got %s", order.Status)
+	}
+	if order.DroneID == nil || *order.DroneID != drone.ID {
+		t.Errorf("Expected the drone to keep the order on its way back, got %v", order.DroneID)
+	}
+	if len(publisher.failed) != 1 || publisher.failed[0].Action != domain.DeliveryFailureActionRequeue {
+		t.Fatalf("Expected one requeue delivery failed event, got %+v", publisher.failed)
+	}
+	if publisher.failed[0].CommandID == nil {
+		t.Error("Expected the drone to get a return command")
+	}
+
+	// The order is not up for another drone before the package is back
+	if _, err := service.Reserve(ctx, "order-1", "drone-user-2", domain.OrderFilter{}); err == nil {
+		t.Error("Expected reserving an order on its way back to fail")
+	}
+
+	order, err = service.ConfirmReturned(ctx, "order-1", drone.UserID, domain.OrderFilter{DroneID: &drone.ID})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if order.Status != domain.OrderStatusPending {
+		t.Fatalf("Expected pending after the return, got %s", order.Status)
+	}
+	if order.DroneID != nil {
+		t.Errorf("Expected the drone to be released, got %s", *order.DroneID)
+	}
+	if order.CurrentLat == nil || *order.CurrentLat != 24.7 || order.CurrentLon == nil || *order.CurrentLon != 46.6 {
+		t.Errorf("Expected the order to wait at the origin, got %v, %v", order.CurrentLat, order.CurrentLon)
+	}
+	for _, key := range []string{"orders:order-1:", "drones:" + drone.ID} {
+		if !cache.wasDeleted(key) {
+			t.Errorf("Expected cache key %s to be invalidated", key)
+		}
+	}
+}
+
+func TestDeliveryFailedResolvesInOneStep(t *testing.T) {
+	tests := []struct {
+		name         string
+		from         domain.OrderStatus
+		attempts     int
+		want         domain.OrderStatus
+		wantAttempts int
+	}{
+		{"first failure re-queues", domain.OrderStatusArrived, 0, domain.OrderStatusReturningToOrigin, 1},
+		{"last attempt returns to sender", domain.OrderStatusArrived, 2, domain.OrderStatusReturningToSender, 3},
+		{"order left failed is moved on", domain.OrderStatusFailed, 1, domain.OrderStatusReturningToOrigin, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drone := testDrone()
+			repo := newFakeOrdersRepo(&domain.Order{
+				BaseModel:           domain.BaseModel{ID: "order-1"},
+				Status:              tt.from,
+				DroneID:             &drone.ID,
+				DeliveryAttempts:    tt.attempts,
+				MaxDeliveryAttempts: 3,
+			})
+			service := newTestOrdersService(repo, newFakeCache(), &fakePublisher{}, drone)
+
+			order, err := service.DeliveryFailed(context.Background(), "order-1", drone.UserID, nil, domain.OrderFilter{})
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if order.Status != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, order.Status)
+			}
+			if order.DeliveryAttempts != tt.wantAttempts {
+				t.Errorf("Expected %d delivery attempts, got %d", tt.wantAttempts, order.DeliveryAttempts)
+			}
+		})
+	}
+}
+
+func TestDeliveryFailedHoldKeepsDroneUntilDropOff(t *testing.T) {
+	drone := testDrone()
+	repo := newFakeOrdersRepo(&domain.Order{
+		BaseModel:           domain.BaseModel{ID: "order-1"},
+		UserID:              "user-1",
+		Status:              domain.OrderStatusArrived,
+		DroneID:             &drone.ID,
+		DeliveryAttempts:    2,
+		MaxDeliveryAttempts: 3,
+	})
+	publisher := &fakePublisher{}
+	service := newTestOrdersService(repo, newFakeCache(), publisher, drone)
+	service.failurePolicy = &AttemptsFailurePolicy{ExhaustedAction: domain.DeliveryFailureActionHoldAtDepot, HasDepot: true}
+	service.deliveryConfig.DepotAddress = "Depot"
+	service.deliveryConfig.DepotLat, service.deliveryConfig.DepotLon = 24.8, 46.7
+	ctx := context.Background()
+
+	order, err := service.DeliveryFailed(ctx, "order-1", drone.UserID, nil, domain.OrderFilter{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if order.Status != domain.OrderStatusDeliveringToDepot {
+		t.Fatalf("Expected delivering_to_depot, got %s", order.Status)
+	}
+	// The package is still on board, the drone keeps the order
+	if order.DroneID == nil || *order.DroneID != drone.ID {
+		t.Errorf("Expected the drone to keep the order on its way to the depot, got %v", order.DroneID)
+	}
+	if len(publisher.failed) != 1 || publisher.failed[0].DepotAddress == nil || *publisher.failed[0].DepotAddress != "Depot" {
+		t.Fatalf("Expected one delivery failed event with the depot, got %+v", publisher.failed)
+	}
+
+	// Not up for another drone before the package is at the depot
+	if _, err := service.RequeueHeldOrder(ctx, "order-1", "admin-1"); err == nil {
+		t.Error("Expected re-queueing an order on its way to the depot to fail")
+	}
+
+	order, err = service.ConfirmReturned(ctx, "order-1", drone.UserID, domain.OrderFilter{DroneID: &drone.ID})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if order.Status != domain.OrderStatusHeldAtDepot {
+		t.Fatalf("Expected held_at_depot after the drop-off, got %s", order.Status)
+	}
+	if order.DroneID != nil {
+		t.Errorf("Expected the drone to be released, got %s", *order.DroneID)
+	}
+}
+
+func TestConfirmReturned(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    domain.OrderStatus
+		want    domain.OrderStatus
+		wantErr error
+	}{
+		{"returning to sender", domain.OrderStatusReturningToSender, domain.OrderStatusReturned, nil},
+		{"returning to origin", domain.OrderStatusReturningToOrigin, domain.OrderStatusPending, nil},
+		{"delivering to depot", domain.OrderStatusDeliveringToDepot, domain.OrderStatusHeldAtDepot, nil},
+		{"still failed", domain.OrderStatusFailed, domain.OrderStatusFailed, domain.ErrReturnNotAllowed},
+		{"in transit", domain.OrderStatusInTransit, domain.OrderStatusInTransit, domain.ErrReturnNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drone := testDrone()
+			repo := newFakeOrdersRepo(&domain.Order{
+				BaseModel: domain.BaseModel{ID: "order-1"},
+				Status:    tt.from,
+				DroneID:   utils.StringPtr(drone.ID),
+			})
+			service := newTestOrdersService(repo, newFakeCache(), &fakePublisher{}, drone)
+
+			_, err := service.ConfirmReturned(context.Background(), "order-1", drone.UserID, domain.OrderFilter{})
+			if err != tt.wantErr {
+				t.Fatalf("Expected error %v, got: %v", tt.wantErr, err)
+			}
+			if got := repo.order("order-1").Status; got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUpdateOrderCannotRequeueBeforeReturn(t *testing.T) {
+	repo := newFakeOrdersRepo(&domain.Order{
+		BaseModel: domain.BaseModel{ID: "order-1"},
+		Status:    domain.OrderStatusReturningToOrigin,
+		DroneID:   utils.StringPtr("drone-1"),
+	})
+	service := newTestOrdersService(repo, newFakeCache(), &fakePublisher{}, nil)
+
+	status := domain.OrderStatusPending
+	_, err := service.UpdateOrder(context.Background(), "order-1", &domain.UpdateOrderRequest{Status: &status}, domain.OrderFilter{})
+	if err != domain.ErrReleaseThroughConfirmReturn {
+		t.Fatalf("Expected ErrReleaseThroughConfirmReturn, got: %v", err)
+	}
+	if order := repo.order("order-1"); order.Status != domain.OrderStatusReturningToOrigin {
+		t.Errorf("Expected the order to stay returning_to_origin, got %s", order.Status)
+	}
+}
+
+// deliveryTestOrder is an arrived order with a delivery proof for code, no code for a legacy order
+func deliveryTestOrder(drone *domain.Drone, code string) *fakeOrdersRepo {
+	repo := newFakeOrdersRepo(&domain.Order{
+		BaseModel:      domain.BaseModel{ID: "order-1"},
+		Status:         domain.OrderStatusArrived,
+		DroneID:        &drone.ID,
+		DestinationLat: 24.72,
+		DestinationLon: 46.67,
+	})
+	proof := &domain.DeliveryProof{OrderID: "order-1"}
+	if code != "" {
+		salt := "salt"
+		hash := utils.HashCode(code, salt)
+		proof.CodeHash, proof.CodeSalt = &hash, &salt
+	}
+	repo.proofs["order-1"] = proof
+	return repo
+}
+
+func confirmDeliveryRequest(code string) *domain.ConfirmDeliveryRequest {
+	return &domain.ConfirmDeliveryRequest{DeliveryCode: code, Lat: 24.72, Lon: 46.67}
+}
+
+func TestConfirmDeliveryCode(t *testing.T) {
+	tests := []struct {
+		name         string
+		code         string
+		attempts     int
+		noProof      bool
+		submitted    string
+		wantErr      error
+		wantStatus   domain.OrderStatus
+		wantAttempts int
+	}{
+		{"right code", "482913", 0, false, "482913", nil, domain.OrderStatusDelivered, 0},
+		{"wrong code", "482913", 0, false, "000000", domain.ErrInvalidDeliveryCode, domain.OrderStatusArrived, 1},
+		{"last wrong code locks", "482913", 2, false, "000000", domain.ErrDeliveryCodeLocked, domain.OrderStatusArrived, 3},
+		{"right code after lock", "482913", 3, false, "482913", domain.ErrDeliveryCodeLocked, domain.OrderStatusArrived, 3},
+		{"legacy order without code", "", 0, false, "", nil, domain.OrderStatusDelivered, 0},
+		{"missing proof fails closed", "", 0, true, "", domain.ErrDeliveryProofNotFound, domain.OrderStatusArrived, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drone := testDrone()
+			repo := deliveryTestOrder(drone, tt.code)
+			repo.proofs["order-1"].FailedAttempts = tt.attempts
+			if tt.noProof {
+				delete(repo.proofs, "order-1")
+			}
+			service := newTestOrdersService(repo, newFakeCache(), &fakePublisher{}, drone)
+
+			_, err := service.ConfirmDelivery(context.Background(), "order-1", drone.UserID, confirmDeliveryRequest(tt.submitted), domain.OrderFilter{})
+			if err != tt.wantErr {
+				t.Fatalf("Expected error %v, got: %v", tt.wantErr, err)
+			}
+			if got := repo.order("order-1").Status; got != tt.wantStatus {
+				t.Errorf("Expected %s, got %s", tt.wantStatus, got)
+			}
+			if proof, ok := repo.proofs["order-1"]; ok && proof.FailedAttempts != tt.wantAttempts {
+				t.Errorf("Expected %d failed attempts, got %d", tt.wantAttempts, proof.FailedAttempts)
+			}
+		})
+	}
+}
+
+func TestConfirmDeliveryConcurrentGuessesStopAtLimit(t *testing.T) {
+	drone := testDrone()
+	repo := deliveryTestOrder(drone, "482913")
+	service := newTestOrdersService(repo, newFakeCache(), &fakePublisher{}, drone)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(guess int) {
+			defer wg.Done()
+			service.ConfirmDelivery(context.Background(), "order-1", drone.UserID, confirmDeliveryRequest(fmt.Sprintf("%06d", guess)), domain.OrderFilter{})
+		}(i)
+	}
+	wg.Wait()
+
+	if attempts := repo.proofs["order-1"].FailedAttempts; attempts != 3 {
+		t.Errorf("Expected the failures to stop at 3, got %d", attempts)
+	}
+	_, err := service.ConfirmDelivery(context.Background(), "order-1", drone.UserID, confirmDeliveryRequest("482913"), domain.OrderFilter{})
+	if err != domain.ErrDeliveryCodeLocked {
+		t.Errorf("Expected ErrDeliveryCodeLocked for the right code once locked, got: %v", err)
+	}
+}
+
+func TestUpdateOrderCannotDeliver(t *testing.T) {
+	drone := testDrone()
+	repo := deliveryTestOrder(drone, "482913")
+	service := newTestOrdersService(repo, newFakeCache(), &fakePublisher{}, nil)
+
+	status := domain.OrderStatusDelivered
+	_, err := service.UpdateOrder(context.Background(), "order-1", &domain.UpdateOrderRequest{Status: &status}, domain.OrderFilter{})
+	if err != domain.ErrDeliverThroughConfirmDelivery {
+		t.Fatalf("Expected ErrDeliverThroughConfirmDelivery, got: %v", err)
+	}
+	if order := repo.order("order-1"); order.Status != domain.OrderStatusArrived {
+		t.Errorf("Expected the order to stay arrived, got %s", order.Status)
+	}
+}
+
+func TestOrderTransitionsInvalidateCachedOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		from       domain.OrderStatus
+		transition func(service *OrdersServiceImpl, ctx context.Context, userID string) (*domain.Order, error)
+	}{
+		{"reserve", domain.OrderStatusPending, func(service *OrdersServiceImpl, ctx context.Context, userID string) (*domain.Order, error) {
+			return service.Reserve(ctx, "order-1", userID, domain.OrderFilter{})
+		}},
+		{"confirm pickup", domain.OrderStatusReserved, func(service *OrdersServiceImpl, ctx context.Context, userID string) (*domain.Order, error) {
+			return service.ConfirmPickup(ctx, "order-1", userID, domain.OrderFilter{})
+		}},
+		{"start transit", domain.OrderStatusPickedUp, func(service *OrdersServiceImpl, ctx context.Context, userID string) (*domain.Order, error) {
+			return service.StartTransit(ctx, "order-1", userID, domain.OrderFilter{})
+		}},
+		{"confirm arrived", domain.OrderStatusInTransit, func(service *OrdersServiceImpl, ctx context.Context, userID string) (*domain.Order, error) {
+			return service.ConfirmArrived(ctx, "order-1", userID, domain.OrderFilter{})
+		}},
+		{"confirm delivery", domain.OrderStatusArrived, func(service *OrdersServiceImpl, ctx context.Context, userID string) (*domain.Order, error) {
+			return service.ConfirmDelivery(ctx, "order-1", userID, confirmDeliveryRequest(""), domain.OrderFilter{})
+		}},
+		{"withdraw", domain.OrderStatusPending, func(service *OrdersServiceImpl, ctx context.Context, userID string) (*domain.Order, error) {
+			return service.Withdraw(ctx, "order-1", userID, domain.OrderFilter{})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drone := testDrone()
+			drone.Status = domain.DroneStatusIdle
+			drone.MaxRangeKm = 100
+			order := &domain.Order{
+				BaseModel:      domain.BaseModel{ID: "order-1"},
+				Status:         tt.from,
+				OriginLat:      24.7,
+				OriginLon:      46.6,
+				DestinationLat: 24.72,
+				DestinationLon: 46.67,
+				Version:        1,
+			}
+			if tt.from != domain.OrderStatusPending {
+				order.DroneID = &drone.ID
+			}
+			repo := newFakeOrdersRepo(order)
+			// Legacy proof, the delivery needs no code
+			repo.proofs["order-1"] = &domain.DeliveryProof{OrderID: "order-1"}
+			cache := newFakeCache()
+			ctx := context.Background()
+			// Orders are cached when they are created
+			cache.Set(ctx, "orders:order-1:", order, 0)
+			service := newTestOrdersService(repo, cache, &fakePublisher{}, drone)
+
+			updated, err := tt.transition(service, ctx, drone.UserID)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			read, err := service.GetOrderByID(ctx, "order-1", domain.OrderFilter{})
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if read.Version != updated.Version || read.Status != updated.Status {
+				t.Errorf("Expected version %d in %s after the transition, read version %d in %s",
+					updated.Version, updated.Status, read.Version, read.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"drones/internal/ports"
+)
+
+// PeriodicWorker runs a job on a fixed interval until stopped
+type PeriodicWorker struct {
+	name     string
+	interval time.Duration
+	job      func(ctx context.Context) error
+	logger   ports.Logger
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func NewPeriodicWorker(
+	name string,
+	interval time.Duration,
+	job func(ctx context.Context) error,
+	logger ports.Logger,
+) ports.Worker {
+	return &PeriodicWorker{name: name, interval: interval, job: job, logger: logger}
+}
+
+func (w *PeriodicWorker) Start(ctx context.Context) error {
+	ctx, w.cancel = context.WithCancel(ctx)
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		w.logger.Info("Worker started", "worker", w.name, "interval", w.interval.String())
+		for {
+			select {
+			case <-ctx.Done():
+				w.logger.Info("Worker stopped", "worker", w.name)
+				return
+			case <-ticker.C:
+				if err := w.job(ctx); err != nil {
+					w.logger.Error("Worker run failed", "worker", w.name, "error", err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (w *PeriodicWorker) Stop() error {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.wg.Wait()
+	return nil
+}
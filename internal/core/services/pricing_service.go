@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	config "drones/configs"
+	"drones/internal/core/domain"
+	"drones/internal/ports"
+	"drones/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+// pricingRulesCacheKey caches the active rules, every rule change invalidates it
+const pricingRulesCacheKey = "pricing:rules"
+
+type PricingServiceImpl struct {
+	repo         ports.PricingRepository
+	cacheService ports.CacheService
+	config       config.PricingConfig
+	location     *time.Location
+	logger       ports.Logger
+}
+
+func NewPricingService(
+	repo ports.PricingRepository,
+	cacheService ports.CacheService,
+	config config.PricingConfig,
+	logger ports.Logger,
+) ports.PricingService {
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		logger.Warn("Unknown pricing time zone, using UTC", "timezone", config.Timezone, "error", err)
+		location = time.UTC
+	}
+
+	return &PricingServiceImpl{
+		repo:         repo,
+		cacheService: cacheService,
+		config:       config,
+		location:     location,
+		logger:       logger,
+	}
+}
+
+// Quote prices a delivery and signs the result so it can be redeemed until it expires
+func (s *PricingServiceImpl) Quote(ctx context.Context, userID string, request *domain.QuoteRequest) (*domain.Quote, error) {
+	priority := request.Priority.OrDefault()
+
+	breakdown, err := s.price(ctx, priority, request.OriginLat, request.OriginLon,
+		request.DestinationLat, request.DestinationLon, request.PackageWeightKg, request.ScheduledAt)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.config.QuoteTTL).UTC().Truncate(time.Second)
+	claims := domain.QuoteClaims{
+		QuoteID:         uuid.NewString(),
+		UserID:          userID,
+		OriginLat:       request.OriginLat,
+		OriginLon:       request.OriginLon,
+		DestinationLat:  request.DestinationLat,
+		DestinationLon:  request.DestinationLon,
+		PackageWeightKg: request.PackageWeightKg,
+		ScheduledAt:     request.ScheduledAt,
+		Priority:        priority,
+		Price:           breakdown.Total,
+		Currency:        s.config.Currency,
+		ExpiresAt:       expiresAt.Unix(),
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Quote{
+		ID:        utils.SignPayload(payload, s.config.QuoteSecret),
+		Price:     breakdown.Total,
+		Currency:  s.config.Currency,
+		Priority:  priority,
+		Breakdown: *breakdown,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// PriceOrder sets the price of a new order, from its quote when it has one
+func (s *PricingServiceImpl) PriceOrder(ctx context.Context, userID string, order *domain.CreateOrderRequest) error {
+	if order.QuoteID != nil {
+		claims, err := s.verifyQuote(userID, *order.QuoteID)
+		if err != nil {
+			return err
+		}
+		if !claims.Matches(order) {
+			return domain.ErrQuoteMismatch
+		}
+
+		order.Price = &claims.Price
+		order.Currency = &claims.Currency
+		order.QuoteReference = &claims.QuoteID
+		return nil
+	}
+
+	breakdown, err := s.price(ctx, order.Priority.OrDefault(), order.OriginLat, order.OriginLon,
+		order.DestinationLat, order.DestinationLon, order.PackageWeightKg, order.ScheduledAt)
+	if err != nil {
+		return err
+	}
+
+	currency := s.config.Currency
+	order.Price = &breakdown.Total
+	order.Currency = &currency
+	return nil
+}
+
+// PriceRoute prices an existing order on a new route with the current rules, a quoted
+// price only held for the route it was quoted on
+func (s *PricingServiceImpl) PriceRoute(ctx context.Context, order *domain.Order, route domain.OrderRoute) (*domain.PriceBreakdown, error) {
+	return s.price(ctx, order.Priority.OrDefault(), route.OriginLat, route.OriginLon,
+		route.DestinationLat, route.DestinationLon, order.PackageWeightKg, order.ScheduledAt)
+}
+
+// verifyQuote checks the signature, expiry and owner of a quote ID
+func (s *PricingServiceImpl) verifyQuote(userID string, quoteID string) (*domain.QuoteClaims, error) {
+	payload, err := utils.VerifySignedPayload(quoteID, s.config.QuoteSecret)
+	if err != nil {
+		return nil, domain.ErrInvalidQuote
+	}
+
+	var claims domain.QuoteClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, domain.ErrInvalidQuote
+	}
+	if claims.UserID != userID {
+		return nil, domain.ErrInvalidQuote
+	}
+	if time.Now().Unix() > claims.ExpiresAt {
+		return nil, domain.ErrQuoteExpired
+	}
+
+	return &claims, nil
+}
+
+// price computes a price at the time the order is flown, now unless it is scheduled
+func (s *PricingServiceImpl) price(ctx context.Context, priority domain.OrderPriority, originLat, originLon, destinationLat, destinationLon float64, weightKg *float64, scheduledAt *string) (*domain.PriceBreakdown, error) {
+	at := time.Now()
+	if scheduledAt != nil {
+		parsed, err := time.Parse(time.RFC3339, *scheduledAt)
+		if err != nil {
+			return nil, domain.NewDomainError(domain.InvalidInputError, "Invalid scheduled_at format", err)
+		}
+		at = parsed
+	}
+
+	rules, err := s.ListRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	distanceKm := utils.HaversineKm(originLat, originLon, destinationLat, destinationLon)
+	return domain.CalculatePrice(rules, priority, distanceKm, weightKg, at.In(s.location))
+}
+
+// ListRules returns the active pricing rules
+func (s *PricingServiceImpl) ListRules(ctx context.Context) ([]*domain.PricingRule, error) {
+	var rules []*domain.PricingRule
+	if err := s.cacheService.Get(ctx, pricingRulesCacheKey, &rules); err == nil && rules != nil {
+		return rules, nil
+	}
+
+	rules, err := s.repo.ListPricingRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		rules = []*domain.PricingRule{}
+	}
+
+	if err := s.cacheService.Set(ctx, pricingRulesCacheKey, rules, 0); err != nil {
+		s.logger.Error("Failed to cache pricing rules", "key", pricingRulesCacheKey, "error", err)
+	}
+
+	return rules, nil
+}
+
+func (s *PricingServiceImpl) CreateRule(ctx context.Context, userID string, request *domain.PricingRuleRequest) (*domain.PricingRule, error) {
+	rule, err := s.repo.CreatePricingRule(ctx, userID, request)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateRules(ctx)
+	return rule, nil
+}
+
+func (s *PricingServiceImpl) UpdateRule(ctx context.Context, ruleID string, userID string, request *domain.PricingRuleRequest) (*domain.PricingRule, error) {
+	rule, err := s.repo.UpdatePricingRule(ctx, ruleID, userID, request)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateRules(ctx)
+	return rule, nil
+}
+
+func (s *PricingServiceImpl) DeleteRule(ctx context.Context, ruleID string, userID string) error {
+	if err := s.repo.DeletePricingRule(ctx, ruleID, userID); err != nil {
+		return err
+	}
+
+	s.invalidateRules(ctx)
+	return nil
+}
+
+func (s *PricingServiceImpl) invalidateRules(ctx context.Context) {
+	if err := s.cacheService.Delete(ctx, pricingRulesCacheKey); err != nil {
+		s.logger.Error("Failed to invalidate pricing rules cache", "key", pricingRulesCacheKey, "error", err)
+	}
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	config "drones/configs"
+	"drones/internal/core/events"
+	"drones/internal/ports"
+)
+
+type ReservationServiceImpl struct {
+	ordersRepo     ports.OrdersRepository
+	cacheService   ports.CacheService
+	eventPublisher ports.EventPublisher
+	config         config.ReservationConfig
+	logger         ports.Logger
+}
+
+func NewReservationService(
+	ordersRepo ports.OrdersRepository,
+	cacheService ports.CacheService,
+	eventPublisher ports.EventPublisher,
+	config config.ReservationConfig,
+	logger ports.Logger,
+) ports.ReservationService {
+	return &ReservationServiceImpl{
+		ordersRepo:     ordersRepo,
+		cacheService:   cacheService,
+		eventPublisher: eventPublisher,
+		config:         config,
+		logger:         logger,
+	}
+}
+
+// ExpireStaleReservations returns orders reserved longer than the TTL without a confirmed
+// pickup to pending, so a drone that went silent does not hold them forever
+func (s *ReservationServiceImpl) ExpireStaleReservations(ctx context.Context) error {
+	expired, err := s.ordersRepo.ExpireReservations(ctx, time.Now().Add(-s.config.TTL), s.config.ExpiryBatchSize)
+	if err != nil {
+		s.logger.Error("Failed to expire reservations", "error", err)
+		return err
+	}
+
+	for _, reservation := range expired {
+		order := reservation.Order
+
+		if err := s.cacheService.Delete(ctx, fmt.Sprintf("orders:%s:", order.ID)); err != nil {
+			s.logger.Error("Failed to invalidate order cache", "orderID", order.ID, "error", err)
+		}
+
+		event := events.OrderUpdatedEvent{
+			OrderID: order.ID,
+			UserID:  order.UserID,
+			Status:  order.Status,
+		}
+		if reservation.DroneID != nil {
+			event.DroneID = *reservation.DroneID
+			if err := s.cacheService.Delete(ctx, "drones:"+*reservation.DroneID); err != nil {
+				s.logger.Error("Failed to invalidate drone cache", "droneID", *reservation.DroneID, "error", err)
+			}
+		}
+
+		// Publish event, the order is back in the dispatch pool
+		if err := s.eventPublisher.PublishOrderUpdated(ctx, event); err != nil {
+			s.logger.Error("Failed to publish order updated event", "orderID", order.ID, "error", err)
+		}
+	}
+
+	if len(expired) > 0 {
+		s.logger.Info("Stale reservations expired", "count", len(expired), "ttl", s.config.TTL.String())
+	}
+	return nil
+}
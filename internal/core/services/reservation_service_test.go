@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	config "drones/configs"
+	"drones/internal/core/domain"
+	"drones/pkg/utils"
+)
+
+// go test ./internal/core/services/ -v
+
+func TestReservationExpiryTransition(t *testing.T) {
+	if !domain.OrderStatusPending.IsTransitionAllowed(domain.OrderStatusReserved) {
+		t.Fatal("Expected reserved -> pending to be allowed for expired reservations")
+	}
+	if domain.OrderStatusScheduled.IsTransitionAllowed(domain.OrderStatusReserved) {
+		t.Error("Expected reserved -> scheduled to stay forbidden")
+	}
+}
+
+func TestExpireStaleReservations(t *testing.T) {
+	droneID := "drone-1"
+	repo := newFakeOrdersRepo()
+	var gotBefore time.Time
+	var gotLimit int
+	repo.expireReservations = func(reservedBefore time.Time, limit int) ([]*domain.ExpiredReservation, error) {
+		gotBefore, gotLimit = reservedBefore, limit
+		return []*domain.ExpiredReservation{
+			{Order: &domain.Order{BaseModel: domain.BaseModel{ID: "order-1"}, UserID: "user-1", Status: domain.OrderStatusPending}, DroneID: &droneID},
+			{Order: &domain.Order{BaseModel: domain.BaseModel{ID: "order-2"}, UserID: "user-2", Status: domain.OrderStatusPending}},
+		}, nil
+	}
+	cache := newFakeCache()
+	cache.Set(context.Background(), "orders:order-1:", domain.Order{Status: domain.OrderStatusReserved}, 0)
+	publisher := &fakePublisher{}
+
+	service := NewReservationService(repo, cache, publisher, config.ReservationConfig{TTL: 10 * time.Minute, ExpiryBatchSize: 50}, nopLogger{})
+	if err := service.ExpireStaleReservations(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if gotLimit != 50 {
+		t.Errorf("Expected batch size 50, got %d", gotLimit)
+	}
+	if age := time.Since(gotBefore); age < 10*time.Minute || age > 11*time.Minute {
+		t.Errorf("Expected cutoff about the TTL ago, got %s ago", age)
+	}
+	for _, key := range []string{"orders:order-1:", "orders:order-2:", "drones:" + droneID} {
+		if !cache.wasDeleted(key) {
+			t.Errorf("Expected cache key %s to be invalidated", key)
+		}
+	}
+	if len(publisher.updated) != 2 {
+		t.Fatalf("Expected 2 order updated events, got %d", len(publisher.updated))
+	}
+	if publisher.updated[0].Status != domain.OrderStatusPending || publisher.updated[0].DroneID != droneID {
+		t.Errorf("Expected pending event with the releasing drone, got %+v", publisher.updated[0])
+	}
+}
+
+func TestUpdateOrderCannotReleaseReservation(t *testing.T) {
+	repo := newFakeOrdersRepo(&domain.Order{
+		BaseModel: domain.BaseModel{ID: "order-1"},
+		Status:    domain.OrderStatusReserved,
+		DroneID:   utils.StringPtr("drone-1"),
+	})
+	service := newTestOrdersService(repo, newFakeCache(), &fakePublisher{}, nil)
+
+	status := domain.OrderStatusPending
+	_, err := service.UpdateOrder(context.Background(), "order-1", &domain.UpdateOrderRequest{Status: &status}, domain.OrderFilter{})
+	if err != domain.ErrReservationReleasedOnExpiry {
+		t.Fatalf("Expected ErrReservationReleasedOnExpiry, got: %v", err)
+	}
+	if order := repo.order("order-1"); order.Status != domain.OrderStatusReserved {
+		t.Errorf("Expected the order to stay reserved, got %s", order.Status)
+	}
+}
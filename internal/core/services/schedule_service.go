@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	config "drones/configs"
+	"drones/internal/core/events"
+	"drones/internal/ports"
+)
+
+type ScheduleServiceImpl struct {
+	ordersRepo     ports.OrdersRepository
+	cacheService   ports.CacheService
+	eventPublisher ports.EventPublisher
+	config         config.ScheduleConfig
+	logger         ports.Logger
+}
+
+func NewScheduleService(
+	ordersRepo ports.OrdersRepository,
+	cacheService ports.CacheService,
+	eventPublisher ports.EventPublisher,
+	config config.ScheduleConfig,
+	logger ports.Logger,
+) ports.ScheduleService {
+	return &ScheduleServiceImpl{
+		ordersRepo:     ordersRepo,
+		cacheService:   cacheService,
+		eventPublisher: eventPublisher,
+		config:         config,
+		logger:         logger,
+	}
+}
+
+func (s *ScheduleServiceImpl) ReleaseDueOrders(ctx context.Context) error {
+	orders, err := s.ordersRepo.ReleaseScheduledOrders(ctx, time.Now().Add(s.config.LeadTime), s.config.ReleaseBatchSize)
+	if err != nil {
+		s.logger.Error("Failed to release scheduled orders", "error", err)
+		return err
+	}
+
+	for _, order := range orders {
+		if err := s.cacheService.Delete(ctx, fmt.Sprintf("orders:%s:", order.ID)); err != nil {
+			s.logger.Error("Failed to invalidate order cache", "orderID", order.ID, "error", err)
+		}
+
+		// Publish event, the dispatcher picks released orders up
+		if err := s.eventPublisher.PublishOrderReleased(ctx, events.OrderReleasedEvent{
+			OrderID:     order.ID,
+			UserID:      order.UserID,
+			Status:      order.Status,
+			ScheduledAt: order.ScheduledAt,
+		}); err != nil {
+			s.logger.Error("Failed to publish order released event", "orderID", order.ID, "error", err)
+		}
+	}
+
+	if len(orders) > 0 {
+		s.logger.Info("Scheduled orders released", "count", len(orders))
+	}
+	return nil
+}
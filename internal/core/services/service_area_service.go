@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"drones/internal/core/domain"
+	"drones/internal/ports"
+)
+
+// serviceAreasCacheKey caches the service areas, every service area change invalidates it
+const serviceAreasCacheKey = "service_areas:all"
+
+type ServiceAreaServiceImpl struct {
+	repo         ports.ServiceAreasRepository
+	cacheService ports.CacheService
+	logger       ports.Logger
+}
+
+func NewServiceAreaService(repo ports.ServiceAreasRepository, cacheService ports.CacheService, logger ports.Logger) ports.ServiceAreaService {
+	return &ServiceAreaServiceImpl{
+		repo:         repo,
+		cacheService: cacheService,
+		logger:       logger,
+	}
+}
+
+// ListServiceAreas returns the service areas not deleted
+func (s *ServiceAreaServiceImpl) ListServiceAreas(ctx context.Context) ([]*domain.ServiceArea, error) {
+	var areas []*domain.ServiceArea
+	if err := s.cacheService.Get(ctx, serviceAreasCacheKey, &areas); err == nil && areas != nil {
+		return areas, nil
+	}
+
+	areas, err := s.repo.ListServiceAreas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if areas == nil {
+		areas = []*domain.ServiceArea{}
+	}
+
+	if err := s.cacheService.Set(ctx, serviceAreasCacheKey, areas, 0); err != nil {
+		s.logger.Error("Failed to cache service areas", "key", serviceAreasCacheKey, "error", err)
+	}
+
+	return areas, nil
+}
+
+func (s *ServiceAreaServiceImpl) CreateServiceArea(ctx context.Context, userID string, request *domain.ServiceAreaRequest) (*domain.ServiceArea, error) {
+	if err := preparePhoneFormats(request); err != nil {
+		return nil, err
+	}
+
+	area, err := s.repo.CreateServiceArea(ctx, userID, request)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateServiceAreas(ctx)
+	return area, nil
+}
+
+func (s *ServiceAreaServiceImpl) UpdateServiceArea(ctx context.Context, serviceAreaID string, userID string, request *domain.ServiceAreaRequest) (*domain.ServiceArea, error) {
+	if err := preparePhoneFormats(request); err != nil {
+		return nil, err
+	}
+
+	area, err := s.repo.UpdateServiceArea(ctx, serviceAreaID, userID, request)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateServiceAreas(ctx)
+	return area, nil
+}
+
+func (s *ServiceAreaServiceImpl) DeleteServiceArea(ctx context.Context, serviceAreaID string, userID string) error {
+	if err := s.repo.DeleteServiceArea(ctx, serviceAreaID, userID); err != nil {
+		return err
+	}
+
+	s.invalidateServiceAreas(ctx)
+	return nil
+}
+
+// FindServiceArea returns the first area containing every point, nil when there is none
+func (s *ServiceAreaServiceImpl) FindServiceArea(ctx context.Context, points ...domain.GeoPoint) (*domain.ServiceArea, error) {
+	areas, err := s.ListServiceAreas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return domain.FindServiceArea(areas, points...), nil
+}
+
+func (s *ServiceAreaServiceImpl) invalidateServiceAreas(ctx context.Context) {
+	if err := s.cacheService.Delete(ctx, serviceAreasCacheKey); err != nil {
+		s.logger.Error("Failed to invalidate service areas cache", "key", serviceAreasCacheKey, "error", err)
+	}
+}
+
+// preparePhoneFormats checks every phone format is a valid regular expression, an area without
+// formats is stored with an empty list
+func preparePhoneFormats(request *domain.ServiceAreaRequest) error {
+	if request.PhoneFormats == nil {
+		request.PhoneFormats = []string{}
+	}
+	for _, format := range request.PhoneFormats {
+		if _, err := regexp.Compile(format); err != nil {
+			return domain.NewDomainError(domain.InvalidInputError, fmt.Sprintf("Invalid phone format %q", format), err)
+		}
+	}
+	return nil
+}
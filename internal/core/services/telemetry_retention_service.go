@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	config "drones/configs"
+	"drones/internal/ports"
+)
+
+type TelemetryRetentionServiceImpl struct {
+	telemetryRepo ports.DroneTelemetryRepository
+	config        config.TelemetryConfig
+	logger        ports.Logger
+}
+
+func NewTelemetryRetentionService(
+	telemetryRepo ports.DroneTelemetryRepository,
+	config config.TelemetryConfig,
+	logger ports.Logger,
+) ports.TelemetryRetentionService {
+	return &TelemetryRetentionServiceImpl{
+		telemetryRepo: telemetryRepo,
+		config:        config,
+		logger:        logger,
+	}
+}
+
+// PruneTelemetry deletes heartbeats older than the retention period in batches, until a batch
+// comes back short, so one run catches up however many heartbeats piled up
+func (s *TelemetryRetentionServiceImpl) PruneTelemetry(ctx context.Context) error {
+	cutoff := time.Now().Add(-s.config.Retention)
+
+	var total int64
+	for {
+		deleted, err := s.telemetryRepo.PruneTelemetry(ctx, cutoff, s.config.PruneBatchSize)
+		if err != nil {
+			s.logger.Error("Failed to prune drone telemetry", "deleted", total, "error", err)
+			return err
+		}
+		total += deleted
+
+		if deleted < int64(s.config.PruneBatchSize) || ctx.Err() != nil {
+			break
+		}
+	}
+
+	if total > 0 {
+		s.logger.Info("Drone telemetry pruned", "count", total, "retention", s.config.Retention.String())
+	}
+	return nil
+}
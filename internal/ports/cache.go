@@ -10,6 +10,9 @@ type CacheService interface {
 	// Get retrieves a value from cache
 	Get(ctx context.Context, key string, dest interface{}) error
 
+	// SetIfNotExists stores a value only if the key does not exist yet, reports whether it was stored
+	SetIfNotExists(ctx context.Context, key string, value interface{}, ttl int) (bool, error)
+
 	// Delete removes a value from cache
 	Delete(ctx context.Context, key string) error
 
@@ -16,7 +16,31 @@ type EventPublisher interface {
 
 	PublishOrderUpdated(ctx context.Context, event events.OrderUpdatedEvent) error
 
+	// Publish order assigned event
+	PublishOrderAssigned(ctx context.Context, event events.OrderAssignedEvent) error
+
+	// Publish scheduled order released event
+	PublishOrderReleased(ctx context.Context, event events.OrderReleasedEvent) error
+
+	// Publish order cancelled event
+	PublishOrderCancelled(ctx context.Context, event events.OrderCancelledEvent) error
+
+	// Publish a one-time code to be sent by SMS
+	PublishSendOTP(ctx context.Context, event events.SendOTPEvent) error
+
+	// Publish a failed delivery attempt to be notified to the enduser
+	PublishOrderDeliveryFailed(ctx context.Context, event events.OrderDeliveryFailedEvent) error
+
 	// Drone Events
+	// Publish an alert for a drone that stopped sending heartbeats
+	PublishDroneLost(ctx context.Context, event events.DroneLostEvent) error
+
+	// Publish a lost drone sending heartbeats again
+	PublishDroneRestored(ctx context.Context, event events.DroneRestoredEvent) error
+
+	// Publish an alert for a drone that entered a no-fly zone
+	PublishGeofenceViolation(ctx context.Context, event events.GeofenceViolationEvent) error
+
 	Stop() error
 }
 
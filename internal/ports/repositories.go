@@ -3,6 +3,7 @@ package ports
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"drones/internal/core/domain"
 )
@@ -33,6 +34,9 @@ type OrdersRepository interface {
 	// Define methods for order data persistence
 	CreateOrder(ctx context.Context, userID string, order *domain.CreateOrderRequest) (*domain.Order, error)
 
+	// CreateOrders creates several orders in a single transaction
+	CreateOrders(ctx context.Context, userID string, orders []*domain.CreateOrderRequest) ([]*domain.Order, error)
+
 	// GetByID retrieves an order by its ID
 	GetOrderByID(ctx context.Context, orderID string, options domain.OrderFilter) (*domain.Order, error)
 
@@ -50,6 +54,49 @@ type OrdersRepository interface {
 
 	// ReseverOrder reserves an order for a specific drone
 	UpdateOrderStatus(ctx context.Context, orderID string, options domain.UpdateStatusRequest) (*domain.Order, error)
+
+	// ListPendingOrders retrieves unassigned pending orders, oldest first
+	ListPendingOrders(ctx context.Context, limit int) ([]*domain.Order, error)
+
+	// AssignOrder atomically reserves a pending order for an idle drone
+	AssignOrder(ctx context.Context, orderID string, droneID string) (*domain.Order, error)
+
+	// ListAvailableOrders retrieves pending and handoff orders reachable from a position
+	ListAvailableOrders(ctx context.Context, query domain.AvailableJobsQuery) ([]*domain.AvailableJob, error)
+
+	// ClaimNextOrder atomically reserves the best pending or handoff order for a drone
+	ClaimNextOrder(ctx context.Context, droneID string, updatedByID string, query domain.AvailableJobsQuery) (*domain.Order, error)
+
+	// ListOrderStatusHistory retrieves the recorded status transitions of an order, oldest first
+	ListOrderStatusHistory(ctx context.Context, orderID string) ([]*domain.OrderStatusHistory, error)
+
+	// ReleaseScheduledOrders moves scheduled orders due before releaseBefore to pending
+	ReleaseScheduledOrders(ctx context.Context, releaseBefore time.Time, limit int) ([]*domain.Order, error)
+
+	// ExpireReservations moves orders reserved before reservedBefore back to pending and frees their loading drones
+	ExpireReservations(ctx context.Context, reservedBefore time.Time, limit int) ([]*domain.ExpiredReservation, error)
+
+	// CancelOrder cancels an open order, releasing its drone
+	CancelOrder(ctx context.Context, orderID string, cancelledByID string, request *domain.CancelOrderRequest) (*domain.OrderCancellation, error)
+
+	// HandoffOrder detaches the drone and moves the pickup point to its last known position
+	HandoffOrder(ctx context.Context, orderID string, updatedByID string, reason string) (*domain.Order, error)
+
+	// ResolveDeliveryFailure records a failed attempt, applies the failure policy action and queues the drone command
+	ResolveDeliveryFailure(ctx context.Context, orderID string, resolution domain.DeliveryFailureResolution) (*domain.DeliveryFailureOutcome, error)
+
+	// ChangeOrderRoute writes a new route, queues it for the assigned drone and records the audit entry
+	ChangeOrderRoute(ctx context.Context, orderID string, change domain.RouteChange) (*domain.RouteChangeOutcome, error)
+
+	// ListOrderCarriers retrieves every drone that carried an order, in pickup order
+	ListOrderCarriers(ctx context.Context, orderID string) ([]*domain.OrderCarrier, error)
+
+	// GetDeliveryProof retrieves the delivery code and proof of an order
+	GetDeliveryProof(ctx context.Context, orderID string) (*domain.DeliveryProof, error)
+
+	// CheckDeliveryCode compares a delivery code hash and atomically counts a mismatch, returns whether
+	// it matched and the failures so far. ErrDeliveryCodeLocked once maxAttempts failures are counted.
+	CheckDeliveryCode(ctx context.Context, orderID string, droneID string, codeHash string, maxAttempts int) (bool, int, error)
 }
 
 type DronesRepository interface {
@@ -77,9 +124,96 @@ type DronesRepository interface {
 	// ListDrones retrieves a list of drones based on the provided filter
 	ListDrones(ctx context.Context, options domain.PaginationOption[domain.DroneFilter]) (*domain.Pagination[domain.DroneDTO], error)
 
-	// Update status
-	UpdateStatusBroken(ctx context.Context, userID, droneID string, status domain.DroneStatus) (*domain.Drone, error)
+	// Update status, a broken drone hands off its active orders
+	UpdateStatusBroken(ctx context.Context, userID, droneID string, status domain.DroneStatus) (*domain.Drone, []domain.HandedOffOrder, error)
+
+	// RestoreLostDrone moves a lost drone back to idle, ErrDroneNotLost when it is no longer lost
+	RestoreLostDrone(ctx context.Context, userID, droneID string) (*domain.Drone, error)
+
+	// Heartbeat, flight time is credited for gaps up to maxFlightGap and the heartbeat recorded as telemetry
+	ProcessHeartbeat(ctx context.Context, droneID string, userId string, req domain.HeartbeatRequest, maxFlightGap time.Duration) (*domain.Drone, error)
+
+	// MarkLostDrones grounds flying drones silent since lastHeartbeatBefore and hands off their orders
+	MarkLostDrones(ctx context.Context, lastHeartbeatBefore time.Time, limit int) ([]*domain.LostDrone, error)
+}
+
+// DroneCommandsRepository defines the interface for the commands queued for drones
+type DroneCommandsRepository interface {
+	// QueueCommand queues a command for a drone, delivered on its next heartbeat
+	QueueCommand(ctx context.Context, command domain.DroneCommand) (*domain.DroneCommand, error)
+
+	// ListCommands retrieves the commands of a drone, newest first
+	ListCommands(ctx context.Context, droneID string, filter domain.DroneCommandFilter) ([]*domain.DroneCommand, error)
+
+	// ListPendingCommands retrieves the commands a drone has not acknowledged yet, oldest first
+	ListPendingCommands(ctx context.Context, droneID string) ([]*domain.DroneCommand, error)
+
+	// AcknowledgeCommands marks pending commands of a drone as carried out
+	AcknowledgeCommands(ctx context.Context, droneID string, userID string, commandIDs []string) ([]*domain.DroneCommand, error)
+}
+
+// DroneTelemetryRepository defines the interface for the recorded heartbeats of drones
+type DroneTelemetryRepository interface {
+	// ListTelemetry retrieves the telemetry of a drone in a time range, one point per query interval
+	ListTelemetry(ctx context.Context, droneID string, query domain.DroneTelemetryQuery) ([]*domain.DroneTelemetry, error)
+
+	// ListOrderTelemetry retrieves every heartbeat recorded while a drone had the order on board, oldest first
+	ListOrderTelemetry(ctx context.Context, orderID string) ([]*domain.DroneTelemetry, error)
+
+	// SumFlightSeconds returns the flight time credited to a drone in a time range
+	SumFlightSeconds(ctx context.Context, droneID string, from, to time.Time) (float64, error)
+
+	// PruneTelemetry deletes up to limit heartbeats recorded before the cutoff
+	PruneTelemetry(ctx context.Context, recordedBefore time.Time, limit int) (int64, error)
+}
+
+// GeofencesRepository defines the interface for no-fly zones and their violations
+type GeofencesRepository interface {
+	// ListGeofences retrieves the geofences not deleted, whatever their time window
+	ListGeofences(ctx context.Context) ([]*domain.Geofence, error)
+
+	// CreateGeofence inserts a new geofence
+	CreateGeofence(ctx context.Context, userID string, request *domain.GeofenceRequest) (*domain.Geofence, error)
+
+	// UpdateGeofence replaces a geofence
+	UpdateGeofence(ctx context.Context, geofenceID string, userID string, request *domain.GeofenceRequest) (*domain.Geofence, error)
+
+	// DeleteGeofence deactivates a geofence
+	DeleteGeofence(ctx context.Context, geofenceID string, userID string) error
+
+	// RecordViolation records a drone entering a geofence
+	RecordViolation(ctx context.Context, violation domain.GeofenceViolation) (*domain.GeofenceViolation, error)
+
+	// ListViolations retrieves geofence violations, newest first
+	ListViolations(ctx context.Context, filter domain.GeofenceViolationFilter) ([]*domain.GeofenceViolation, error)
+}
+
+// ServiceAreasRepository defines the interface for the regions the fleet operates in
+type ServiceAreasRepository interface {
+	// ListServiceAreas retrieves the service areas not deleted
+	ListServiceAreas(ctx context.Context) ([]*domain.ServiceArea, error)
+
+	// CreateServiceArea inserts a new service area
+	CreateServiceArea(ctx context.Context, userID string, request *domain.ServiceAreaRequest) (*domain.ServiceArea, error)
+
+	// UpdateServiceArea replaces a service area
+	UpdateServiceArea(ctx context.Context, serviceAreaID string, userID string, request *domain.ServiceAreaRequest) (*domain.ServiceArea, error)
+
+	// DeleteServiceArea deactivates a service area
+	DeleteServiceArea(ctx context.Context, serviceAreaID string, userID string) error
+}
+
+// PricingRepository defines the interface for pricing rule persistence
+type PricingRepository interface {
+	// ListPricingRules retrieves the active pricing rules
+	ListPricingRules(ctx context.Context) ([]*domain.PricingRule, error)
+
+	// CreatePricingRule inserts a new rule
+	CreatePricingRule(ctx context.Context, userID string, request *domain.PricingRuleRequest) (*domain.PricingRule, error)
+
+	// UpdatePricingRule replaces an active rule
+	UpdatePricingRule(ctx context.Context, ruleID string, userID string, request *domain.PricingRuleRequest) (*domain.PricingRule, error)
 
-	// Heartbeat
-	ProcessHeartbeat(ctx context.Context, droneID string, userId string, req domain.HeartbeatRequest) (*domain.Drone, error)
+	// DeletePricingRule deactivates a rule
+	DeletePricingRule(ctx context.Context, ruleID string, userID string) error
 }
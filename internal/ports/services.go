@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"drones/internal/core/domain"
 )
@@ -89,15 +90,13 @@ type UserService interface {
 //
 // Current implementation includes:
 // - CreateOrder: Create a new order in the system
+// - BulkCreateOrders: Create multiple orders in a single operation
+// - CancelOrder: Cancel an existing order
 // - GetByID: Retrieve an order by its ID
 // - UpdateOrder: Update existing order information
 // - DeleteOrder: Remove an order from the system
 // - ListOrders: Retrieve a paginated list of orders based on filters
 //
-// TODO: Future enhancements should include:
-// - BulkCreateOrders: Create multiple orders in a single operation
-// - CancelOrder: Cancel an existing order
-//
 // For example, to create a new order:
 //
 //	// Create a new order
@@ -105,6 +104,9 @@ type OrdersService interface {
 	// Define methods for order data persistence
 	CreateOrder(ctx context.Context, userID string, order *domain.CreateOrderRequest) (*domain.Order, error)
 
+	// BulkCreateOrders creates validated rows in chunks and reports a result per row
+	BulkCreateOrders(ctx context.Context, userID string, rows []domain.BulkOrderRow) ([]domain.BulkOrderResult, error)
+
 	// GetByID retrieves an order by its ID
 	GetOrderByID(ctx context.Context, orderID string, options domain.OrderFilter) (*domain.Order, error)
 
@@ -129,18 +131,33 @@ type OrdersService interface {
 	// Mark order as arrived
 	ConfirmArrived(ctx context.Context, orderID string, userID string, options domain.OrderFilter) (*domain.Order, error)
 
-	// Deliverd
-	ConfirmDelivery(ctx context.Context, orderID string, userID string, options domain.OrderFilter) (*domain.Order, error)
+	// Deliverd, the drone proves the delivery with the receiver code and its GPS fix
+	ConfirmDelivery(ctx context.Context, orderID string, userID string, request *domain.ConfirmDeliveryRequest, options domain.OrderFilter) (*domain.Order, error)
+
+	// Delivery proof of an order
+	GetDeliveryProof(ctx context.Context, orderID string, options domain.OrderFilter) (*domain.DeliveryProof, error)
+
+	// Photo attached to the delivery proof of an order
+	GetDeliveryPhoto(ctx context.Context, orderID string, options domain.OrderFilter) (*domain.DeliveryPhoto, error)
 
-	// Delivery failed
-	DeliveryFailed(ctx context.Context, orderID string, userID string, options domain.OrderFilter) (*domain.Order, error)
+	// Delivery failed, the failure policy decides what happens to the order next
+	DeliveryFailed(ctx context.Context, orderID string, userID string, request *domain.DeliveryFailedRequest, options domain.OrderFilter) (*domain.Order, error)
+
+	// Confirm an order on its way back was dropped off at the origin, the sender or the depot
+	ConfirmReturned(ctx context.Context, orderID string, userID string, options domain.OrderFilter) (*domain.Order, error)
+
+	// Put an order held at the depot up for another drone
+	RequeueHeldOrder(ctx context.Context, orderID string, userID string) (*domain.Order, error)
+
+	// Move the origin or destination of an order, repriced and pushed to the assigned drone
+	ChangeRoute(ctx context.Context, orderID string, userID string, request *domain.ChangeRouteRequest) (*domain.Order, error)
 
 
 	// handoff an order
-	Handoff(ctx context.Context, orderID string, droneID string, options domain.OrderFilter) (*domain.Order, error)
+	Handoff(ctx context.Context, orderID string, userID string, options domain.OrderFilter) (*domain.Order, error)
 
 	// reassign an order
-	Reassign(ctx context.Context, orderID string, droneID string, options domain.OrderFilter) (*domain.Order, error)
+	Reassign(ctx context.Context, orderID string, userID string, options domain.OrderFilter) (*domain.Order, error)
 
 	// DeleteOrder deletes an order from the repository
 	DeleteOrder(ctx context.Context, orderID string, options domain.OrderFilter) error
@@ -150,6 +167,21 @@ type OrdersService interface {
 
 	// Order location update
 	UpadateOrderLocation(ctx context.Context, userID, orderID string, currentLat, currentLon, currentAltitude float64, options domain.OrderFilter) (*domain.Order, error)
+
+	// List reservable jobs near the drone of the user
+	ListAvailableJobs(ctx context.Context, userID string, radiusKm float64, limit int) ([]*domain.AvailableJob, error)
+
+	// Claim the best pending job near the drone of the user
+	ClaimNextOrder(ctx context.Context, userID string, radiusKm float64) (*domain.Order, error)
+
+	// Lifecycle steps of an order, oldest first
+	GetOrderTimeline(ctx context.Context, orderID string, options domain.OrderFilter) (*domain.OrderTimeline, error)
+
+	// Flight path of an order from pickup to delivery as GeoJSON
+	GetOrderTrack(ctx context.Context, orderID string, options domain.OrderFilter) (*domain.OrderTrack, error)
+
+	// CancelOrder cancels an order from any open status on behalf of an admin
+	CancelOrder(ctx context.Context, orderID string, userID string, request *domain.CancelOrderRequest) (*domain.Order, error)
 }
 
 type DronesService interface {
@@ -171,6 +203,157 @@ type DronesService interface {
 	// Action broken
 	UpdateDroneStatus(ctx context.Context, userID, droneID string, status domain.DroneStatus) (*domain.Drone, error)
 
-	// Heartbeat
-	ProcessHeartbeat(ctx context.Context,  droneID string , userId string, req domain.HeartbeatRequest) (*domain.Drone, error)
+	// Heartbeat, returns the current order, pending commands and warnings with the drone
+	ProcessHeartbeat(ctx context.Context,  droneID string , userId string, req domain.HeartbeatRequest) (*domain.Heartbeat, error)
+
+	// Queue a command for a drone, delivered on its next heartbeat
+	QueueCommand(ctx context.Context, droneID string, userID string, request *domain.QueueDroneCommandRequest) (*domain.DroneCommand, error)
+
+	// Commands queued for a drone, newest first
+	ListCommands(ctx context.Context, droneID string, filter domain.DroneCommandFilter) ([]*domain.DroneCommand, error)
+
+	// Recorded heartbeats of a drone over a time range, downsampled, with the flight time
+	GetTelemetry(ctx context.Context, droneID string, query domain.DroneTelemetryQuery) (*domain.DroneTelemetryHistory, error)
+}
+
+// Dispatch service
+// DispatchService assigns pending orders to the best available idle drone.
+//
+// Current implementation includes:
+// - DispatchOrder: Assign a single pending order, e.g. when an OrderCreated event arrives
+// - Sweep: Retry every unassigned pending order, used by the periodic worker
+type DispatchService interface {
+	// Assign a pending order to the best eligible drone
+	DispatchOrder(ctx context.Context, orderID string) (*domain.Order, error)
+
+	// Try to assign all pending orders that are still waiting for a drone
+	Sweep(ctx context.Context) error
+}
+
+// Eta service
+// EtaService keeps Order.EstimatedArrivalAt up to date.
+//
+// Current implementation includes:
+// - OnHeartbeat: Record the observed drone speed and refresh the ETA of its active order
+// - RefreshOrder: Recalculate the ETA of an order after a status transition
+type EtaService interface {
+	// Refresh the ETA of the active order of a drone, previous is the drone before the heartbeat
+	OnHeartbeat(ctx context.Context, previous *domain.Drone, current *domain.Drone) error
+
+	// Recalculate the ETA of an order, returns the order with the new ETA
+	RefreshOrder(ctx context.Context, order *domain.Order) (*domain.Order, error)
+}
+
+// Schedule service
+// ScheduleService releases scheduled orders to the dispatch pool once they are due.
+type ScheduleService interface {
+	// Release scheduled orders within the lead time of their scheduled_at
+	ReleaseDueOrders(ctx context.Context) error
+}
+
+// ReservationService returns reservations whose pickup was never confirmed to the dispatch pool.
+type ReservationService interface {
+	// Expire reservations older than the reservation TTL
+	ExpireStaleReservations(ctx context.Context) error
+}
+
+// GeofenceService manages the no-fly zones and checks positions against them.
+type GeofenceService interface {
+	// Geofences not deleted, whatever their time window
+	ListGeofences(ctx context.Context) ([]*domain.Geofence, error)
+
+	CreateGeofence(ctx context.Context, userID string, request *domain.GeofenceRequest) (*domain.Geofence, error)
+
+	UpdateGeofence(ctx context.Context, geofenceID string, userID string, request *domain.GeofenceRequest) (*domain.Geofence, error)
+
+	DeleteGeofence(ctx context.Context, geofenceID string, userID string) error
+
+	// The zone active at a time that contains a position, nil when there is none
+	FindGeofence(ctx context.Context, lat, lon float64, at time.Time) (*domain.Geofence, error)
+
+	// Record a drone entering a zone and alert operators
+	RecordViolation(ctx context.Context, violation domain.GeofenceViolation) (*domain.GeofenceViolation, error)
+
+	// Violations, newest first
+	ListViolations(ctx context.Context, filter domain.GeofenceViolationFilter) ([]*domain.GeofenceViolation, error)
+}
+
+// ServiceAreaService manages the regions the fleet operates in.
+type ServiceAreaService interface {
+	// Service areas not deleted
+	ListServiceAreas(ctx context.Context) ([]*domain.ServiceArea, error)
+
+	CreateServiceArea(ctx context.Context, userID string, request *domain.ServiceAreaRequest) (*domain.ServiceArea, error)
+
+	UpdateServiceArea(ctx context.Context, serviceAreaID string, userID string, request *domain.ServiceAreaRequest) (*domain.ServiceArea, error)
+
+	DeleteServiceArea(ctx context.Context, serviceAreaID string, userID string) error
+
+	// The first area containing every point, nil when there is none
+	FindServiceArea(ctx context.Context, points ...domain.GeoPoint) (*domain.ServiceArea, error)
+}
+
+// TelemetryRetentionService keeps the drone telemetry history within its retention period.
+type TelemetryRetentionService interface {
+	// Delete heartbeats older than the retention period
+	PruneTelemetry(ctx context.Context) error
+}
+
+// HeartbeatWatchdogService grounds flying drones that stopped sending heartbeats.
+type HeartbeatWatchdogService interface {
+	// Mark drones silent for longer than the heartbeat timeout as lost and hand off their orders
+	DetectLostDrones(ctx context.Context) error
+}
+
+// DispatchPolicy ranks eligible drones for an order, a higher score wins
+type DispatchPolicy interface {
+	// Policy name
+	Name() domain.DispatchPolicyName
+
+	// Score a candidate drone for an order
+	Score(order *domain.Order, candidate *domain.DispatchCandidate) float64
+}
+
+// DeliveryFailurePolicy decides what happens to an order after a failed delivery attempt
+type DeliveryFailurePolicy interface {
+	// Action for an order whose DeliveryAttempts already counts the failed attempt
+	Decide(order *domain.Order, request *domain.DeliveryFailedRequest) domain.DeliveryFailureAction
+}
+
+// PricingService prices deliveries from the admin editable rule table
+type PricingService interface {
+	// Quote prices a delivery and returns a signed, time-limited quote
+	Quote(ctx context.Context, userID string, request *domain.QuoteRequest) (*domain.Quote, error)
+
+	// PriceOrder sets the price of a new order, locked in from its quote when it has one
+	PriceOrder(ctx context.Context, userID string, order *domain.CreateOrderRequest) error
+
+	// PriceRoute prices an existing order on a new route with the current rules
+	PriceRoute(ctx context.Context, order *domain.Order, route domain.OrderRoute) (*domain.PriceBreakdown, error)
+
+	// ListRules returns the active pricing rules
+	ListRules(ctx context.Context) ([]*domain.PricingRule, error)
+
+	// CreateRule adds a pricing rule
+	CreateRule(ctx context.Context, userID string, request *domain.PricingRuleRequest) (*domain.PricingRule, error)
+
+	// UpdateRule replaces a pricing rule
+	UpdateRule(ctx context.Context, ruleID string, userID string, request *domain.PricingRuleRequest) (*domain.PricingRule, error)
+
+	// DeleteRule deactivates a pricing rule
+	DeleteRule(ctx context.Context, ruleID string, userID string) error
+}
+
+// IdempotencyService remembers the responses of requests sent with an idempotency key
+// so that client retries are replayed instead of processed again
+type IdempotencyService interface {
+	// Begin reserves the key for a request. It returns nil when the request should be
+	// processed, or the stored record when the same request was already processed.
+	Begin(ctx context.Context, userID string, key string, requestHash string) (*domain.IdempotencyRecord, error)
+
+	// Complete stores the response of a request reserved with Begin
+	Complete(ctx context.Context, userID string, key string, record *domain.IdempotencyRecord) error
+
+	// Release forgets the key so the request can be retried, used when processing failed
+	Release(ctx context.Context, userID string, key string) error
 }
@@ -0,0 +1,15 @@
+package ports
+
+import "context"
+
+// BlobStorage defines the interface for storing binary objects such as delivery photos
+type BlobStorage interface {
+	// Put stores data under key, replacing any existing object
+	Put(ctx context.Context, key string, data []byte) error
+
+	// Get retrieves the object stored under key, domain.ErrBlobNotFound when there is none
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Delete removes the object stored under key
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,12 @@
+package ports
+
+import "context"
+
+// Worker defines a long running background job started and stopped with the application
+type Worker interface {
+	// Start runs the worker in the background until Stop is called or ctx is cancelled
+	Start(ctx context.Context) error
+
+	// Stop signals the worker to stop and waits for the current run to finish
+	Stop() error
+}
@@ -0,0 +1,10 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_order_status_history_drone;
+DROP INDEX IF EXISTS idx_order_status_history_order;
+
+
+-- Drop triggers
+DROP TRIGGER IF EXISTS trg_order_status_history_updated_at ON order_status_history;
+
+-- Drop table
+DROP TABLE IF EXISTS order_status_history;
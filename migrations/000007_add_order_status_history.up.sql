@@ -0,0 +1,24 @@
+--- Order Status History Table
+CREATE TABLE order_status_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    from_status VARCHAR(50),              -- NULL when the order is created
+    to_status VARCHAR(50) NOT NULL,
+    drone_id UUID,                        -- drone handling the order at the time
+    actor_id UUID,                        -- user who triggered the transition, NULL for system
+    reason TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by_id UUID,
+    updated_by_id UUID
+);
+
+CREATE INDEX idx_order_status_history_order ON order_status_history(order_id, created_at);
+CREATE INDEX idx_order_status_history_drone ON order_status_history(drone_id);
+
+
+CREATE TRIGGER trg_order_status_history_updated_at
+BEFORE UPDATE ON order_status_history
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,4 @@
+ALTER TABLE order_status_history
+    DROP COLUMN IF EXISTS altitude,
+    DROP COLUMN IF EXISTS lon,
+    DROP COLUMN IF EXISTS lat;
@@ -0,0 +1,5 @@
+-- Position of the drone, or of the package when no drone is involved, at the time of the transition
+ALTER TABLE order_status_history
+    ADD COLUMN lat DOUBLE PRECISION,
+    ADD COLUMN lon DOUBLE PRECISION,
+    ADD COLUMN altitude DOUBLE PRECISION;
@@ -0,0 +1,4 @@
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS cancelled_by_id,
+    DROP COLUMN IF EXISTS cancellation_note,
+    DROP COLUMN IF EXISTS cancellation_reason;
@@ -0,0 +1,5 @@
+-- Why and by whom an order was cancelled
+ALTER TABLE orders
+    ADD COLUMN cancellation_reason VARCHAR(50),
+    ADD COLUMN cancellation_note TEXT,
+    ADD COLUMN cancelled_by_id UUID;
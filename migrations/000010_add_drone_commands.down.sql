@@ -0,0 +1,10 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_drone_commands_order;
+DROP INDEX IF EXISTS idx_drone_commands_drone_status;
+
+
+-- Drop triggers
+DROP TRIGGER IF EXISTS trg_drone_commands_updated_at ON drone_commands;
+
+-- Drop table
+DROP TABLE IF EXISTS drone_commands;
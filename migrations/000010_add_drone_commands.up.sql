@@ -0,0 +1,27 @@
+--- Drone Commands Table
+-- Instructions queued for a drone, delivered on its next heartbeat
+CREATE TABLE drone_commands (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    drone_id UUID NOT NULL REFERENCES drones(id) ON DELETE CASCADE,
+    order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    command VARCHAR(50) NOT NULL,             -- 'return_to_origin', ...
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- 'pending', 'acknowledged'
+    lat DOUBLE PRECISION,                     -- target position, when the command has one
+    lon DOUBLE PRECISION,
+    note TEXT,
+    acknowledged_at TIMESTAMP,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by_id UUID,
+    updated_by_id UUID
+);
+
+CREATE INDEX idx_drone_commands_drone_status ON drone_commands(drone_id, status, created_at);
+CREATE INDEX idx_drone_commands_order ON drone_commands(order_id);
+
+
+CREATE TRIGGER trg_drone_commands_updated_at
+BEFORE UPDATE ON drone_commands
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,10 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_order_carriers_drone;
+DROP INDEX IF EXISTS idx_order_carriers_order;
+
+
+-- Drop triggers
+DROP TRIGGER IF EXISTS trg_order_carriers_updated_at ON order_carriers;
+
+-- Drop table
+DROP TABLE IF EXISTS order_carriers;
@@ -0,0 +1,28 @@
+--- Order Carriers Table
+-- Every drone that carried an order, from pickup until it delivered or handed the package off
+CREATE TABLE order_carriers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    drone_id UUID NOT NULL REFERENCES drones(id),
+    pickup_lat DOUBLE PRECISION,
+    pickup_lon DOUBLE PRECISION,
+    picked_up_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    release_lat DOUBLE PRECISION,
+    release_lon DOUBLE PRECISION,
+    released_at TIMESTAMPTZ,                  -- NULL while the drone still carries the package
+    release_status VARCHAR(50),               -- order status that ended the leg: 'delivered', 'handoff', 'cancelled'
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by_id UUID,
+    updated_by_id UUID
+);
+
+CREATE INDEX idx_order_carriers_order ON order_carriers(order_id, picked_up_at);
+CREATE INDEX idx_order_carriers_drone ON order_carriers(drone_id) WHERE released_at IS NULL;
+
+
+CREATE TRIGGER trg_order_carriers_updated_at
+BEFORE UPDATE ON order_carriers
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,5 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS trg_delivery_proofs_updated_at ON delivery_proofs;
+
+-- Drop table
+DROP TABLE IF EXISTS delivery_proofs;
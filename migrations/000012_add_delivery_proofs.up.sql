@@ -0,0 +1,27 @@
+--- Delivery Proofs Table
+-- One-time receiver code issued at order creation and the proof submitted by the drone on delivery
+CREATE TABLE delivery_proofs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL UNIQUE REFERENCES orders(id) ON DELETE CASCADE,
+    code_hash VARCHAR(128),                   -- NULL for orders created before delivery codes
+    code_salt VARCHAR(64),
+    failed_attempts INT NOT NULL DEFAULT 0,
+    drone_id UUID REFERENCES drones(id),
+    lat DOUBLE PRECISION,
+    lon DOUBLE PRECISION,
+    distance_m DOUBLE PRECISION,              -- distance between the drone fix and the destination
+    photo_key TEXT,                           -- blob storage key of the optional photo
+    photo_content_type VARCHAR(100),
+    verified_at TIMESTAMPTZ,                  -- NULL until the drone submitted the right code
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by_id UUID,
+    updated_by_id UUID
+);
+
+
+CREATE TRIGGER trg_delivery_proofs_updated_at
+BEFORE UPDATE ON delivery_proofs
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,14 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_orders_quote_id;
+DROP INDEX IF EXISTS idx_pricing_rules_tariff;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS quote_id,
+    DROP COLUMN IF EXISTS currency,
+    DROP COLUMN IF EXISTS price;
+
+-- Drop triggers
+DROP TRIGGER IF EXISTS trg_pricing_rules_updated_at ON pricing_rules;
+
+-- Drop table
+DROP TABLE IF EXISTS pricing_rules;
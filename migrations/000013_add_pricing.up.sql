@@ -0,0 +1,46 @@
+--- Pricing Rules Table
+-- Admin editable tariffs per priority tier and time-of-day surcharges
+CREATE TABLE pricing_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    rule_type VARCHAR(50) NOT NULL,           -- 'tariff', 'time_surcharge'
+    priority VARCHAR(50),                     -- tier of a tariff, NULL surcharges apply to every tier
+    base_fare NUMERIC(10, 2) NOT NULL DEFAULT 0,
+    per_km NUMERIC(10, 2) NOT NULL DEFAULT 0,
+    per_kg NUMERIC(10, 2) NOT NULL DEFAULT 0,
+    min_fare NUMERIC(10, 2) NOT NULL DEFAULT 0,
+    surcharge_percent NUMERIC(6, 2) NOT NULL DEFAULT 0,
+    start_time VARCHAR(5),                    -- surcharge window, local "HH:MM"
+    end_time VARCHAR(5),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by_id UUID,
+    updated_by_id UUID
+);
+
+CREATE UNIQUE INDEX idx_pricing_rules_tariff ON pricing_rules(priority) WHERE rule_type = 'tariff' AND active = TRUE;
+
+
+CREATE TRIGGER trg_pricing_rules_updated_at
+BEFORE UPDATE ON pricing_rules
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Default rules
+INSERT INTO pricing_rules (name, rule_type, priority, base_fare, per_km, per_kg, min_fare) VALUES
+    ('Standard tariff', 'tariff', 'standard', 10.00, 2.00, 1.00, 15.00),
+    ('Express tariff', 'tariff', 'express', 15.00, 3.00, 1.50, 25.00),
+    ('Critical medical tariff', 'tariff', 'critical_medical', 25.00, 4.00, 2.00, 40.00);
+
+INSERT INTO pricing_rules (name, rule_type, surcharge_percent, start_time, end_time) VALUES
+    ('Night surcharge', 'time_surcharge', 20.00, '22:00', '06:00');
+
+-- Price locked in when the order was created
+ALTER TABLE orders
+    ADD COLUMN price NUMERIC(10, 2),
+    ADD COLUMN currency VARCHAR(3),
+    ADD COLUMN quote_id UUID;
+
+-- A quote can only be used once
+CREATE UNIQUE INDEX idx_orders_quote_id ON orders(quote_id) WHERE quote_id IS NOT NULL;
@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_orders_status_priority;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS priority;
@@ -0,0 +1,5 @@
+-- Priority tier an order was booked with, used to rank dispatch and available jobs
+ALTER TABLE orders
+    ADD COLUMN priority VARCHAR(50) NOT NULL DEFAULT 'standard';
+
+CREATE INDEX idx_orders_status_priority ON orders(status, priority, created_at);
@@ -0,0 +1,5 @@
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS required_capabilities;
+
+ALTER TABLE drones
+    DROP COLUMN IF EXISTS capabilities;
@@ -0,0 +1,7 @@
+-- Equipment a drone is fitted with (cold_chain, heavy_lift, secure_box)
+ALTER TABLE drones
+    ADD COLUMN capabilities VARCHAR(50)[] NOT NULL DEFAULT '{}';
+
+-- Equipment a drone needs to carry the order
+ALTER TABLE orders
+    ADD COLUMN required_capabilities VARCHAR(50)[] NOT NULL DEFAULT '{}';
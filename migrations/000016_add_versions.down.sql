@@ -0,0 +1,11 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS trg_drones_version ON drones;
+DROP TRIGGER IF EXISTS trg_orders_version ON orders;
+
+ALTER TABLE drones
+    DROP COLUMN IF EXISTS version;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS version;
+
+DROP FUNCTION IF EXISTS increment_version_column();
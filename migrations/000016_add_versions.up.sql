@@ -0,0 +1,29 @@
+-- Create function to bump the row version on every change, except to the columns
+-- passed as trigger arguments (telemetry written by heartbeats)
+CREATE OR REPLACE FUNCTION increment_version_column()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF (to_jsonb(NEW) - TG_ARGV - 'version' - 'updated_at') IS DISTINCT FROM
+       (to_jsonb(OLD) - TG_ARGV - 'version' - 'updated_at') THEN
+        NEW.version = OLD.version + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+-- Version used for optimistic locking, exposed as the ETag of the row
+ALTER TABLE orders
+    ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE drones
+    ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+CREATE TRIGGER trg_orders_version
+BEFORE UPDATE ON orders
+FOR EACH ROW
+EXECUTE FUNCTION increment_version_column('current_lat', 'current_lon', 'current_altitude', 'last_location_update_at', 'estimated_arrival_at');
+
+CREATE TRIGGER trg_drones_version
+BEFORE UPDATE ON drones
+FOR EACH ROW
+EXECUTE FUNCTION increment_version_column('current_lat', 'current_lon', 'current_altitude', 'battery_level_percent', 'last_location_update_at');
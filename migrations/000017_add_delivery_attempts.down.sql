@@ -0,0 +1,4 @@
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS failure_action,
+    DROP COLUMN IF EXISTS max_delivery_attempts,
+    DROP COLUMN IF EXISTS delivery_attempts;
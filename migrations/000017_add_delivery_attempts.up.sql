@@ -0,0 +1,5 @@
+-- Failed delivery attempts and what the failure policy did after the last one
+ALTER TABLE orders
+    ADD COLUMN delivery_attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN max_delivery_attempts INTEGER NOT NULL DEFAULT 3,
+    ADD COLUMN failure_action VARCHAR(50); -- 'requeue', 'return_to_sender', 'hold_at_depot'
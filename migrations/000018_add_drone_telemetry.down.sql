@@ -0,0 +1,17 @@
+-- Flight hours bump the drone version again
+DROP TRIGGER IF EXISTS trg_drones_version ON drones;
+CREATE TRIGGER trg_drones_version
+BEFORE UPDATE ON drones
+FOR EACH ROW
+EXECUTE FUNCTION increment_version_column('current_lat', 'current_lon', 'current_altitude', 'battery_level_percent', 'last_location_update_at');
+
+ALTER TABLE drones
+    ALTER COLUMN total_flight_hours TYPE NUMERIC(10,2);
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_drone_telemetry_recorded;
+DROP INDEX IF EXISTS idx_drone_telemetry_order;
+DROP INDEX IF EXISTS idx_drone_telemetry_drone_recorded;
+
+-- Drop table
+DROP TABLE IF EXISTS drone_telemetry;
@@ -0,0 +1,29 @@
+-- Drone Telemetry Table
+-- Every heartbeat of a drone, kept for incident investigations and flight-hour accounting
+CREATE TABLE drone_telemetry (
+    id BIGSERIAL PRIMARY KEY,
+    drone_id UUID NOT NULL REFERENCES drones(id) ON DELETE CASCADE,
+    order_id UUID REFERENCES orders(id) ON DELETE SET NULL, -- order on board when the heartbeat was sent
+    status VARCHAR(50) NOT NULL,              -- drone status after the heartbeat
+    lat DOUBLE PRECISION NOT NULL,
+    lon DOUBLE PRECISION NOT NULL,
+    altitude DOUBLE PRECISION NOT NULL,
+    battery_level_percent INTEGER NOT NULL,
+    flight_seconds DOUBLE PRECISION NOT NULL DEFAULT 0, -- flight time credited to the drone by this heartbeat
+    recorded_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_drone_telemetry_drone_recorded ON drone_telemetry(drone_id, recorded_at);
+CREATE INDEX idx_drone_telemetry_order ON drone_telemetry(order_id, recorded_at) WHERE order_id IS NOT NULL;
+CREATE INDEX idx_drone_telemetry_recorded ON drone_telemetry(recorded_at);
+
+-- Flight time is now added on every heartbeat, a few seconds at a time
+ALTER TABLE drones
+    ALTER COLUMN total_flight_hours TYPE NUMERIC(14,6);
+
+-- Heartbeats now write the flight hours too, they must not bump the drone version
+DROP TRIGGER IF EXISTS trg_drones_version ON drones;
+CREATE TRIGGER trg_drones_version
+BEFORE UPDATE ON drones
+FOR EACH ROW
+EXECUTE FUNCTION increment_version_column('current_lat', 'current_lon', 'current_altitude', 'battery_level_percent', 'last_location_update_at', 'total_flight_hours');
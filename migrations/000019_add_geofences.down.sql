@@ -0,0 +1,12 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_geofence_violations_drone;
+DROP INDEX IF EXISTS idx_geofence_violations_geofence;
+DROP INDEX IF EXISTS idx_geofences_active;
+
+
+-- Drop triggers
+DROP TRIGGER IF EXISTS trg_geofences_updated_at ON geofences;
+
+-- Drop tables
+DROP TABLE IF EXISTS geofence_violations;
+DROP TABLE IF EXISTS geofences;
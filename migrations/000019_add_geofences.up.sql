@@ -0,0 +1,44 @@
+--- Geofences Table
+-- No-fly zones drawn by admins, checked on order creation, route changes and every heartbeat
+CREATE TABLE geofences (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    shape VARCHAR(20) NOT NULL,               -- 'polygon', 'circle'
+    points JSONB,                             -- polygon vertices, [{"lat": ..., "lon": ...}]
+    center_lat DOUBLE PRECISION,              -- circle
+    center_lon DOUBLE PRECISION,
+    radius_m DOUBLE PRECISION,
+    start_time VARCHAR(5),                    -- daily window, local "HH:MM", NULL for all day
+    end_time VARCHAR(5),
+    active_from TIMESTAMPTZ,                  -- NULL for no limit
+    active_until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by_id UUID,
+    updated_by_id UUID
+);
+
+CREATE INDEX idx_geofences_active ON geofences(active) WHERE active = TRUE;
+
+
+CREATE TRIGGER trg_geofences_updated_at
+BEFORE UPDATE ON geofences
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+--- Geofence Violations Table
+-- A drone entering an active no-fly zone
+CREATE TABLE geofence_violations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    geofence_id UUID NOT NULL REFERENCES geofences(id) ON DELETE CASCADE,
+    drone_id UUID NOT NULL REFERENCES drones(id) ON DELETE CASCADE,
+    order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    lat DOUBLE PRECISION NOT NULL,
+    lon DOUBLE PRECISION NOT NULL,
+    altitude DOUBLE PRECISION,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_geofence_violations_geofence ON geofence_violations(geofence_id, created_at);
+CREATE INDEX idx_geofence_violations_drone ON geofence_violations(drone_id, created_at);
@@ -0,0 +1,12 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_orders_service_area;
+DROP INDEX IF EXISTS idx_service_areas_active;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS service_area_id;
+
+-- Drop triggers
+DROP TRIGGER IF EXISTS trg_service_areas_updated_at ON service_areas;
+
+-- Drop table
+DROP TABLE IF EXISTS service_areas;
@@ -0,0 +1,35 @@
+--- Service Areas Table
+-- Regions the fleet operates in, every order is picked up and dropped off inside one of them
+CREATE TABLE service_areas (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    points JSONB NOT NULL,                    -- polygon vertices, [{"lat": ..., "lon": ...}]
+    phone_formats TEXT[] NOT NULL DEFAULT '{}', -- receiver phone regular expressions, empty for any
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by_id UUID,
+    updated_by_id UUID
+);
+
+CREATE INDEX idx_service_areas_active ON service_areas(active) WHERE active = TRUE;
+
+
+CREATE TRIGGER trg_service_areas_updated_at
+BEFORE UPDATE ON service_areas
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Default area, the bounds and phone format validated until now
+INSERT INTO service_areas (name, points, phone_formats) VALUES
+    ('Saudi Arabia',
+     '[{"lat": 16.0, "lon": 34.0}, {"lat": 16.0, "lon": 56.0}, {"lat": 32.0, "lon": 56.0}, {"lat": 32.0, "lon": 34.0}]',
+     ARRAY['^\+?966[5-9][0-9]{8}$']);
+
+-- Service area the order was placed in
+ALTER TABLE orders
+    ADD COLUMN service_area_id UUID REFERENCES service_areas(id);
+
+UPDATE orders SET service_area_id = (SELECT id FROM service_areas LIMIT 1);
+
+CREATE INDEX idx_orders_service_area ON orders(service_area_id);
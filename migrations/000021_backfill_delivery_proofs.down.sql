@@ -0,0 +1,3 @@
+-- Drop the backfilled rows, they have no code and no proof
+DELETE FROM delivery_proofs
+WHERE code_hash IS NULL AND verified_at IS NULL;
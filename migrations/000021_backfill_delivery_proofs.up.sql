@@ -0,0 +1,9 @@
+--- Delivery Proofs Backfill
+-- Orders created before delivery codes get a proof row without a code, so a missing row
+-- always means the delivery cannot be verified
+INSERT INTO delivery_proofs (order_id)
+SELECT o.id
+FROM orders o
+WHERE NOT EXISTS (
+    SELECT 1 FROM delivery_proofs p WHERE p.order_id = o.id
+);
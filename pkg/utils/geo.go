@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"math"
+	"time"
+)
+
+// EarthRadiusKm is the mean radius of the earth in kilometers
+const EarthRadiusKm = 6371.0
+
+// HaversineKm returns the great-circle distance in kilometers between two coordinates
+func HaversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	dLat := degreesToRadians(lat2 - lat1)
+	dLon := degreesToRadians(lon2 - lon1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(degreesToRadians(lat1))*math.Cos(degreesToRadians(lat2))*
+			math.Sin(dLon/2)*math.Sin(dLon/2)
+	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+
+	return EarthRadiusKm * c
+}
+
+func degreesToRadians(degrees float64) float64 {
+	return degrees * math.Pi / 180.0
+}
+
+// TravelDuration is the time needed to cover distanceKm at speedKmh, zero when the speed is unknown
+func TravelDuration(distanceKm, speedKmh float64) time.Duration {
+	if speedKmh <= 0 || distanceKm <= 0 {
+		return 0
+	}
+	return time.Duration(distanceKm / speedKmh * float64(time.Hour))
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHaversineKm(t *testing.T) {
+	tests := []struct {
+		name      string
+		lat1      float64
+		lon1      float64
+		lat2      float64
+		lon2      float64
+		expected  float64
+		tolerance float64
+	}{
+		{
+			name:      "same point",
+			lat1:      24.7136,
+			lon1:      46.6753,
+			lat2:      24.7136,
+			lon2:      46.6753,
+			expected:  0,
+			tolerance: 0.0001,
+		},
+		{
+			name:      "riyadh to jeddah",
+			lat1:      24.7136,
+			lon1:      46.6753,
+			lat2:      21.4858,
+			lon2:      39.1925,
+			expected:  845.1,
+			tolerance: 2,
+		},
+		{
+			name:      "one degree of latitude",
+			lat1:      0,
+			lon1:      0,
+			lat2:      1,
+			lon2:      0,
+			expected:  111.19,
+			tolerance: 0.1,
+		},
+		{
+			name:      "short hop inside a city",
+			lat1:      24.7136,
+			lon1:      46.6753,
+			lat2:      24.7256,
+			lon2:      46.6853,
+			expected:  1.66,
+			tolerance: 0.05,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := HaversineKm(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+			if math.Abs(result-tt.expected) > tt.tolerance {
+				t.Errorf("HaversineKm() = %f, want %f (±%f)", result, tt.expected, tt.tolerance)
+			}
+		})
+	}
+}
+
+func TestHaversineKm_Symmetric(t *testing.T) {
+	forward := HaversineKm(24.7136, 46.6753, 26.4207, 50.0888)
+	backward := HaversineKm(26.4207, 50.0888, 24.7136, 46.6753)
+
+	if math.Abs(forward-backward) > 1e-9 {
+		t.Errorf("HaversineKm is not symmetric: %f != %f", forward, backward)
+	}
+}
+
+func TestTravelDuration(t *testing.T) {
+	tests := []struct {
+		name       string
+		distanceKm float64
+		speedKmh   float64
+		expected   time.Duration
+	}{
+		{name: "one hour", distanceKm: 60, speedKmh: 60, expected: time.Hour},
+		{name: "ten minutes", distanceKm: 10, speedKmh: 60, expected: 10 * time.Minute},
+		{name: "unknown speed", distanceKm: 10, speedKmh: 0, expected: 0},
+		{name: "already there", distanceKm: 0, speedKmh: 60, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := TravelDuration(tt.distanceKm, tt.speedKmh)
+			if result != tt.expected {
+				t.Errorf("TravelDuration() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
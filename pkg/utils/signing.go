@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidSignature is returned for tokens that were not signed with the secret
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// SignPayload returns "payload.signature", both base64url encoded, signed with HMAC-SHA256
+func SignPayload(payload []byte, secret string) string {
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + sign(encoded, secret)
+}
+
+// VerifySignedPayload returns the payload of a token created by SignPayload with the same secret
+func VerifySignedPayload(token, secret string) ([]byte, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || encoded == "" || signature == "" {
+		return nil, ErrInvalidSignature
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(sign(encoded, secret))) {
+		return nil, ErrInvalidSignature
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	return payload, nil
+}
+
+func sign(encoded, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
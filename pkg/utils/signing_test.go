@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSignPayload_RoundTrip(t *testing.T) {
+	payload := []byte(`{"qid":"123","price":12.5}`)
+	token := SignPayload(payload, "secret")
+
+	if strings.Count(token, ".") != 1 {
+		t.Fatalf("SignPayload() = %q, want payload.signature", token)
+	}
+
+	result, err := VerifySignedPayload(token, "secret")
+	if err != nil {
+		t.Fatalf("VerifySignedPayload() error = %v", err)
+	}
+	if string(result) != string(payload) {
+		t.Errorf("VerifySignedPayload() = %q, want %q", result, payload)
+	}
+}
+
+func TestVerifySignedPayload(t *testing.T) {
+	token := SignPayload([]byte(`{"price":12.5}`), "secret")
+	encoded, signature, _ := strings.Cut(token, ".")
+	tampered := SignPayload([]byte(`{"price":1}`), "secret")
+	tamperedPayload, _, _ := strings.Cut(tampered, ".")
+
+	tests := []struct {
+		name   string
+		token  string
+		secret string
+	}{
+		{name: "wrong secret", token: token, secret: "other"},
+		{name: "tampered payload", token: tamperedPayload + "." + signature, secret: "secret"},
+		{name: "missing signature", token: encoded, secret: "secret"},
+		{name: "empty signature", token: encoded + ".", secret: "secret"},
+		{name: "empty token", token: "", secret: "secret"},
+		{name: "invalid encoding", token: "!!!." + signature, secret: "secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := VerifySignedPayload(tt.token, tt.secret); err != ErrInvalidSignature {
+				t.Errorf("VerifySignedPayload(%q) error = %v, want ErrInvalidSignature", tt.token, err)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package utils
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
 	"fmt"
 
@@ -88,6 +90,17 @@ func VerifyOtpHash(otp, salt, hash string) bool {
 	return err == nil
 }
 
+// HashCode hashes a short-lived code with SHA-256, cheap enough to issue codes for bulk orders
+func HashCode(code, salt string) string {
+	sum := sha256.Sum256([]byte(code + salt))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyCodeHash compares a code against a HashCode hash in constant time
+func VerifyCodeHash(code, salt, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashCode(code, salt)), []byte(hash)) == 1
+}
+
 func GenerateVerfiyCred() (string, string, string, string) {
 	otpCode := GenerateOTP(4)
 	salt := GenerateSalt(16)
@@ -244,6 +244,49 @@ func TestVerifyOtpHash(t *testing.T) {
 	}
 }
 
+func TestHashCode(t *testing.T) {
+	hash := HashCode("123456", "salt")
+
+	if len(hash) != 64 {
+		t.Errorf("HashCode length = %d, want 64", len(hash))
+	}
+	if hash != HashCode("123456", "salt") {
+		t.Error("HashCode should be deterministic for the same code and salt")
+	}
+	if hash == HashCode("123456", "other") {
+		t.Error("HashCode should depend on the salt")
+	}
+}
+
+func TestVerifyCodeHash(t *testing.T) {
+	code := "123456"
+	salt := "testsalt"
+	hash := HashCode(code, salt)
+
+	tests := []struct {
+		name     string
+		code     string
+		salt     string
+		hash     string
+		expected bool
+	}{
+		{name: "correct code", code: code, salt: salt, hash: hash, expected: true},
+		{name: "wrong code", code: "654321", salt: salt, hash: hash, expected: false},
+		{name: "wrong salt", code: code, salt: "wrongsalt", hash: hash, expected: false},
+		{name: "empty code", code: "", salt: salt, hash: hash, expected: false},
+		{name: "empty hash", code: code, salt: salt, hash: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := VerifyCodeHash(tt.code, tt.salt, tt.hash)
+			if result != tt.expected {
+				t.Errorf("VerifyCodeHash(%q, %q, hash) = %v, want %v", tt.code, tt.salt, result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestGenerateVerfiyCred(t *testing.T) {
 	otpCode, hashedOtp, salt, token := GenerateVerfiyCred()
 